@@ -55,6 +55,21 @@ func (a *App) DetectInputType(input string) string {
 	return a.requestService.DetectInputType(a.ctx, input)
 }
 
+// ListHarEntries 按条件列出HAR文件中的条目
+func (a *App) ListHarEntries(input string, filter models.HarEntryFilter) ([]models.HarEntrySummary, error) {
+	return a.requestService.ListHarEntries(a.ctx, input, filter)
+}
+
+// ImportHarEntry 导入HAR文件中的一个条目
+func (a *App) ImportHarEntry(input string, index int) (*models.HarImport, error) {
+	return a.requestService.ImportHarEntry(a.ctx, input, index)
+}
+
+// DetectVolatileFields 对比同一接口的多次抓包，找出易变字段
+func (a *App) DetectVolatileFields(inputs []string) (*models.VolatileAnalysis, error) {
+	return a.requestService.DetectVolatileFields(a.ctx, inputs)
+}
+
 // GeneratePythonCode 生成Python代码
 func (a *App) GeneratePythonCode(request *models.ParsedRequest) string {
 	return a.requestService.GeneratePythonCode(a.ctx, request)
@@ -75,7 +90,7 @@ func (a *App) TestFieldNecessity(request *models.ParsedRequest, config *models.V
 		runtime.EventsEmit(a.ctx, "test-progress", progress)
 	}
 
-	return a.requestService.TestFieldNecessity(a.ctx, request, config, progressCallback)
+	return a.requestService.TestFieldNecessity(a.ctx, request, config, progressCallback, a.emitTranscript)
 }
 
 // TestFieldNecessityWithProgress 测试字段必要性（带前端进度回调）
@@ -86,7 +101,60 @@ func (a *App) TestFieldNecessityWithProgress(request *models.ParsedRequest, conf
 		runtime.EventsEmit(a.ctx, "test-progress", progress)
 	}
 
-	return a.requestService.TestFieldNecessity(a.ctx, request, config, progressCallback)
+	return a.requestService.TestFieldNecessity(a.ctx, request, config, progressCallback, a.emitTranscript)
+}
+
+// emitTranscript 通过 test-transcript 事件把运行记录逐条发送到前端
+func (a *App) emitTranscript(entry *models.TranscriptEntry) {
+	runtime.EventsEmit(a.ctx, "test-transcript", entry)
+}
+
+// ExportTranscript 以 JSON Lines 格式导出运行记录
+func (a *App) ExportTranscript(entries []models.TranscriptEntry, filePath string) error {
+	return a.requestService.ExportTranscript(a.ctx, entries, filePath)
+}
+
+// ListFieldNecessityRuns 列出正在进行的字段必要性测试
+func (a *App) ListFieldNecessityRuns() []models.FieldTestRunInfo {
+	return a.requestService.ListFieldNecessityRuns(a.ctx)
+}
+
+// CancelFieldNecessity 取消字段必要性测试（runID 为空时取消全部）
+func (a *App) CancelFieldNecessity(runID string) error {
+	return a.requestService.CancelFieldNecessity(a.ctx, runID)
+}
+
+// PauseFieldNecessity 暂停字段必要性测试（runID 为空时暂停全部）
+func (a *App) PauseFieldNecessity(runID string) error {
+	return a.requestService.PauseFieldNecessity(a.ctx, runID)
+}
+
+// ResumeFieldNecessity 恢复字段必要性测试（runID 为空时恢复全部）
+func (a *App) ResumeFieldNecessity(runID string) error {
+	return a.requestService.ResumeFieldNecessity(a.ctx, runID)
+}
+
+// StartTokenReplay 启动定时重放，进度通过 replay-progress 事件发送到前端，返回运行ID
+func (a *App) StartTokenReplay(request *models.ParsedRequest, config *models.ValidationConfig, replay models.ReplayConfig) (string, error) {
+	progressCallback := func(progress *models.ReplayProgress) {
+		runtime.EventsEmit(a.ctx, "replay-progress", progress)
+	}
+	return a.requestService.StartReplay(a.ctx, request, config, replay, progressCallback)
+}
+
+// GetTokenReplayResult 获取定时重放结果
+func (a *App) GetTokenReplayResult(runID string) (*models.ReplayResult, error) {
+	return a.requestService.GetReplayResult(a.ctx, runID)
+}
+
+// ListTokenReplays 列出定时重放
+func (a *App) ListTokenReplays() []models.ReplayResult {
+	return a.requestService.ListReplays(a.ctx)
+}
+
+// CancelTokenReplay 取消定时重放
+func (a *App) CancelTokenReplay(runID string) error {
+	return a.requestService.CancelReplay(a.ctx, runID)
 }
 
 // ValidateExpression 验证表达式
@@ -256,6 +324,21 @@ func (a *App) OpenResourceMonitorDownloadDir(opener string) error {
 	return a.resourceMonitorService.OpenDownloadDir(a.ctx, opener)
 }
 
+// GetNetworkSettings 获取默认网络配置
+func (a *App) GetNetworkSettings() (*models.NetworkConfig, error) {
+	return a.requestService.GetNetworkSettings(a.ctx)
+}
+
+// UpdateNetworkSettings 保存默认网络配置
+func (a *App) UpdateNetworkSettings(network models.NetworkConfig) (*models.NetworkConfig, error) {
+	return a.requestService.UpdateNetworkSettings(a.ctx, network)
+}
+
+// ResetNetworkSettings 恢复默认网络配置
+func (a *App) ResetNetworkSettings() (*models.NetworkConfig, error) {
+	return a.requestService.ResetNetworkSettings(a.ctx)
+}
+
 // GetResourceMonitorSettings 获取资源监听设置
 func (a *App) GetResourceMonitorSettings() (*models.ResourceMonitorSettings, error) {
 	return a.resourceMonitorService.GetSettings(a.ctx)
@@ -103,27 +103,16 @@ func (d *EncodingDetector) DetectEncoding(data []byte, calibrationText string) (
 		return "UTF-8", nil // 默认返回UTF-8
 	}
 
-	fmt.Printf("开始检测编码，校准文本: '%s'，数据长度: %d\n", calibrationText, len(data))
-
 	// 遍历所有编码，将原始字节数据按不同编码解码
 	for encodingName, enc := range d.encodings {
 		decoded, err := d.decodeBytes(data, enc)
 		if err != nil {
-			fmt.Printf("编码 %s 解码失败: %v\n", encodingName, err)
 			continue
 		}
 
 		// 检查解码后的文本是否包含校准文本
 		if strings.Contains(decoded, calibrationText) {
-			fmt.Printf("找到匹配编码: %s\n", encodingName)
 			return encodingName, nil
-		} else {
-			// 显示解码后文本的前100个字符用于调试
-			preview := decoded
-			if len(preview) > 100 {
-				preview = preview[:100] + "..."
-			}
-			fmt.Printf("编码 %s 解码成功但不包含校准文本，预览: %s\n", encodingName, preview)
 		}
 	}
 
@@ -136,9 +125,7 @@ func (d *EncodingDetector) AutoDetectEncoding(data []byte) (string, string, erro
 	reader := bytes.NewReader(data)
 
 	// 尝试从内容中检测编码
-	encoding, name, certain := charset.DetermineEncoding(data, "text/html")
-
-	fmt.Printf("自动检测到编码: %s (确定性: %v)\n", name, certain)
+	encoding, name, _ := charset.DetermineEncoding(data, "text/html")
 
 	// 使用检测到的编码进行转换
 	decoder := encoding.NewDecoder()
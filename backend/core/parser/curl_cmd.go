@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// ParseCmd 解析 Windows cmd 格式的Curl命令（Chrome "Copy as cURL (cmd)"）
+//
+// cmd.exe 先处理 ^ 转义与 ^ 续行，curl.exe 再按 MS C 运行库规则拆分参数，这里按同样的两步还原参数。
+func (p *CurlRequestParser) ParseCmd(command string) (*models.ParsedRequest, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("Curl命令不能为空")
+	}
+
+	args := splitWindowsArgs(unescapeCmd(p.cleanCurlCommand(command)))
+	return p.parseArgs(args)
+}
+
+// IsCmdCommand 检测是否为 cmd 格式的Curl命令（含 ^" 转义或 ^ 续行）
+func (p *CurlRequestParser) IsCmdCommand(input string) bool {
+	trimmed := p.cleanCurlCommand(input)
+	return p.IsCurlCommand(trimmed) && (strings.Contains(trimmed, `^"`) || strings.Contains(trimmed, "^\n"))
+}
+
+// unescapeCmd 按 cmd.exe 规则处理 ^ 转义：^x 得到 x，行尾 ^ 续行并按字面保留下一行的首字符
+//
+// 双引号内的 ^ 不是转义符；被 ^ 转义的双引号不切换引号状态。
+func unescapeCmd(command string) string {
+	var out strings.Builder
+	inQuotes := false
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+			out.WriteRune(char)
+		case char == '^' && !inQuotes && i+1 < len(runes):
+			i++
+			if runes[i] == '\n' {
+				if i+1 >= len(runes) {
+					continue
+				}
+				i++
+			}
+			out.WriteRune(runes[i])
+		default:
+			out.WriteRune(char)
+		}
+	}
+	return out.String()
+}
+
+// splitWindowsArgs 按 MS C 运行库（CommandLineToArgvW）规则拆分参数
+//
+// 2n 个反斜杠加双引号得到 n 个反斜杠并切换引号状态，2n+1 个得到 n 个反斜杠和字面双引号；
+// 引号内连续两个双引号表示一个字面双引号；其余反斜杠按字面保留。
+func splitWindowsArgs(command string) []string {
+	var args []string
+	var current strings.Builder
+	inArg, inQuotes := false, false
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case char == '\\':
+			n := 1
+			for i+n < len(runes) && runes[i+n] == '\\' {
+				n++
+			}
+			if i+n < len(runes) && runes[i+n] == '"' {
+				current.WriteString(strings.Repeat(`\`, n/2))
+				if n%2 == 1 {
+					current.WriteRune('"')
+					i += n
+				} else {
+					i += n - 1 // 由下一轮处理双引号
+				}
+			} else {
+				current.WriteString(strings.Repeat(`\`, n))
+				i += n - 1
+			}
+			inArg = true
+
+		case char == '"':
+			if inQuotes && i+1 < len(runes) && runes[i+1] == '"' {
+				current.WriteRune('"')
+				i++
+			} else {
+				inQuotes = !inQuotes
+			}
+			inArg = true
+
+		case !inQuotes && (char == ' ' || char == '\t' || char == '\n'):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+		default:
+			current.WriteRune(char)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args
+}
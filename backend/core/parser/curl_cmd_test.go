@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// chromeCmdEscape 与 Chrome "Copy as cURL (cmd)" 相同的参数转义
+func chromeCmdEscape(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = regexp.MustCompile("[^a-zA-Z0-9\\s_\\-:=+~'/.,?;()*`]").ReplaceAllString(value, "^$0")
+	value = regexp.MustCompile(`%([a-zA-Z0-9_])`).ReplaceAllString(value, "%^$1")
+	value = regexp.MustCompile(`\r?\n`).ReplaceAllString(value, "^\n\n")
+	return `^"` + value + `^"`
+}
+
+func TestCurlRequestParser_ParseCmd(t *testing.T) {
+	requestURL := "https://example.com/api?q=a&b=%41"
+	header := `X-Data: {"k":"v"} & <tag> | 100% ^_^`
+	body := "{\"path\":\"C:\\\\\",\r\n\"ok\":true}" // curl.exe 只还原紧邻双引号的反斜杠
+	command := strings.Join([]string{
+		"curl " + chromeCmdEscape(requestURL),
+		"  -H " + chromeCmdEscape(header),
+		"  -H " + chromeCmdEscape("cookie: sid=abc; theme=dark"),
+		"  --data-raw " + chromeCmdEscape(body),
+		"  --compressed",
+	}, " ^\r\n")
+
+	parser := NewUnifiedRequestParser()
+	if inputType := parser.DetectInputType(command); inputType != "curl-cmd" {
+		t.Fatalf("expected curl-cmd input type, got %q", inputType)
+	}
+
+	req, err := parser.Parse(command)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.URL != requestURL || req.QueryParams["b"] != "A" {
+		t.Fatalf("unexpected URL: %q (%v)", req.URL, req.QueryParams)
+	}
+	if req.Headers["X-Data"] != `{"k":"v"} & <tag> | 100% ^_^` {
+		t.Fatalf("unexpected header: %q", req.Headers["X-Data"])
+	}
+	if req.Body != strings.ReplaceAll(body, "\r\n", "\n") {
+		t.Fatalf("unexpected body: %q", req.Body)
+	}
+	if req.Method != "POST" || req.Cookies["theme"] != "dark" || req.Headers["Accept-Encoding"] == "" {
+		t.Fatalf("unexpected request: %#v", req)
+	}
+}
+
+func TestSplitWindowsArgs(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{`a "b c" d`, []string{"a", "b c", "d"}},
+		{`"a\"b" \\server\share`, []string{`a"b`, `\\server\share`}},
+		{`"a\\" b`, []string{`a\`, "b"}},
+		{`"a\\\"b"`, []string{`a\"b`}},
+		{`"say ""hi""" ""`, []string{`say "hi"`, ""}},
+	}
+	for _, tt := range tests {
+		if got := splitWindowsArgs(tt.input); strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.want, got)
+		}
+	}
+
+	if got := unescapeCmd(`a^&b "c^d" ^"e^"`); got != `a&b "c^d" "e"` {
+		t.Fatalf("unexpected cmd unescape: %q", got)
+	}
+}
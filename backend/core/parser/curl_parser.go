@@ -1,14 +1,69 @@
 package parser
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/url"
-	"regexp"
+	"path"
 	"strings"
+	"unicode"
 
 	"RequestProbe/backend/models"
 )
 
+// curlFormBoundary -F 生成 multipart 请求体时使用的分隔符（固定值便于结果复现）
+const curlFormBoundary = "------------------------RequestProbeBoundary"
+
+// curlCompressedEncodings --compressed 时发送的 Accept-Encoding
+const curlCompressedEncodings = "deflate, gzip, br, zstd"
+
+// curlShortOptions 短选项对应的长选项
+var curlShortOptions = map[byte]string{
+	'X': "--request", 'H': "--header", 'b': "--cookie", 'd': "--data", 'F': "--form",
+	'u': "--user", 'G': "--get", 'I': "--head", 'A': "--user-agent", 'e': "--referer",
+	's': "--silent", 'S': "--show-error", 'v': "--verbose", 'i': "--include", 'f': "--fail",
+	'N': "--no-buffer", '#': "--progress-bar", 'g': "--globoff", 'o': "--output", 'O': "--remote-name",
+	'J': "--remote-header-name", 'R': "--remote-time", 'w': "--write-out", 'D': "--dump-header",
+	'c': "--cookie-jar", 'K': "--config", 'x': "--proxy", 'U': "--proxy-user", 'm': "--max-time",
+	'E': "--cert", 'r': "--range", 'T': "--upload-file", 'z': "--time-cond", 'C': "--continue-at",
+	'Y': "--speed-limit", 'y': "--speed-time", 'L': "--location", 'k': "--insecure", 'p': "--proxytunnel",
+	'n': "--netrc", 'Z': "--parallel", '0': "--http1.0", '4': "--ipv4", '6': "--ipv6",
+}
+
+// curlValueOptions 需要参数值的长选项（含不支持但需要跳过参数值的选项）
+var curlValueOptions = map[string]bool{
+	"--request": true, "--header": true, "--cookie": true, "--url": true, "--url-query": true,
+	"--data": true, "--data-ascii": true, "--data-raw": true, "--data-binary": true, "--data-urlencode": true,
+	"--json": true, "--form": true, "--form-string": true, "--user": true, "--user-agent": true,
+	"--referer": true, "--oauth2-bearer": true,
+	"--output": true, "--write-out": true, "--dump-header": true, "--stderr": true, "--trace": true,
+	"--trace-ascii": true, "--output-dir": true, "--config": true, "--cookie-jar": true,
+	"--proxy": true, "--proxy-user": true, "--proxy-header": true, "--preproxy": true, "--noproxy": true,
+	"--socks5": true, "--socks5-hostname": true, "--max-time": true, "--connect-timeout": true,
+	"--retry": true, "--retry-delay": true, "--retry-max-time": true, "--max-redirs": true,
+	"--cacert": true, "--capath": true, "--cert": true, "--cert-type": true, "--key": true,
+	"--key-type": true, "--pass": true, "--ciphers": true, "--resolve": true, "--connect-to": true,
+	"--interface": true, "--local-port": true, "--limit-rate": true, "--range": true,
+	"--upload-file": true, "--time-cond": true, "--continue-at": true, "--speed-limit": true,
+	"--speed-time": true, "--unix-socket": true, "--aws-sigv4": true, "--request-target": true,
+	"--netrc-file": true, "--max-filesize": true, "--keepalive-time": true, "--tls-max": true,
+	"--alt-svc": true, "--hsts": true, "--doh-url": true, "--dns-servers": true, "--variable": true,
+}
+
+// curlOutputOptions 仅影响curl自身输出、与请求内容无关的选项，解析时直接忽略
+var curlOutputOptions = map[string]bool{
+	"--silent": true, "--show-error": true, "--verbose": true, "--include": true, "--fail": true,
+	"--fail-with-body": true, "--fail-early": true, "--no-buffer": true, "--progress-bar": true,
+	"--no-progress-meter": true, "--output": true, "--write-out": true, "--dump-header": true,
+	"--stderr": true, "--trace": true, "--trace-ascii": true, "--trace-time": true,
+	"--remote-name": true, "--remote-name-all": true, "--remote-header-name": true, "--remote-time": true,
+	"--create-dirs": true, "--output-dir": true, "--globoff": true, "--styled-output": true,
+	"--no-styled-output": true,
+}
+
+// curlImplicitHeaders 由选项生成的请求头，按curl的发送顺序排列在显式请求头之前
+var curlImplicitHeaders = []string{"Authorization", "User-Agent", "Referer", "Accept-Encoding"}
+
 // CurlRequestParser Curl命令解析器
 type CurlRequestParser struct{}
 
@@ -32,239 +87,623 @@ func (p *CurlRequestParser) Parse(curlCommand string) (*models.ParsedRequest, er
 		return nil, fmt.Errorf("解析Curl参数失败: %v", err)
 	}
 
-	// 提取URL
-	requestURL := p.extractURL(args)
-	if requestURL == "" {
-		return nil, fmt.Errorf("未找到请求URL")
-	}
-
-	// 提取HTTP方法
-	method := p.extractMethod(args)
-
-	// 提取Headers
-	headers := p.extractHeaders(args)
-
-	// 提取Cookies
-	cookies := p.extractCookies(args)
-
-	// 提取请求体
-	body := p.extractBody(args)
-
-	// 解析URL参数
-	queryParams, err := p.parseQueryParams(requestURL)
-	if err != nil {
-		return nil, fmt.Errorf("解析URL参数失败: %v", err)
-	}
+	return p.parseArgs(args)
+}
 
-	// 确定Content-Type
-	contentType := headers["Content-Type"]
-	if contentType == "" {
-		contentType = headers["content-type"]
+// parseArgs 根据拆分后的参数生成解析结果
+func (p *CurlRequestParser) parseArgs(args []string) (*models.ParsedRequest, error) {
+	cmd := newCurlCommand()
+	if err := p.applyArgs(cmd, args); err != nil {
+		return nil, err
 	}
-
-	return &models.ParsedRequest{
-		Method:      method,
-		URL:         requestURL,
-		Headers:     headers,
-		Cookies:     cookies,
-		Body:        body,
-		QueryParams: queryParams,
-		ContentType: contentType,
-	}, nil
+	return cmd.request()
 }
 
-// cleanCurlCommand 清理Curl命令，处理多行和转义
+// cleanCurlCommand 清理Curl命令，统一换行符（续行由 parseCurlArgs 处理）
 func (p *CurlRequestParser) cleanCurlCommand(command string) string {
-	// 移除行尾的反斜杠和换行符
-	command = regexp.MustCompile(`\\\s*\n\s*`).ReplaceAllString(command, " ")
-
-	// 移除多余的空白字符
-	command = regexp.MustCompile(`\s+`).ReplaceAllString(command, " ")
-
-	return strings.TrimSpace(command)
+	return strings.TrimSpace(strings.ReplaceAll(command, "\r\n", "\n"))
 }
 
-// parseCurlArgs 解析Curl命令参数
+// parseCurlArgs 按 POSIX shell 规则拆分Curl命令参数
+//
+// 支持单引号、双引号、反斜杠转义、反斜杠续行以及 $'...' ANSI-C 引号。
 func (p *CurlRequestParser) parseCurlArgs(command string) ([]string, error) {
 	var args []string
 	var current strings.Builder
-	var inQuotes bool
-	var quoteChar rune
-	var escaped bool
-
-	for _, char := range command {
-		if escaped {
-			current.WriteRune(char)
-			escaped = false
-			continue
-		}
-
-		if char == '\\' {
-			escaped = true
-			continue
-		}
+	inArg := false
+	runes := []rune(command)
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case char == '\\':
+			if i+1 < len(runes) {
+				i++
+				if runes[i] == '\n' {
+					continue // 续行
+				}
+				current.WriteRune(runes[i])
+				inArg = true
+			}
 
-		if !inQuotes && (char == '"' || char == '\'') {
-			inQuotes = true
-			quoteChar = char
-			continue
-		}
+		case char == '\'':
+			end := i + 1
+			for end < len(runes) && runes[end] != '\'' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("单引号未闭合")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			i = end
+			inArg = true
+
+		case char == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				// 双引号内反斜杠只转义 $ ` " \ 和换行
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("双引号未闭合")
+			}
+			inArg = true
 
-		if inQuotes && char == quoteChar {
-			inQuotes = false
-			continue
-		}
+		case char == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			end, err := readANSICQuoted(runes, i+2, &current)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+			inArg = true
 
-		if !inQuotes && char == ' ' {
-			if current.Len() > 0 {
+		case unicode.IsSpace(char):
+			if inArg {
 				args = append(args, current.String())
 				current.Reset()
+				inArg = false
 			}
-			continue
-		}
 
-		current.WriteRune(char)
+		default:
+			current.WriteRune(char)
+			inArg = true
+		}
 	}
 
-	if current.Len() > 0 {
+	if inArg {
 		args = append(args, current.String())
 	}
 
 	return args, nil
 }
 
-// extractURL 提取URL
-func (p *CurlRequestParser) extractURL(args []string) string {
-	for i := 0; i < len(args); i++ {
-		arg := args[i]
-		if arg == "curl" {
+// readANSICQuoted 读取 $'...' 引号内的内容并解码转义，返回结束引号的位置
+func readANSICQuoted(runes []rune, start int, out *strings.Builder) (int, error) {
+	for i := start; i < len(runes); i++ {
+		char := runes[i]
+		if char == '\'' {
+			return i, nil
+		}
+		if char != '\\' || i+1 >= len(runes) {
+			out.WriteRune(char)
 			continue
 		}
-		if strings.HasPrefix(arg, "-") {
-			if p.optionConsumesValue(arg) && i+1 < len(args) {
+
+		i++
+		switch escape := runes[i]; escape {
+		case 'a':
+			out.WriteByte('\a')
+		case 'b':
+			out.WriteByte('\b')
+		case 'e', 'E':
+			out.WriteByte(0x1b)
+		case 'f':
+			out.WriteByte('\f')
+		case 'n':
+			out.WriteByte('\n')
+		case 'r':
+			out.WriteByte('\r')
+		case 't':
+			out.WriteByte('\t')
+		case 'v':
+			out.WriteByte('\v')
+		case '\\', '\'', '"', '?':
+			out.WriteRune(escape)
+		case 'x', 'u', 'U':
+			maxDigits := 2
+			if escape == 'u' {
+				maxDigits = 4
+			} else if escape == 'U' {
+				maxDigits = 8
+			}
+			value, n := readEscapeDigits(runes, i+1, 16, maxDigits)
+			switch {
+			case n == 0:
+				out.WriteRune('\\')
+				out.WriteRune(escape)
+			case escape == 'x':
+				out.WriteByte(byte(value)) // \xHH 为原始字节，连续多个可组成UTF-8字符
+			default:
+				out.WriteRune(rune(value))
+			}
+			i += n
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			value, n := readEscapeDigits(runes, i, 8, 3)
+			out.WriteByte(byte(value))
+			i += n - 1
+		case 'c':
+			if i+1 < len(runes) {
 				i++
+				out.WriteByte(byte(runes[i]) & 0x1f)
 			}
-			continue
+		default:
+			out.WriteRune('\\')
+			out.WriteRune(escape)
 		}
-		// 第一个非选项参数应该是URL
-		return arg
 	}
-	return ""
+	return 0, fmt.Errorf("$'...' 引号未闭合")
 }
 
-// extractMethod 提取HTTP方法
-func (p *CurlRequestParser) extractMethod(args []string) string {
-	for i, arg := range args {
-		if (arg == "-X" || arg == "--request") && i+1 < len(args) {
-			return strings.ToUpper(args[i+1])
+// readEscapeDigits 从 start 开始读取最多 maxDigits 位指定进制的数字，返回数值与位数
+func readEscapeDigits(runes []rune, start, base, maxDigits int) (int, int) {
+	value, n := 0, 0
+	for ; n < maxDigits && start+n < len(runes); n++ {
+		digit := strings.IndexRune("0123456789abcdef", unicode.ToLower(runes[start+n]))
+		if digit < 0 || digit >= base {
+			break
 		}
+		value = value*base + digit
 	}
+	return value, n
+}
 
-	for _, arg := range args {
-		if p.isDataOption(arg) {
-			return "POST"
-		}
+// applyArgs 依次处理Curl参数
+func (p *CurlRequestParser) applyArgs(cmd *curlCommand, args []string) error {
+	if len(args) > 0 && (args[0] == "curl" || strings.EqualFold(args[0], "curl.exe")) {
+		args = args[1:]
 	}
 
-	return "GET" // 默认方法
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			// 之后的参数都不是选项
+			cmd.urls = append(cmd.urls, args[i+1:]...)
+			return nil
+
+		case strings.HasPrefix(arg, "--"):
+			value := ""
+			if p.optionConsumesValue(arg) {
+				if i+1 >= len(args) {
+					return fmt.Errorf("选项 %s 缺少参数", arg)
+				}
+				i++
+				value = args[i]
+			}
+			cmd.apply(arg, value)
+
+		case len(arg) > 1 && arg[0] == '-':
+			// 短选项可以合并（-sSL），需要参数值的短选项可以直接附带参数（-XPOST）
+			for j := 1; j < len(arg); j++ {
+				name, known := curlShortOptions[arg[j]]
+				if !known {
+					cmd.warn("不支持的选项 -%c，已忽略", arg[j])
+					continue
+				}
+				if !p.optionConsumesValue(name) {
+					cmd.apply(name, "")
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						return fmt.Errorf("选项 -%c 缺少参数", arg[j])
+					}
+					i++
+					value = args[i]
+				}
+				cmd.apply(name, value)
+				break
+			}
+
+		default:
+			cmd.urls = append(cmd.urls, arg)
+		}
+	}
+	return nil
 }
 
+// optionConsumesValue 选项（长选项或单个短选项）是否需要参数值
 func (p *CurlRequestParser) optionConsumesValue(arg string) bool {
-	switch arg {
-	case "-X", "--request",
-		"-H", "--header",
-		"-b", "--cookie",
-		"-d", "--data", "--data-raw",
-		"-A", "--user-agent",
-		"-e", "--referer",
-		"--url":
-		return true
-	default:
-		return false
+	if len(arg) == 2 && arg[0] == '-' && arg[1] != '-' {
+		arg = curlShortOptions[arg[1]]
 	}
+	return curlValueOptions[arg]
 }
 
-func (p *CurlRequestParser) isDataOption(arg string) bool {
-	switch arg {
-	case "-d", "--data", "--data-raw":
-		return true
+// curlCommand 逐个处理Curl选项时累积的请求信息
+type curlCommand struct {
+	method      string
+	urls        []string
+	urlQueries  []string
+	headers     map[string]string
+	headerOrder []string
+	values      map[string][]string // 显式请求头的全部取值（同名 -H 重复时 curl 逐行发送）
+	implicit    map[string]string   // 由 -u/-A/-e/--compressed 生成的请求头
+	removed     map[string]bool     // 以 "Name:" 形式移除的请求头（小写）
+	cookies     map[string]string
+	cookieOrder []string
+	data        strings.Builder
+	post        bool // 使用过 -d 系列或 --json 选项
+	json        bool
+	form        []harParam
+	get         bool
+	head        bool
+	warnings    []string
+}
+
+// newCurlCommand 创建空的Curl选项累积状态
+func newCurlCommand() *curlCommand {
+	return &curlCommand{
+		headers:  make(map[string]string),
+		values:   make(map[string][]string),
+		implicit: make(map[string]string),
+		removed:  make(map[string]bool),
+		cookies:  make(map[string]string),
+	}
+}
+
+// warn 记录解析警告
+func (c *curlCommand) warn(format string, args ...interface{}) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// warnLocalFile 记录引用了本地文件的警告（解析器不读取文件）
+func (c *curlCommand) warnLocalFile(option, file string) {
+	c.warn("选项 %s 引用了本地文件 %s，未读取文件内容", option, file)
+}
+
+// apply 处理单个选项（name 为长选项名）
+func (c *curlCommand) apply(name, value string) {
+	switch name {
+	case "--request":
+		c.method = strings.ToUpper(value)
+	case "--url":
+		c.urls = append(c.urls, value)
+	case "--url-query":
+		if query, ok := strings.CutPrefix(value, "+"); ok {
+			c.urlQueries = append(c.urlQueries, query)
+		} else if query, file := encodeCurlData(value); file != "" {
+			c.warnLocalFile(name, file)
+		} else {
+			c.urlQueries = append(c.urlQueries, query)
+		}
+	case "--header":
+		c.addHeader(value)
+	case "--cookie":
+		if !strings.Contains(value, "=") {
+			c.warnLocalFile(name, value)
+			return
+		}
+		c.addCookies(value)
+	case "--data", "--data-ascii", "--data-binary", "--json":
+		c.post = true
+		c.json = c.json || name == "--json"
+		if file, ok := strings.CutPrefix(value, "@"); ok {
+			c.warnLocalFile(name, file)
+			return
+		}
+		c.appendData(value, name == "--json")
+	case "--data-raw":
+		c.post = true
+		c.appendData(value, false)
+	case "--data-urlencode":
+		c.post = true
+		if data, file := encodeCurlData(value); file != "" {
+			c.warnLocalFile(name, file)
+		} else {
+			c.appendData(data, false)
+		}
+	case "--form", "--form-string":
+		c.addFormField(name, value)
+	case "--user":
+		if !strings.Contains(value, ":") {
+			c.warn("选项 --user 未提供密码，按空密码处理")
+			value += ":"
+		}
+		c.implicit["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(value))
+	case "--oauth2-bearer":
+		c.implicit["Authorization"] = "Bearer " + value
+	case "--user-agent":
+		c.implicit["User-Agent"] = value
+	case "--referer":
+		// ";auto" 表示跟随重定向时自动设置Referer
+		if referer := strings.TrimSuffix(value, ";auto"); referer != "" {
+			c.implicit["Referer"] = referer
+		}
+	case "--compressed":
+		c.implicit["Accept-Encoding"] = curlCompressedEncodings
+	case "--get":
+		c.get = true
+	case "--head":
+		c.head = true
 	default:
-		return false
+		if !curlOutputOptions[name] {
+			c.warn("不支持的选项 %s，已忽略", name)
+		}
 	}
 }
 
-// extractHeaders 提取Headers
-func (p *CurlRequestParser) extractHeaders(args []string) map[string]string {
-	headers := make(map[string]string)
-
-	for i, arg := range args {
-		if (arg == "-H" || arg == "--header") && i+1 < len(args) {
-			headerValue := args[i+1]
-			if colonIndex := strings.Index(headerValue, ":"); colonIndex > 0 {
-				key := strings.TrimSpace(headerValue[:colonIndex])
-				value := strings.TrimSpace(headerValue[colonIndex+1:])
-				headers[key] = value
-			}
+// appendData 追加请求体数据：-d 系列之间以 & 连接，--json 直接拼接
+func (c *curlCommand) appendData(value string, concat bool) {
+	if c.data.Len() > 0 && !concat {
+		c.data.WriteByte('&')
+	}
+	c.data.WriteString(value)
+}
+
+// encodeCurlData 按 --data-urlencode 的规则编码参数值，name@file 形式返回文件名
+func encodeCurlData(value string) (string, string) {
+	sep := strings.IndexByte(value, '=')
+	if sep < 0 {
+		sep = strings.IndexByte(value, '@')
+	}
+	if sep < 0 {
+		return curlEscape(value), ""
+	}
+	if value[sep] == '@' {
+		return "", value[sep+1:]
+	}
+	if name := value[:sep]; name != "" {
+		return name + "=" + curlEscape(value[sep+1:]), ""
+	}
+	return curlEscape(value[sep+1:]), ""
+}
+
+// curlEscape 与 curl_easy_escape 相同的百分号编码（空格编码为 %20）
+func curlEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// addHeader 处理 -H：Name: value 设置请求头，Name: 移除请求头，Name; 发送空值请求头
+func (c *curlCommand) addHeader(line string) {
+	if file, ok := strings.CutPrefix(line, "@"); ok {
+		c.warnLocalFile("--header", file)
+		return
+	}
+
+	if colonIndex := strings.Index(line, ":"); colonIndex > 0 {
+		key := strings.TrimSpace(line[:colonIndex])
+		value := strings.TrimSpace(line[colonIndex+1:])
+		if value == "" {
+			c.removeHeader(key)
+			return
+		}
+		c.setHeader(key, value)
+		if strings.EqualFold(key, "Cookie") {
+			c.addCookies(value)
 		}
+		return
+	}
+
+	if key, ok := strings.CutSuffix(strings.TrimSpace(line), ";"); ok && key != "" {
+		c.setHeader(key, "")
+		return
+	}
+	c.warn("请求头格式无效: %s", line)
+}
+
+// setHeader 设置请求头并记录出现顺序
+func (c *curlCommand) setHeader(key, value string) {
+	if _, exists := c.headers[key]; !exists {
+		c.headerOrder = append(c.headerOrder, key)
 	}
+	c.headers[key] = value
+	c.values[key] = append(c.values[key], value)
+	delete(c.removed, strings.ToLower(key))
+}
 
-	return headers
+// removeHeader 移除请求头（同时阻止选项生成同名请求头）
+func (c *curlCommand) removeHeader(name string) {
+	if key, exists := headerKey(c.headers, name); exists {
+		delete(c.headers, key)
+		delete(c.values, key)
+	}
+	c.removed[strings.ToLower(name)] = true
+}
+
+// addCookies 合并 name=value; ... 形式的Cookie
+func (c *curlCommand) addCookies(value string) {
+	cookies, names := NewRawRequestParser().parseCookieHeader(value)
+	for _, name := range names {
+		if _, exists := c.cookies[name]; !exists {
+			c.cookieOrder = append(c.cookieOrder, name)
+		}
+		c.cookies[name] = cookies[name]
+	}
 }
 
-// extractCookies 提取Cookies
-func (p *CurlRequestParser) extractCookies(args []string) map[string]string {
-	cookies := make(map[string]string)
-
-	for i, arg := range args {
-		if (arg == "-b" || arg == "--cookie") && i+1 < len(args) {
-			cookieValue := args[i+1]
-
-			// 解析cookie字符串
-			pairs := strings.Split(cookieValue, ";")
-			for _, pair := range pairs {
-				pair = strings.TrimSpace(pair)
-				if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
-					name := strings.TrimSpace(pair[:equalIndex])
-					value := strings.TrimSpace(pair[equalIndex+1:])
-					cookies[name] = value
+// addFormField 处理 -F/--form-string 表单字段（name=value，-F 下 name=@file 为文件上传）
+func (c *curlCommand) addFormField(option, value string) {
+	equalIndex := strings.Index(value, "=")
+	if equalIndex <= 0 {
+		c.warn("表单字段格式无效: %s", value)
+		return
+	}
+	field := harParam{Name: value[:equalIndex], Value: value[equalIndex+1:]}
+
+	if option == "--form" && (strings.HasPrefix(field.Value, "@") || strings.HasPrefix(field.Value, "<")) {
+		// @file 上传文件，<file 以文件内容作为字段值；可附带 ;type= 与 ;filename=
+		spec := strings.Split(field.Value[1:], ";")
+		c.warnLocalFile(option, spec[0])
+		if field.Value[0] == '@' {
+			field.FileName = path.Base(strings.ReplaceAll(spec[0], "\\", "/"))
+			for _, attr := range spec[1:] {
+				if contentType, ok := strings.CutPrefix(attr, "type="); ok {
+					field.ContentType = contentType
+				} else if fileName, ok := strings.CutPrefix(attr, "filename="); ok {
+					field.FileName = strings.Trim(fileName, `"`)
 				}
 			}
 		}
+		field.Value = ""
 	}
-
-	return cookies
+	c.form = append(c.form, field)
 }
 
-// extractBody 提取请求体
-func (p *CurlRequestParser) extractBody(args []string) string {
-	for i, arg := range args {
-		if (arg == "-d" || arg == "--data" || arg == "--data-raw") && i+1 < len(args) {
-			return args[i+1]
+// request 根据累积的选项生成解析结果
+func (c *curlCommand) request() (*models.ParsedRequest, error) {
+	if len(c.urls) == 0 {
+		return nil, fmt.Errorf("未找到请求URL")
+	}
+	if len(c.urls) > 1 {
+		c.warn("命令包含多个URL，仅使用第一个: %s", c.urls[0])
+	}
+	if c.post && len(c.form) > 0 {
+		return nil, fmt.Errorf("不能同时使用 -F 与 -d/--json 选项")
+	}
+
+	// 与curl一致，未写协议时按 http:// 处理
+	requestURL := c.urls[0]
+	if !strings.Contains(requestURL, "://") {
+		requestURL = "http://" + requestURL
+	}
+	for _, query := range c.urlQueries {
+		requestURL = appendURLQuery(requestURL, query)
+	}
+
+	body := c.data.String()
+	if c.get {
+		// -G 把 -d 数据追加到URL查询串
+		requestURL = appendURLQuery(requestURL, body)
+		body = ""
+	}
+
+	// 选项生成的请求头排在显式请求头之前，显式请求头优先
+	headers := make(map[string]string, len(c.headers)+len(c.implicit))
+	var headerOrder []string
+	for _, name := range curlImplicitHeaders {
+		value, set := c.implicit[name]
+		if _, explicit := headerKey(c.headers, name); set && !explicit && !c.removed[strings.ToLower(name)] {
+			headers[name] = value
+			headerOrder = append(headerOrder, name)
 		}
 	}
-	return ""
-}
+	for _, key := range c.headerOrder {
+		if value, exists := c.headers[key]; exists {
+			headers[key] = value
+			headerOrder = append(headerOrder, key)
+		}
+	}
+	addDefault := func(name, value string) {
+		if _, exists := headerKey(headers, name); !exists && !c.removed[strings.ToLower(name)] {
+			headers[name] = value
+			headerOrder = append(headerOrder, name)
+		}
+	}
+
+	switch {
+	case len(c.form) > 0:
+		contentType := headerValue(headers, "Content-Type")
+		if contentType == "" {
+			contentType = "multipart/form-data; boundary=" + curlFormBoundary
+		}
+		formBody, formContentType, err := harPostBody(&harPostData{Params: c.form}, contentType)
+		if err != nil {
+			return nil, fmt.Errorf("生成表单请求体失败: %v", err)
+		}
+		body = formBody
+		if key, exists := headerKey(headers, "Content-Type"); exists {
+			headers[key] = formContentType
+		} else {
+			addDefault("Content-Type", formContentType)
+		}
+	case c.post && !c.get && c.json:
+		addDefault("Content-Type", "application/json")
+		addDefault("Accept", "application/json")
+	case c.post && !c.get:
+		addDefault("Content-Type", "application/x-www-form-urlencoded")
+	}
 
-// parseQueryParams 解析URL查询参数
-func (p *CurlRequestParser) parseQueryParams(requestURL string) (map[string]string, error) {
-	params := make(map[string]string)
+	method := c.method
+	if method == "" {
+		switch {
+		case c.head:
+			method = "HEAD"
+		case c.get:
+			method = "GET"
+		case c.post || len(c.form) > 0:
+			method = "POST"
+		default:
+			method = "GET"
+		}
+	}
 
-	parsedURL, err := url.Parse(requestURL)
+	// 解析URL参数
+	queryParams, err := NewRawRequestParser().parseQueryParams(requestURL)
 	if err != nil {
-		return params, err
+		return nil, fmt.Errorf("解析URL参数失败: %v", err)
 	}
 
-	for key, values := range parsedURL.Query() {
-		if len(values) > 0 {
-			params[key] = values[0]
-		}
+	return &models.ParsedRequest{
+		Method:      method,
+		URL:         requestURL,
+		Headers:     headers,
+		Cookies:     c.cookies,
+		Body:        body,
+		QueryParams: queryParams,
+		ContentType: headerValue(headers, "Content-Type"),
+		HeaderOrder: headerOrder,
+		CookieOrder: c.cookieOrder,
+
+		RepeatedHeaders: repeatedHeaders(headers, c.values),
+		Warnings:        c.warnings,
+	}, nil
+}
+
+// appendURLQuery 把查询串追加到URL（保留片段）
+func appendURLQuery(rawURL, query string) string {
+	if query == "" {
+		return rawURL
+	}
+	base, fragment, hasFragment := strings.Cut(rawURL, "#")
+	switch {
+	case !strings.Contains(base, "?"):
+		base += "?"
+	case !strings.HasSuffix(base, "?") && !strings.HasSuffix(base, "&"):
+		base += "&"
 	}
+	base += query
+	if hasFragment {
+		base += "#" + fragment
+	}
+	return base
+}
 
-	return params, nil
+// headerKey 不区分大小写查找请求头，返回实际使用的名称
+func headerKey(headers map[string]string, name string) (string, bool) {
+	if _, exists := headers[name]; exists {
+		return name, true
+	}
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return key, true
+		}
+	}
+	return "", false
 }
 
 // IsCurlCommand 检测是否为Curl命令
 func (p *CurlRequestParser) IsCurlCommand(input string) bool {
 	trimmed := strings.TrimSpace(input)
+	if len(trimmed) >= 9 && strings.EqualFold(trimmed[:9], "curl.exe ") {
+		return true
+	}
 	return strings.HasPrefix(trimmed, "curl ") || trimmed == "curl"
 }
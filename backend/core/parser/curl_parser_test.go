@@ -1,6 +1,9 @@
 package parser
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestCurlRequestParser_ParseDefaultsToPostWhenBodyProvided(t *testing.T) {
 	parser := NewCurlRequestParser()
@@ -35,3 +38,197 @@ func TestCurlRequestParser_ExtractURLSkipsOptionValues(t *testing.T) {
 		t.Fatalf("expected URL to be detected from trailing argument, got %q", req.URL)
 	}
 }
+
+func TestCurlRequestParser_PreservesHeaderAndCookieOrder(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl 'https://example.com/' -H 'X-B: 2' -H 'Accept: */*' -H 'X-A: 1' -b 'z=1; a=2; m=3'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "X-B,Accept,X-A" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if got := strings.Join(req.OrderedCookieNames(), ","); got != "z,a,m" {
+		t.Fatalf("unexpected cookie order: %s", got)
+	}
+}
+
+func TestCurlRequestParser_ANSICQuotingAndContinuation(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse("curl 'https://example.com/upload' \\\r\n  -H $'X-Note: caf\\u00e9 \\x41\\101' \\\n  --data-raw $'line1\\r\\nit\\'s \"ok\"' \\\n  -H \"X-Quote: a \\\"b\\\" \\\\n\"")
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if req.Headers["X-Note"] != "café AA" {
+		t.Fatalf("expected ANSI-C escapes decoded, got %q", req.Headers["X-Note"])
+	}
+	if req.Body != "line1\r\nit's \"ok\"" {
+		t.Fatalf("expected ANSI-C body decoded, got %q", req.Body)
+	}
+	if req.Headers["X-Quote"] != `a "b" \n` {
+		t.Fatalf("expected double-quote escapes, got %q", req.Headers["X-Quote"])
+	}
+
+	if _, err := parser.Parse(`curl 'https://example.com/ -H 'a: b'`); err == nil {
+		t.Fatalf("expected error for unterminated quote")
+	}
+}
+
+func TestCurlRequestParser_DataOptions(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl https://example.com/form -d a=1 --data 'b=2' --data-binary 'c=3' --data-urlencode 'q=a b&c' --data-urlencode '=x/y' -d @body.txt`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if req.Method != "POST" || req.Body != "a=1&b=2&c=3&q=a%20b%26c&x%2Fy" {
+		t.Fatalf("expected concatenated form body, got %s %q", req.Method, req.Body)
+	}
+	if req.Headers["Content-Type"] != "application/x-www-form-urlencoded" || req.ContentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("expected default form content type, got %v", req.Headers)
+	}
+	if len(req.Warnings) != 1 || !strings.Contains(req.Warnings[0], "body.txt") {
+		t.Fatalf("expected warning for data file, got %v", req.Warnings)
+	}
+}
+
+func TestCurlRequestParser_GetMovesDataToQuery(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl -G 'https://example.com/search?lang=en#top' -d q=go --data-urlencode 'tag=a b' --url-query 'page=2' --url-query '+raw=%41'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if req.Method != "GET" || req.Body != "" {
+		t.Fatalf("expected GET without body, got %s %q", req.Method, req.Body)
+	}
+	if req.URL != "https://example.com/search?lang=en&page=2&raw=%41&q=go&tag=a%20b#top" {
+		t.Fatalf("unexpected URL: %s", req.URL)
+	}
+	if req.QueryParams["tag"] != "a b" || req.QueryParams["raw"] != "A" {
+		t.Fatalf("unexpected query params: %v", req.QueryParams)
+	}
+	if _, exists := req.Headers["Content-Type"]; exists {
+		t.Fatalf("expected no content type for -G, got %v", req.Headers)
+	}
+}
+
+func TestCurlRequestParser_ImplicitHeaders(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl -sSL -A 'probe/1.0' -e 'https://example.com/;auto' -u alice:secret --compressed -H 'X-Id: 1' https://example.com/`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "Authorization,User-Agent,Referer,Accept-Encoding,X-Id" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if req.Headers["Authorization"] != "Basic YWxpY2U6c2VjcmV0" || req.Headers["User-Agent"] != "probe/1.0" ||
+		req.Headers["Referer"] != "https://example.com/" || req.Headers["Accept-Encoding"] != "deflate, gzip, br, zstd" {
+		t.Fatalf("unexpected implicit headers: %v", req.Headers)
+	}
+	if len(req.Warnings) != 1 || !strings.Contains(req.Warnings[0], "--location") {
+		t.Fatalf("expected warning for -L only, got %v", req.Warnings)
+	}
+
+	// 显式请求头优先，"Name:" 移除请求头
+	req, err = parser.Parse(`curl https://example.com/ -A probe -H 'user-agent: custom' -e https://ref/ -H 'Referer:' -H 'X-Empty;'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "user-agent,X-Empty" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	if req.Headers["user-agent"] != "custom" || req.Headers["X-Empty"] != "" {
+		t.Fatalf("unexpected header values: %v", req.Headers)
+	}
+}
+
+func TestCurlRequestParser_HeadAndExplicitMethod(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl -I https://example.com/`)
+	if err != nil || req.Method != "HEAD" {
+		t.Fatalf("expected HEAD, got %v %v", req, err)
+	}
+
+	req, err = parser.Parse(`curl -XPUT example.com/items -d x=1`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "PUT" || req.URL != "http://example.com/items" {
+		t.Fatalf("expected PUT to http URL, got %s %s", req.Method, req.URL)
+	}
+}
+
+func TestCurlRequestParser_JSON(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl https://example.com/api --json '{"a":' --json '1}'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if req.Method != "POST" || req.Body != `{"a":1}` {
+		t.Fatalf("expected concatenated JSON body, got %s %q", req.Method, req.Body)
+	}
+	if req.Headers["Content-Type"] != "application/json" || req.Headers["Accept"] != "application/json" {
+		t.Fatalf("expected JSON headers, got %v", req.Headers)
+	}
+}
+
+func TestCurlRequestParser_Form(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl https://example.com/upload -F 'title=hi' --form-string 'note=@literal' -F 'file=@/tmp/a.png;type=image/png'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if req.Method != "POST" || req.ContentType != "multipart/form-data; boundary="+curlFormBoundary {
+		t.Fatalf("unexpected method or content type: %s %q", req.Method, req.ContentType)
+	}
+	if !strings.Contains(req.Body, "name=\"title\"\r\n\r\nhi\r\n") || !strings.Contains(req.Body, "@literal") ||
+		!strings.Contains(req.Body, `filename="a.png"`) || !strings.Contains(req.Body, "Content-Type: image/png") {
+		t.Fatalf("unexpected multipart body: %q", req.Body)
+	}
+	if len(req.Warnings) != 1 || !strings.Contains(req.Warnings[0], "/tmp/a.png") {
+		t.Fatalf("expected warning for uploaded file, got %v", req.Warnings)
+	}
+
+	if _, err := parser.Parse(`curl https://example.com/ -F a=1 -d b=2`); err == nil {
+		t.Fatalf("expected error when mixing -F and -d")
+	}
+}
+
+func TestCurlRequestParser_CookiesAndUnsupportedOptions(t *testing.T) {
+	parser := NewCurlRequestParser()
+
+	req, err := parser.Parse(`curl https://example.com/ -H 'Cookie: a=1; b=2' -b 'c=3' -b jar.txt --proxy http://127.0.0.1:8080 -k --no-progress-meter -o out.html --frobnicate`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if got := strings.Join(req.OrderedCookieNames(), ","); got != "a,b,c" {
+		t.Fatalf("expected cookies from header and -b, got %s", got)
+	}
+	if req.URL != "https://example.com/" {
+		t.Fatalf("expected option values not to be taken as URL, got %q", req.URL)
+	}
+	want := []string{"jar.txt", "--proxy", "--insecure", "--frobnicate"}
+	if len(req.Warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %v", len(want), req.Warnings)
+	}
+	for i, fragment := range want {
+		if !strings.Contains(req.Warnings[i], fragment) {
+			t.Fatalf("expected warning %d to mention %s, got %q", i, fragment, req.Warnings[i])
+		}
+	}
+}
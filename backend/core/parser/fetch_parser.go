@@ -0,0 +1,509 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"RequestProbe/backend/models"
+)
+
+// fetchIgnoredOptions 只影响浏览器行为、不改变请求内容的 fetch 选项
+var fetchIgnoredOptions = map[string]bool{
+	"mode": true, "credentials": true, "referrerPolicy": true, "cache": true, "redirect": true,
+	"integrity": true, "keepalive": true, "priority": true, "signal": true,
+}
+
+// FetchRequestParser fetch 调用解析器（浏览器 "Copy as fetch"）
+//
+// 只读取 fetch("url", {...}) 中的字面量，不执行任何 JavaScript：
+// 支持单双引号字符串、无插值的模板字符串、不加引号的键、尾随逗号与注释。
+type FetchRequestParser struct{}
+
+// NewFetchRequestParser 创建fetch解析器
+func NewFetchRequestParser() *FetchRequestParser {
+	return &FetchRequestParser{}
+}
+
+// jsObject 保持键顺序的对象字面量
+type jsObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// jsLiteralReader JavaScript 字面量读取器
+//
+// 值的类型：string、jsNumber、bool、nil（null/undefined）、*jsObject 与 []interface{}。
+type jsLiteralReader struct {
+	runes []rune
+	pos   int
+}
+
+// jsNumber 数字字面量（保留原始写法）
+type jsNumber string
+
+// Parse 解析fetch调用
+func (p *FetchRequestParser) Parse(input string) (*models.ParsedRequest, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("fetch调用不能为空")
+	}
+
+	reader := &jsLiteralReader{runes: []rune(strings.ReplaceAll(input, "\r\n", "\n"))}
+	requestURL, options, err := reader.readFetchCall()
+	if err != nil {
+		return nil, fmt.Errorf("解析fetch调用失败: %v", err)
+	}
+
+	req := &models.ParsedRequest{
+		Method:  "GET",
+		URL:     requestURL,
+		Headers: make(map[string]string),
+		Cookies: make(map[string]string),
+	}
+	setHeader := func(key, value string) {
+		if _, exists := req.Headers[key]; !exists {
+			req.HeaderOrder = append(req.HeaderOrder, key)
+		}
+		req.Headers[key] = value
+	}
+
+	var referrer string
+	for _, key := range options.keys {
+		value := options.values[key]
+		switch {
+		case key == "method":
+			method, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("method 必须是字符串")
+			}
+			req.Method = strings.ToUpper(method)
+		case key == "headers":
+			headers, err := fetchHeaders(value)
+			if err != nil {
+				return nil, err
+			}
+			for _, header := range headers {
+				setHeader(header[0], header[1])
+			}
+		case key == "body":
+			switch body := value.(type) {
+			case string:
+				req.Body = body
+			case nil:
+			default:
+				req.Warnings = append(req.Warnings, "body 不是字符串字面量，已忽略")
+			}
+		case key == "referrer":
+			referrer, _ = value.(string)
+		case fetchIgnoredOptions[key]:
+		default:
+			req.Warnings = append(req.Warnings, fmt.Sprintf("不支持的选项 %s，已忽略", key))
+		}
+	}
+
+	// 浏览器按 referrer 选项发送 Referer（"about:client" 表示默认值，无法还原）
+	if _, exists := headerKey(req.Headers, "Referer"); !exists && referrer != "" && referrer != "about:client" {
+		setHeader("Referer", referrer)
+	}
+
+	// 与Raw格式一致，把cookie请求头解析为Cookie字段
+	if key, exists := headerKey(req.Headers, "Cookie"); exists {
+		req.Cookies, req.CookieOrder = NewRawRequestParser().parseCookieHeader(req.Headers[key])
+	}
+	req.ContentType = headerValue(req.Headers, "Content-Type")
+
+	queryParams, err := NewRawRequestParser().parseQueryParams(requestURL)
+	if err != nil {
+		return nil, fmt.Errorf("解析URL参数失败: %v", err)
+	}
+	req.QueryParams = queryParams
+	return req, nil
+}
+
+// IsFetchCommand 检测是否为fetch调用
+func (p *FetchRequestParser) IsFetchCommand(input string) bool {
+	trimmed := strings.TrimSpace(input)
+	if rest, ok := strings.CutPrefix(trimmed, "await"); ok && rest != "" && unicode.IsSpace(rune(rest[0])) {
+		trimmed = strings.TrimSpace(rest)
+	}
+	rest, ok := strings.CutPrefix(trimmed, "fetch")
+	return ok && strings.HasPrefix(strings.TrimSpace(rest), "(")
+}
+
+// fetchHeaders 读取 headers 选项：对象字面量或 [name, value] 数组
+func fetchHeaders(value interface{}) ([][2]string, error) {
+	var headers [][2]string
+	switch headerValues := value.(type) {
+	case *jsObject:
+		for _, key := range headerValues.keys {
+			text, ok := jsText(headerValues.values[key])
+			if !ok {
+				return nil, fmt.Errorf("请求头 %s 的值必须是字符串", key)
+			}
+			headers = append(headers, [2]string{key, text})
+		}
+	case []interface{}:
+		for _, item := range headerValues {
+			pair, ok := item.([]interface{})
+			if !ok || len(pair) != 2 {
+				return nil, fmt.Errorf("headers 数组的每一项必须是 [名称, 值]")
+			}
+			key, keyOK := pair[0].(string)
+			text, valueOK := jsText(pair[1])
+			if !keyOK || !valueOK {
+				return nil, fmt.Errorf("headers 数组的每一项必须是 [名称, 值]")
+			}
+			headers = append(headers, [2]string{key, text})
+		}
+	case nil:
+	default:
+		return nil, fmt.Errorf("headers 必须是对象字面量")
+	}
+	return headers, nil
+}
+
+// jsText 把字符串或数字字面量转为请求头的值
+func jsText(value interface{}) (string, bool) {
+	switch text := value.(type) {
+	case string:
+		return text, true
+	case jsNumber:
+		return string(text), true
+	default:
+		return "", false
+	}
+}
+
+// readFetchCall 读取 [await] fetch(url[, options])[;]
+func (r *jsLiteralReader) readFetchCall() (string, *jsObject, error) {
+	r.skipSpace()
+	if r.readIdentifier() == "await" {
+		r.skipSpace()
+	} else {
+		r.pos = 0
+		r.skipSpace()
+	}
+	if r.readIdentifier() != "fetch" {
+		return "", nil, r.errorf("未找到fetch调用")
+	}
+	if err := r.expect('('); err != nil {
+		return "", nil, err
+	}
+
+	value, err := r.readValue()
+	if err != nil {
+		return "", nil, err
+	}
+	requestURL, ok := value.(string)
+	if !ok || requestURL == "" {
+		return "", nil, fmt.Errorf("fetch 的第一个参数必须是URL字符串")
+	}
+
+	options := &jsObject{values: make(map[string]interface{})}
+	r.skipSpace()
+	if r.peek() == ',' {
+		r.pos++
+		r.skipSpace()
+		if r.peek() != ')' {
+			value, err := r.readValue()
+			if err != nil {
+				return "", nil, err
+			}
+			if options, ok = value.(*jsObject); !ok {
+				return "", nil, fmt.Errorf("fetch 的第二个参数必须是对象字面量")
+			}
+			r.skipSpace()
+			if r.peek() == ',' {
+				r.pos++
+			}
+		}
+	}
+	if err := r.expect(')'); err != nil {
+		return "", nil, err
+	}
+
+	r.skipSpace()
+	if r.peek() == ';' {
+		r.pos++
+		r.skipSpace()
+	}
+	if r.pos < len(r.runes) {
+		return "", nil, r.errorf("fetch调用之后存在多余内容")
+	}
+	return requestURL, options, nil
+}
+
+// errorf 生成带位置的错误
+func (r *jsLiteralReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("位置 %d: %s", r.pos+1, fmt.Sprintf(format, args...))
+}
+
+// peek 返回当前字符（已到末尾时返回0）
+func (r *jsLiteralReader) peek() rune {
+	if r.pos >= len(r.runes) {
+		return 0
+	}
+	return r.runes[r.pos]
+}
+
+// expect 跳过空白后读取指定字符
+func (r *jsLiteralReader) expect(char rune) error {
+	r.skipSpace()
+	if r.peek() != char {
+		return r.errorf("应为 %q", char)
+	}
+	r.pos++
+	return nil
+}
+
+// skipSpace 跳过空白与 // 、/* */ 注释
+func (r *jsLiteralReader) skipSpace() {
+	for r.pos < len(r.runes) {
+		char := r.runes[r.pos]
+		switch {
+		case unicode.IsSpace(char):
+			r.pos++
+		case char == '/' && r.pos+1 < len(r.runes) && r.runes[r.pos+1] == '/':
+			for r.pos < len(r.runes) && r.runes[r.pos] != '\n' {
+				r.pos++
+			}
+		case char == '/' && r.pos+1 < len(r.runes) && r.runes[r.pos+1] == '*':
+			end := r.pos + 2
+			for end+1 < len(r.runes) && !(r.runes[end] == '*' && r.runes[end+1] == '/') {
+				end++
+			}
+			r.pos = min(end+2, len(r.runes))
+		default:
+			return
+		}
+	}
+}
+
+// readIdentifier 读取标识符（不是标识符时返回空字符串）
+func (r *jsLiteralReader) readIdentifier() string {
+	start := r.pos
+	for r.pos < len(r.runes) {
+		char := r.runes[r.pos]
+		if !(unicode.IsLetter(char) || char == '_' || char == '$' || r.pos > start && unicode.IsDigit(char)) {
+			break
+		}
+		r.pos++
+	}
+	return string(r.runes[start:r.pos])
+}
+
+// readValue 读取一个字面量
+func (r *jsLiteralReader) readValue() (interface{}, error) {
+	r.skipSpace()
+	char := r.peek()
+	switch {
+	case char == '"' || char == '\'' || char == '`':
+		return r.readString()
+	case char == '{':
+		return r.readObject()
+	case char == '[':
+		return r.readArray()
+	case char == '-' || char == '+' || char == '.' || unicode.IsDigit(char):
+		return r.readNumber()
+	}
+
+	start := r.pos
+	switch identifier := r.readIdentifier(); identifier {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "undefined":
+		return nil, nil
+	case "":
+		return nil, r.errorf("无法识别的字面量")
+	default:
+		r.pos = start
+		return nil, r.errorf("不支持表达式 %s，只能使用字面量", identifier)
+	}
+}
+
+// readObject 读取对象字面量（键可以是字符串、标识符或数字）
+func (r *jsLiteralReader) readObject() (*jsObject, error) {
+	object := &jsObject{values: make(map[string]interface{})}
+	r.pos++ // {
+	for {
+		r.skipSpace()
+		if r.peek() == '}' {
+			r.pos++
+			return object, nil
+		}
+
+		var key string
+		switch char := r.peek(); {
+		case char == '"' || char == '\'':
+			value, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			key = value
+		case unicode.IsDigit(char):
+			value, err := r.readNumber()
+			if err != nil {
+				return nil, err
+			}
+			key = string(value)
+		default:
+			if key = r.readIdentifier(); key == "" {
+				return nil, r.errorf("应为对象的键")
+			}
+		}
+
+		if err := r.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := object.values[key]; !exists {
+			object.keys = append(object.keys, key)
+		}
+		object.values[key] = value
+
+		r.skipSpace()
+		switch r.peek() {
+		case ',':
+			r.pos++
+		case '}':
+		default:
+			return nil, r.errorf("对象中应为 ',' 或 '}'")
+		}
+	}
+}
+
+// readArray 读取数组字面量
+func (r *jsLiteralReader) readArray() ([]interface{}, error) {
+	items := []interface{}{}
+	r.pos++ // [
+	for {
+		r.skipSpace()
+		if r.peek() == ']' {
+			r.pos++
+			return items, nil
+		}
+		value, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+
+		r.skipSpace()
+		switch r.peek() {
+		case ',':
+			r.pos++
+		case ']':
+		default:
+			return nil, r.errorf("数组中应为 ',' 或 ']'")
+		}
+	}
+}
+
+// readNumber 读取数字字面量
+func (r *jsLiteralReader) readNumber() (jsNumber, error) {
+	start := r.pos
+	for r.pos < len(r.runes) && (unicode.IsDigit(r.runes[r.pos]) || unicode.IsLetter(r.runes[r.pos]) || strings.ContainsRune("+-._", r.runes[r.pos])) {
+		r.pos++
+	}
+	text := strings.ReplaceAll(string(r.runes[start:r.pos]), "_", "")
+	if _, err := strconv.ParseFloat(text, 64); err != nil {
+		if _, err := strconv.ParseInt(text, 0, 64); err != nil {
+			r.pos = start
+			return "", r.errorf("无效的数字 %s", text)
+		}
+	}
+	return jsNumber(strings.TrimPrefix(text, "+")), nil
+}
+
+// readString 读取字符串字面量（模板字符串不能包含 ${} 插值）
+func (r *jsLiteralReader) readString() (string, error) {
+	quote := r.runes[r.pos]
+	r.pos++
+	var out strings.Builder
+	for r.pos < len(r.runes) {
+		char := r.runes[r.pos]
+		r.pos++
+		switch {
+		case char == quote:
+			return out.String(), nil
+		case char == '\\':
+			if err := r.readEscape(&out); err != nil {
+				return "", err
+			}
+		case quote == '`' && char == '$' && r.peek() == '{':
+			r.pos--
+			return "", r.errorf("不支持模板字符串插值")
+		case char == '\n' && quote != '`':
+			return "", r.errorf("字符串中不能直接换行")
+		default:
+			out.WriteRune(char)
+		}
+	}
+	return "", r.errorf("字符串未闭合")
+}
+
+// readEscape 解码反斜杠之后的转义序列
+func (r *jsLiteralReader) readEscape(out *strings.Builder) error {
+	if r.pos >= len(r.runes) {
+		return r.errorf("字符串未闭合")
+	}
+	char := r.runes[r.pos]
+	r.pos++
+	switch char {
+	case 'n':
+		out.WriteByte('\n')
+	case 'r':
+		out.WriteByte('\r')
+	case 't':
+		out.WriteByte('\t')
+	case 'b':
+		out.WriteByte('\b')
+	case 'f':
+		out.WriteByte('\f')
+	case 'v':
+		out.WriteByte('\v')
+	case '0':
+		out.WriteByte(0)
+	case '\n':
+		// 续行
+	case 'x':
+		value, n := readEscapeDigits(r.runes, r.pos, 16, 2)
+		if n != 2 {
+			return r.errorf("无效的 \\x 转义")
+		}
+		out.WriteRune(rune(value))
+		r.pos += n
+	case 'u':
+		if r.peek() == '{' {
+			value, n := readEscapeDigits(r.runes, r.pos+1, 16, 6)
+			if n == 0 || r.pos+1+n >= len(r.runes) || r.runes[r.pos+1+n] != '}' {
+				return r.errorf("无效的 \\u{} 转义")
+			}
+			out.WriteRune(rune(value))
+			r.pos += n + 2
+			return nil
+		}
+		value, n := readEscapeDigits(r.runes, r.pos, 16, 4)
+		if n != 4 {
+			return r.errorf("无效的 \\u 转义")
+		}
+		r.pos += n
+		// 代理对
+		if value >= 0xD800 && value < 0xDC00 && r.pos+5 < len(r.runes) && r.runes[r.pos] == '\\' && r.runes[r.pos+1] == 'u' {
+			if low, m := readEscapeDigits(r.runes, r.pos+2, 16, 4); m == 4 && low >= 0xDC00 && low < 0xE000 {
+				value = 0x10000 + (value-0xD800)<<10 + (low - 0xDC00)
+				r.pos += 6
+			}
+		}
+		out.WriteRune(rune(value))
+	default:
+		out.WriteRune(char)
+	}
+	return nil
+}
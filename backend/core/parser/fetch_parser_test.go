@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFetchRequestParser_ChromeExport(t *testing.T) {
+	input := `fetch("https://example.com/api/login?from=web", {
+  "headers": {
+    "accept": "application/json",
+    "content-type": "application/json",
+    "cookie": "sid=abc; theme=dark",
+    "x-emoji": "😀 \u{1F600} caf\xe9"
+  },
+  "referrer": "https://example.com/login",
+  "referrerPolicy": "strict-origin-when-cross-origin",
+  "body": "{\"user\":\"alice\",\"pass\":\"a\\\"b\"}",
+  "method": "POST",
+  "mode": "cors",
+  "credentials": "include"
+});`
+
+	parser := NewUnifiedRequestParser()
+	if inputType := parser.DetectInputType(input); inputType != "fetch" {
+		t.Fatalf("expected fetch input type, got %q", inputType)
+	}
+
+	req, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "POST" || req.URL != "https://example.com/api/login?from=web" || req.QueryParams["from"] != "web" {
+		t.Fatalf("unexpected request line: %s %s", req.Method, req.URL)
+	}
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "accept,content-type,cookie,x-emoji,Referer" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if req.Headers["x-emoji"] != "😀 😀 café" || req.Headers["Referer"] != "https://example.com/login" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	if got := strings.Join(req.OrderedCookieNames(), ","); got != "sid,theme" || req.Cookies["theme"] != "dark" {
+		t.Fatalf("expected cookies from cookie header, got %v", req.Cookies)
+	}
+	if req.Body != `{"user":"alice","pass":"a\"b"}` || req.ContentType != "application/json" {
+		t.Fatalf("unexpected body: %q (%s)", req.Body, req.ContentType)
+	}
+	if len(req.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", req.Warnings)
+	}
+}
+
+func TestFetchRequestParser_JSON5Literals(t *testing.T) {
+	input := "await fetch('https://example.com/items', {\n" +
+		"  // Firefox 风格\n" +
+		"  method: 'put', /* 注释 */\n" +
+		"  headers: [['X-Count', 3], [\"X-Name\", `tmpl`],],\n" +
+		"  body: null,\n" +
+		"  window: null,\n" +
+		"},)"
+
+	req, err := NewFetchRequestParser().Parse(input)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "PUT" || req.Headers["X-Count"] != "3" || req.Headers["X-Name"] != "tmpl" || req.Body != "" {
+		t.Fatalf("unexpected request: %#v", req)
+	}
+	if len(req.Warnings) != 1 || !strings.Contains(req.Warnings[0], "window") {
+		t.Fatalf("expected warning for unknown option, got %v", req.Warnings)
+	}
+
+	req, err = NewFetchRequestParser().Parse(`fetch("https://example.com/")`)
+	if err != nil || req.Method != "GET" || len(req.Headers) != 0 {
+		t.Fatalf("expected bare fetch to be a GET, got %#v %v", req, err)
+	}
+}
+
+func TestFetchRequestParser_RejectsExpressions(t *testing.T) {
+	inputs := []string{
+		`fetch(url)`,
+		`fetch("https://example.com/", { body: JSON.stringify({a: 1}) })`,
+		"fetch(`https://example.com/${id}`)",
+		`fetch("https://example.com/", { method: "POST" }).then(r => r.json())`,
+		`fetch("https://example.com/", { method: "POST" `,
+		`fetch("https://example.com/", { "a": 1 "b": 2 })`,
+	}
+	for _, input := range inputs {
+		if _, err := NewFetchRequestParser().Parse(input); err == nil {
+			t.Errorf("expected error for %s", input)
+		}
+	}
+}
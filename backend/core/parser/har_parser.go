@@ -0,0 +1,441 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+// HarRequestParser HAR 1.2 文件解析器（DevTools、Charles 等导出的抓包）
+type HarRequestParser struct{}
+
+// NewHarRequestParser 创建HAR解析器
+func NewHarRequestParser() *HarRequestParser {
+	return &HarRequestParser{}
+}
+
+// harFile HAR文件
+type harFile struct {
+	Log struct {
+		Version string     `json:"version"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry HAR条目
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"` // 毫秒
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+}
+
+// harRequest HAR请求
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harCookie    `json:"cookies"`
+	PostData    *harPostData   `json:"postData"`
+}
+
+// harResponse HAR响应
+type harResponse struct {
+	Status      int            `json:"status"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harCookie    `json:"cookies"`
+	Content     harContent     `json:"content"`
+}
+
+// harNameValue 名称-值对（请求头、查询参数）
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harCookie HAR Cookie
+type harCookie struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Path   string `json:"path"`
+	Domain string `json:"domain"`
+}
+
+// harPostData 请求体
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text"`
+	Params   []harParam `json:"params"`
+}
+
+// harParam 表单参数
+type harParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+}
+
+// harContent 响应体
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding"`
+}
+
+// Parse 解析HAR中的第一个条目（实现 RequestParser 接口）
+func (p *HarRequestParser) Parse(input string) (*models.ParsedRequest, error) {
+	imported, err := p.ParseEntry(input, 0)
+	if err != nil {
+		return nil, err
+	}
+	return imported.Request, nil
+}
+
+// load 读取HAR文件
+func (p *HarRequestParser) load(input string) (*harFile, error) {
+	var har harFile
+	if err := json.Unmarshal([]byte(strings.TrimSpace(input)), &har); err != nil {
+		return nil, fmt.Errorf("HAR文件格式错误: %v", err)
+	}
+	if len(har.Log.Entries) == 0 {
+		return nil, fmt.Errorf("HAR文件中没有请求条目")
+	}
+	return &har, nil
+}
+
+// ListEntries 按条件列出HAR中的条目
+func (p *HarRequestParser) ListEntries(input string, filter models.HarEntryFilter) ([]models.HarEntrySummary, error) {
+	har, err := p.load(input)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := []models.HarEntrySummary{}
+	for i, entry := range har.Log.Entries {
+		host := ""
+		if parsedURL, err := url.Parse(entry.Request.URL); err == nil {
+			host = parsedURL.Host
+		}
+
+		if filter.Host != "" && !strings.Contains(strings.ToLower(host), strings.ToLower(strings.TrimSpace(filter.Host))) {
+			continue
+		}
+		if filter.Method != "" && !strings.EqualFold(entry.Request.Method, strings.TrimSpace(filter.Method)) {
+			continue
+		}
+		if filter.MimeType != "" && !strings.Contains(strings.ToLower(entry.Response.Content.MimeType), strings.ToLower(strings.TrimSpace(filter.MimeType))) {
+			continue
+		}
+		if !matchStatusFilter(filter.Status, entry.Response.Status) {
+			continue
+		}
+
+		summaries = append(summaries, models.HarEntrySummary{
+			Index:           i,
+			StartedDateTime: entry.StartedDateTime,
+			Method:          strings.ToUpper(entry.Request.Method),
+			URL:             entry.Request.URL,
+			Host:            host,
+			Status:          entry.Response.Status,
+			MimeType:        entry.Response.Content.MimeType,
+			Size:            entry.Response.Content.Size,
+			Duration:        harDuration(entry.Time),
+		})
+	}
+	return summaries, nil
+}
+
+// matchStatusFilter 匹配状态码条件：精确值（如 404）或状态类（如 2xx）
+func matchStatusFilter(filter string, status int) bool {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return true
+	}
+	if len(filter) == 3 && strings.HasSuffix(filter, "xx") {
+		return strconv.Itoa(status/100) == filter[:1]
+	}
+	return filter == strconv.Itoa(status)
+}
+
+// ParseEntry 把指定序号的条目转换为请求，同时返回HAR中记录的响应
+//
+// 同名请求头合并为一个值（Cookie 用 "; "，其余用 ", "），HTTP/2 伪头部（如 :authority）被忽略。
+// postData 没有 text 而只有 params 时，按 mimeType 重建表单或 multipart 请求体。
+func (p *HarRequestParser) ParseEntry(input string, index int) (*models.HarImport, error) {
+	har, err := p.load(input)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(har.Log.Entries) {
+		return nil, fmt.Errorf("HAR条目序号超出范围: %d（共%d条）", index, len(har.Log.Entries))
+	}
+
+	entry := har.Log.Entries[index]
+	request, err := p.convertRequest(entry.Request)
+	if err != nil {
+		return nil, fmt.Errorf("转换第%d个HAR条目失败: %v", index, err)
+	}
+	response, err := p.convertResponse(entry)
+	if err != nil {
+		return nil, fmt.Errorf("转换第%d个HAR条目的响应失败: %v", index, err)
+	}
+	return &models.HarImport{Request: request, Response: response}, nil
+}
+
+// convertRequest 转换HAR请求
+func (p *HarRequestParser) convertRequest(harReq harRequest) (*models.ParsedRequest, error) {
+	if harReq.URL == "" {
+		return nil, fmt.Errorf("未找到请求URL")
+	}
+
+	request := &models.ParsedRequest{
+		Method:      strings.ToUpper(harReq.Method),
+		URL:         harReq.URL,
+		Headers:     make(map[string]string),
+		Cookies:     make(map[string]string),
+		QueryParams: make(map[string]string),
+	}
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+
+	// 请求头：保持顺序，同名头（不区分大小写，名称按首次出现的写法）保留全部取值，Headers 中为最后一个取值
+	names := make(map[string]string)
+	values := make(map[string][]string)
+	for _, header := range harReq.Headers {
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		lower := strings.ToLower(header.Name)
+		name, exists := names[lower]
+		if !exists {
+			name = header.Name
+			names[lower] = name
+			request.HeaderOrder = append(request.HeaderOrder, name)
+		}
+		request.Headers[name] = header.Value
+		values[name] = append(values[name], header.Value)
+	}
+	request.RepeatedHeaders = repeatedHeaders(request.Headers, values)
+
+	// Cookie：优先按 Cookie 头（含重复的 Cookie 头）解析，没有时使用 cookies 列表
+	if name, exists := names["cookie"]; exists {
+		parser := NewRawRequestParser()
+		for _, value := range values[name] {
+			cookies, order := parser.parseCookieHeader(value)
+			for _, cookieName := range order {
+				if _, exists := request.Cookies[cookieName]; !exists {
+					request.CookieOrder = append(request.CookieOrder, cookieName)
+				}
+				request.Cookies[cookieName] = cookies[cookieName]
+			}
+		}
+	} else {
+		for _, cookie := range harReq.Cookies {
+			if _, exists := request.Cookies[cookie.Name]; !exists {
+				request.CookieOrder = append(request.CookieOrder, cookie.Name)
+			}
+			request.Cookies[cookie.Name] = cookie.Value
+		}
+	}
+
+	// 查询参数：以URL为准，URL中没有时使用 queryString
+	parsedURL, err := url.Parse(harReq.URL)
+	if err != nil {
+		return nil, fmt.Errorf("解析URL参数失败: %v", err)
+	}
+	for key, values := range parsedURL.Query() {
+		request.QueryParams[key] = values[0]
+	}
+	if len(request.QueryParams) == 0 && len(harReq.QueryString) > 0 {
+		query := url.Values{}
+		for _, param := range harReq.QueryString {
+			query.Add(param.Name, param.Value)
+			if _, exists := request.QueryParams[param.Name]; !exists {
+				request.QueryParams[param.Name] = param.Value
+			}
+		}
+		separator := "?"
+		if strings.Contains(request.URL, "?") {
+			separator = "&"
+		}
+		request.URL += separator + query.Encode()
+	}
+
+	// 请求体
+	request.ContentType = headerValue(request.Headers, "Content-Type")
+	if postData := harReq.PostData; postData != nil {
+		if request.ContentType == "" {
+			request.ContentType = postData.MimeType
+		}
+		body, contentType, err := harPostBody(postData, request.ContentType)
+		if err != nil {
+			return nil, err
+		}
+		request.Body = body
+		if contentType != request.ContentType {
+			// 重建 multipart 请求体时生成了新的分隔符
+			request.ContentType = contentType
+			if name, exists := names["content-type"]; exists {
+				request.Headers[name] = contentType
+			}
+		}
+	}
+	return request, nil
+}
+
+// harPostBody 返回请求体；只有 params 时按表单或 multipart 重建
+func harPostBody(postData *harPostData, contentType string) (string, string, error) {
+	if postData.Text != "" || len(postData.Params) == 0 {
+		return postData.Text, contentType, nil
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if mediaType != "multipart/form-data" {
+		pairs := make([]string, 0, len(postData.Params))
+		for _, param := range postData.Params {
+			pairs = append(pairs, url.QueryEscape(param.Name)+"="+url.QueryEscape(param.Value))
+		}
+		return strings.Join(pairs, "&"), contentType, nil
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if boundary := params["boundary"]; boundary != "" {
+		if err := writer.SetBoundary(boundary); err != nil {
+			return "", "", fmt.Errorf("multipart 分隔符无效: %v", err)
+		}
+	}
+	for _, param := range postData.Params {
+		var part io.Writer
+		var err error
+		if param.FileName != "" {
+			part, err = writer.CreatePart(multipartFileHeader(param))
+		} else {
+			part, err = writer.CreateFormField(param.Name)
+		}
+		if err != nil {
+			return "", "", err
+		}
+		part.Write([]byte(param.Value))
+	}
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), writer.FormDataContentType(), nil
+}
+
+// multipartFileHeader 文件字段的 part 头
+func multipartFileHeader(param harParam) map[string][]string {
+	contentType := param.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	quote := strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+	disposition := fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quote.Replace(param.Name), quote.Replace(param.FileName))
+	return map[string][]string{
+		"Content-Disposition": {disposition},
+		"Content-Type":        {contentType},
+	}
+}
+
+// convertResponse 转换HAR中记录的响应
+func (p *HarRequestParser) convertResponse(entry harEntry) (*models.ResponseData, error) {
+	harResp := entry.Response
+	body := []byte(harResp.Content.Text)
+	if strings.EqualFold(harResp.Content.Encoding, "base64") {
+		decoded, err := base64.StdEncoding.DecodeString(harResp.Content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("响应体 base64 解码失败: %v", err)
+		}
+		body = decoded
+	}
+
+	response := &models.ResponseData{
+		StatusCode:     harResp.Status,
+		Headers:        make(map[string]string),
+		Body:           string(body),
+		Cookies:        make([]models.ResponseCookie, 0, len(harResp.Cookies)),
+		URL:            entry.Request.URL,
+		Duration:       harDuration(entry.Time),
+		ContentLength:  int64(len(body)),
+		CharacterCount: len([]rune(string(body))),
+		RawBody:        body,
+		Protocol:       harResp.HTTPVersion,
+	}
+	// 同名响应头合并为一个取值：Set-Cookie 无法用逗号合并，按行分隔，其余按 RFC 9110 以 ", " 合并
+	names := make(map[string]string)
+	for _, header := range harResp.Headers {
+		if strings.HasPrefix(header.Name, ":") {
+			continue
+		}
+		lower := strings.ToLower(header.Name)
+		name, exists := names[lower]
+		if !exists {
+			names[lower] = header.Name
+			response.Headers[header.Name] = header.Value
+			continue
+		}
+		separator := ", "
+		if lower == "set-cookie" {
+			separator = "\n"
+		}
+		response.Headers[name] += separator + header.Value
+	}
+	for _, cookie := range harResp.Cookies {
+		response.Cookies = append(response.Cookies, models.ResponseCookie{
+			Name:   cookie.Name,
+			Value:  cookie.Value,
+			Domain: cookie.Domain,
+			Path:   cookie.Path,
+		})
+	}
+	return response, nil
+}
+
+// harDuration 把HAR中的毫秒数转换为时长（-1 表示未知）
+func harDuration(milliseconds float64) time.Duration {
+	if milliseconds <= 0 {
+		return 0
+	}
+	return time.Duration(milliseconds * float64(time.Millisecond))
+}
+
+// headerValue 不区分大小写地获取请求头
+func headerValue(headers map[string]string, name string) string {
+	for key, value := range headers {
+		if strings.EqualFold(key, name) {
+			return value
+		}
+	}
+	return ""
+}
+
+// IsHarFile 检测是否为HAR文件（包含 log.entries 的JSON）
+func (p *HarRequestParser) IsHarFile(input string) bool {
+	trimmed := strings.TrimSpace(input)
+	if !strings.HasPrefix(trimmed, "{") {
+		return false
+	}
+	var probe struct {
+		Log *struct {
+			Entries json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	return json.Unmarshal([]byte(trimmed), &probe) == nil && probe.Log != nil && probe.Log.Entries != nil
+}
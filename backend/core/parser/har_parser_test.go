@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+const testHar = `{
+  "log": {
+    "version": "1.2",
+    "entries": [
+      {
+        "startedDateTime": "2024-01-01T00:00:00.000Z",
+        "time": 120.5,
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/v1/login?from=web",
+          "httpVersion": "HTTP/2",
+          "headers": [
+            {"name": ":authority", "value": "api.example.com"},
+            {"name": "content-type", "value": "application/x-www-form-urlencoded"},
+            {"name": "accept", "value": "text/html"},
+            {"name": "Accept", "value": "application/json"},
+            {"name": "cookie", "value": "sid=abc"},
+            {"name": "cookie", "value": "theme=dark"}
+          ],
+          "queryString": [{"name": "from", "value": "web"}],
+          "cookies": [],
+          "postData": {
+            "mimeType": "application/x-www-form-urlencoded",
+            "params": [{"name": "user", "value": "alice"}, {"name": "pass", "value": "a&b"}]
+          }
+        },
+        "response": {
+          "status": 200,
+          "httpVersion": "HTTP/2",
+          "headers": [
+            {"name": "content-type", "value": "application/json"},
+            {"name": "Vary", "value": "Accept"},
+            {"name": "vary", "value": "Origin"},
+            {"name": "set-cookie", "value": "token=t1; Path=/"},
+            {"name": "set-cookie", "value": "lang=en"}
+          ],
+          "cookies": [{"name": "token", "value": "t1", "path": "/"}],
+          "content": {"size": 11, "mimeType": "application/json", "text": "eyJvayI6dHJ1ZX0=", "encoding": "base64"}
+        }
+      },
+      {
+        "startedDateTime": "2024-01-01T00:00:01.000Z",
+        "time": 30,
+        "request": {
+          "method": "GET",
+          "url": "https://cdn.example.com/app.js",
+          "headers": [],
+          "queryString": [],
+          "cookies": [{"name": "cdn", "value": "1"}]
+        },
+        "response": {"status": 304, "headers": [], "cookies": [], "content": {"size": 0, "mimeType": "application/javascript"}}
+      },
+      {
+        "startedDateTime": "2024-01-01T00:00:02.000Z",
+        "time": 80,
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/v1/upload",
+          "headers": [{"name": "Content-Type", "value": "multipart/form-data; boundary=XyZ"}],
+          "queryString": [],
+          "cookies": [],
+          "postData": {
+            "mimeType": "multipart/form-data; boundary=XyZ",
+            "params": [{"name": "title", "value": "hi"}, {"name": "file", "value": "data", "fileName": "a.txt", "contentType": "text/plain"}]
+          }
+        },
+        "response": {"status": 500, "headers": [], "cookies": [], "content": {"size": 5, "mimeType": "text/plain", "text": "error"}}
+      }
+    ]
+  }
+}`
+
+func TestHarRequestParser_ListEntriesWithFilters(t *testing.T) {
+	parser := NewHarRequestParser()
+
+	indexes := func(filter models.HarEntryFilter) []int {
+		t.Helper()
+		entries, err := parser.ListEntries(testHar, filter)
+		if err != nil {
+			t.Fatalf("expected list success, got error: %v", err)
+		}
+		result := []int{}
+		for _, entry := range entries {
+			result = append(result, entry.Index)
+		}
+		return result
+	}
+
+	tests := []struct {
+		filter models.HarEntryFilter
+		want   []int
+	}{
+		{models.HarEntryFilter{}, []int{0, 1, 2}},
+		{models.HarEntryFilter{Host: "API.example"}, []int{0, 2}},
+		{models.HarEntryFilter{Method: "get"}, []int{1}},
+		{models.HarEntryFilter{MimeType: "json"}, []int{0}},
+		{models.HarEntryFilter{Status: "5xx"}, []int{2}},
+		{models.HarEntryFilter{Status: "304"}, []int{1}},
+		{models.HarEntryFilter{Host: "api", Method: "POST", Status: "2xx"}, []int{0}},
+	}
+	for _, tt := range tests {
+		if got := indexes(tt.filter); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filter %+v: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestHarRequestParser_ParseEntry(t *testing.T) {
+	imported, err := NewHarRequestParser().ParseEntry(testHar, 0)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	req := imported.Request
+	if req.Method != "POST" || req.URL != "https://api.example.com/v1/login?from=web" || req.QueryParams["from"] != "web" {
+		t.Fatalf("unexpected request line: %s %s %v", req.Method, req.URL, req.QueryParams)
+	}
+	if !reflect.DeepEqual(req.HeaderOrder, []string{"content-type", "accept", "cookie"}) {
+		t.Fatalf("expected pseudo headers to be skipped and order kept, got %v", req.HeaderOrder)
+	}
+	if req.Headers["accept"] != "application/json" || req.Headers["cookie"] != "theme=dark" {
+		t.Fatalf("expected last value of repeated headers, got %v", req.Headers)
+	}
+	if !reflect.DeepEqual(req.HeaderValues("accept"), []string{"text/html", "application/json"}) ||
+		!reflect.DeepEqual(req.HeaderValues("cookie"), []string{"sid=abc", "theme=dark"}) {
+		t.Fatalf("expected repeated headers to keep every value, got %v", req.RepeatedHeaders)
+	}
+	if !reflect.DeepEqual(req.CookieOrder, []string{"sid", "theme"}) || req.Cookies["theme"] != "dark" {
+		t.Fatalf("unexpected cookies: %v %v", req.Cookies, req.CookieOrder)
+	}
+	if req.Body != "user=alice&pass=a%26b" || req.ContentType != "application/x-www-form-urlencoded" {
+		t.Fatalf("expected form body rebuilt from params, got %q (%s)", req.Body, req.ContentType)
+	}
+
+	resp := imported.Response
+	if resp.StatusCode != 200 || resp.Body != `{"ok":true}` || resp.Protocol != "HTTP/2" || resp.Duration.Milliseconds() != 120 {
+		t.Fatalf("unexpected response: %#v", resp)
+	}
+	if len(resp.Cookies) != 1 || resp.Cookies[0].Name != "token" {
+		t.Fatalf("unexpected response cookies: %v", resp.Cookies)
+	}
+	if resp.Headers["Vary"] != "Accept, Origin" || resp.Headers["set-cookie"] != "token=t1; Path=/\nlang=en" {
+		t.Fatalf("expected repeated response headers to be kept, got %v", resp.Headers)
+	}
+}
+
+func TestHarRequestParser_ParseEntryCookiesAndMultipart(t *testing.T) {
+	parser := NewHarRequestParser()
+
+	imported, err := parser.ParseEntry(testHar, 1)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if imported.Request.Cookies["cdn"] != "1" || imported.Request.Body != "" {
+		t.Fatalf("expected cookies from cookie list, got %#v", imported.Request)
+	}
+
+	imported, err = parser.ParseEntry(testHar, 2)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	body := imported.Request.Body
+	if !strings.HasPrefix(body, "--XyZ\r\n") || !strings.Contains(body, `name="title"`) ||
+		!strings.Contains(body, `filename="a.txt"`) || !strings.HasSuffix(body, "--XyZ--\r\n") {
+		t.Fatalf("expected multipart body with original boundary, got %q", body)
+	}
+
+	if _, err := parser.ParseEntry(testHar, 3); err == nil {
+		t.Fatalf("expected error for out-of-range index")
+	}
+}
+
+func TestUnifiedRequestParser_DetectsHar(t *testing.T) {
+	parser := NewUnifiedRequestParser()
+	if inputType := parser.DetectInputType(testHar); inputType != "har" {
+		t.Fatalf("expected har input type, got %q", inputType)
+	}
+	if parser.DetectInputType(`{"log": {}}`) == "har" {
+		t.Fatalf("expected JSON without entries not to be detected as HAR")
+	}
+
+	req, err := parser.Parse(testHar)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.URL != "https://api.example.com/v1/login?from=web" {
+		t.Fatalf("expected first entry to be parsed, got %q", req.URL)
+	}
+}
@@ -0,0 +1,645 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"RequestProbe/backend/models"
+)
+
+// psInvokeCommands 发送请求的 cmdlet 及其别名
+var psInvokeCommands = map[string]bool{
+	"invoke-webrequest": true, "invoke-restmethod": true, "iwr": true, "irm": true,
+}
+
+// psIgnoredSwitches 与请求内容无关的开关参数，解析时直接忽略
+var psIgnoredSwitches = map[string]bool{
+	"usebasicparsing": true, "passthru": true, "skiphttperrorcheck": true, "skipheadervalidation": true,
+}
+
+// psSwitches 影响请求但不支持的开关参数（不带参数值）
+var psSwitches = map[string]bool{
+	"disablekeepalive": true, "skipcertificatecheck": true, "allowunencryptedauthentication": true,
+	"noproxy": true, "usedefaultcredentials": true, "proxyusedefaultcredentials": true, "resume": true,
+	"allowinsecureredirect": true, "preserveauthorizationonredirect": true,
+}
+
+// psPseudoHeaders Chrome 导出时去掉冒号的 HTTP/2 伪头部，不是真实请求头
+var psPseudoHeaders = map[string]bool{"authority": true, "method": true, "path": true, "scheme": true}
+
+// psCharExpr 双引号字符串中的 $([char]N) 子表达式
+var psCharExpr = regexp.MustCompile(`(?i)^\$\(\[char\]\s*(0x[0-9a-f]+|\d+)\s*\)`)
+
+// PowerShellRequestParser PowerShell 请求命令解析器（Chrome "Copy as PowerShell"）
+//
+// 支持 Invoke-WebRequest/Invoke-RestMethod 的常用参数、@{...} 哈希表、变量引用，
+// 以及 WebRequestSession 上的 UserAgent 与 $session.Cookies.Add(...) Cookie。
+type PowerShellRequestParser struct{}
+
+// NewPowerShellRequestParser 创建PowerShell解析器
+func NewPowerShellRequestParser() *PowerShellRequestParser {
+	return &PowerShellRequestParser{}
+}
+
+// psTokenKind PowerShell 词法单元类型
+type psTokenKind int
+
+const (
+	psString    psTokenKind = iota // 字符串字面量（已解码）
+	psWord                         // 裸单词，如命令名、类型名
+	psVariable                     // $name 或 $name.Property
+	psParameter                    // -Name
+	psPunct                        // 标点：( ) { } [ ] = , @{ @(
+	psEnd                          // 语句结束：换行或分号
+)
+
+// psToken PowerShell 词法单元
+type psToken struct {
+	kind  psTokenKind
+	value string
+}
+
+// is 判断是否为指定类型与值的词法单元
+func (t psToken) is(kind psTokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// psValue 参数或变量的值：字符串、哈希表或（未赋值的）变量引用
+type psValue struct {
+	text     string
+	table    []psEntry
+	isTable  bool
+	variable string // 变量名（小写，不含$）
+}
+
+// psEntry 哈希表中的一项（保持书写顺序）
+type psEntry struct {
+	key   string
+	value string
+}
+
+// psSession WebRequestSession 上设置的 UserAgent 与 Cookie
+type psSession struct {
+	userAgent   string
+	cookies     map[string]string
+	cookieOrder []string
+}
+
+// psScript 逐条处理语句时的状态
+type psScript struct {
+	variables map[string]psValue
+	sessions  map[string]*psSession
+	request   *models.ParsedRequest
+	warnings  []string
+}
+
+// Parse 解析PowerShell命令
+func (p *PowerShellRequestParser) Parse(input string) (*models.ParsedRequest, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("PowerShell命令不能为空")
+	}
+
+	tokens, err := lexPowerShell(strings.ReplaceAll(input, "\r\n", "\n"))
+	if err != nil {
+		return nil, fmt.Errorf("解析PowerShell命令失败: %v", err)
+	}
+
+	script := &psScript{
+		variables: make(map[string]psValue),
+		sessions:  make(map[string]*psSession),
+	}
+	for _, statement := range splitPSStatements(tokens) {
+		if err := script.statement(statement); err != nil {
+			return nil, err
+		}
+	}
+	if script.request == nil {
+		return nil, fmt.Errorf("未找到 Invoke-WebRequest 或 Invoke-RestMethod 命令")
+	}
+	script.request.Warnings = script.warnings
+	return script.request, nil
+}
+
+// IsPowerShellCommand 检测是否为PowerShell请求命令（某一行以 Invoke-WebRequest 等命令开头）
+func (p *PowerShellRequestParser) IsPowerShellCommand(input string) bool {
+	for _, line := range strings.Split(input, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && psInvokeCommands[strings.ToLower(fields[0])] {
+			return true
+		}
+	}
+	return false
+}
+
+// lexPowerShell 把脚本拆分为词法单元（反引号加换行为续行，# 开始注释）
+func lexPowerShell(script string) ([]psToken, error) {
+	var tokens []psToken
+	runes := []rune(script)
+
+	for i := 0; i < len(runes); {
+		char := runes[i]
+		next := rune(0)
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		switch {
+		case char == '`' && next == '\n':
+			i += 2
+		case char == '\n' || char == ';':
+			tokens = append(tokens, psToken{psEnd, string(char)})
+			i++
+		case unicode.IsSpace(char):
+			i++
+		case char == '#':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case char == '"':
+			value, end, err := readPSDoubleQuoted(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, psToken{psString, value})
+			i = end + 1
+		case char == '\'':
+			value, end, err := readPSSingleQuoted(runes, i+1)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, psToken{psString, value})
+			i = end + 1
+		case char == '@' && (next == '{' || next == '('):
+			tokens = append(tokens, psToken{psPunct, string(runes[i : i+2])})
+			i += 2
+		case char == '$' && next == '(':
+			tokens = append(tokens, psToken{psPunct, "("})
+			i += 2
+		case char == '$':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || strings.ContainsRune("_:.?", runes[end])) {
+				end++
+			}
+			tokens = append(tokens, psToken{psVariable, string(runes[i:end])})
+			i = end
+		case char == '-' && unicode.IsLetter(next):
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) {
+				end++
+			}
+			tokens = append(tokens, psToken{psParameter, string(runes[i:end])})
+			// -Switch:$true 形式
+			if end < len(runes) && runes[end] == ':' {
+				end++
+			}
+			i = end
+		case strings.ContainsRune("(){}[]=,", char):
+			tokens = append(tokens, psToken{psPunct, string(char)})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune("(){}[]=,;\"'`", runes[end]) {
+				end++
+			}
+			tokens = append(tokens, psToken{psWord, string(runes[i:end])})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// readPSDoubleQuoted 读取双引号字符串，返回内容与结束引号的位置
+//
+// 支持反引号转义、"" 表示双引号以及 $([char]N) 子表达式；其他变量与子表达式按字面保留。
+func readPSDoubleQuoted(runes []rune, start int) (string, int, error) {
+	var out strings.Builder
+	for i := start; i < len(runes); i++ {
+		char := runes[i]
+		switch {
+		case char == '`' && i+1 < len(runes):
+			i++
+			out.WriteString(psEscape(runes[i]))
+		case char == '"':
+			if i+1 < len(runes) && runes[i+1] == '"' {
+				out.WriteRune('"')
+				i++
+				continue
+			}
+			return out.String(), i, nil
+		case char == '$':
+			rest := string(runes[i:min(i+32, len(runes))])
+			if match := psCharExpr.FindStringSubmatch(rest); match != nil {
+				if code, err := strconv.ParseInt(match[1], 0, 32); err == nil {
+					out.WriteRune(rune(code))
+					i += len([]rune(match[0])) - 1
+					continue
+				}
+			}
+			out.WriteRune(char)
+		default:
+			out.WriteRune(char)
+		}
+	}
+	return "", 0, fmt.Errorf("双引号未闭合")
+}
+
+// readPSSingleQuoted 读取单引号字符串（连续两个单引号表示一个单引号），返回内容与结束引号的位置
+func readPSSingleQuoted(runes []rune, start int) (string, int, error) {
+	var out strings.Builder
+	for i := start; i < len(runes); i++ {
+		if runes[i] != '\'' {
+			out.WriteRune(runes[i])
+			continue
+		}
+		if i+1 < len(runes) && runes[i+1] == '\'' {
+			out.WriteRune('\'')
+			i++
+			continue
+		}
+		return out.String(), i, nil
+	}
+	return "", 0, fmt.Errorf("单引号未闭合")
+}
+
+// psEscape 解码反引号转义字符
+func psEscape(char rune) string {
+	switch char {
+	case '0':
+		return "\x00"
+	case 'a':
+		return "\a"
+	case 'b':
+		return "\b"
+	case 'e':
+		return "\x1b"
+	case 'f':
+		return "\f"
+	case 'n':
+		return "\n"
+	case 'r':
+		return "\r"
+	case 't':
+		return "\t"
+	case 'v':
+		return "\v"
+	default:
+		return string(char)
+	}
+}
+
+// splitPSStatements 按顶层的换行和分号拆分语句（括号与哈希表内的换行保留为分隔符）
+func splitPSStatements(tokens []psToken) [][]psToken {
+	var statements [][]psToken
+	var current []psToken
+	depth := 0
+
+	for _, token := range tokens {
+		if token.kind == psPunct {
+			switch token.value {
+			case "(", "{", "[", "@{", "@(":
+				depth++
+			case ")", "}", "]":
+				depth--
+			}
+		}
+		if token.kind == psEnd && depth <= 0 {
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, token)
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements
+}
+
+// warn 记录解析警告
+func (s *psScript) warn(format string, args ...interface{}) {
+	s.warnings = append(s.warnings, fmt.Sprintf(format, args...))
+}
+
+// session 返回变量对应的会话（不存在时创建）
+func (s *psScript) session(name string) *psSession {
+	session := s.sessions[name]
+	if session == nil {
+		session = &psSession{cookies: make(map[string]string)}
+		s.sessions[name] = session
+	}
+	return session
+}
+
+// statement 处理一条语句
+func (s *psScript) statement(tokens []psToken) error {
+	first := tokens[0]
+	switch {
+	case first.kind == psVariable && len(tokens) > 1 && tokens[1].is(psPunct, "="):
+		return s.assign(first.value, tokens[2:])
+	case first.kind == psVariable && strings.HasSuffix(strings.ToLower(first.value), ".cookies.add"):
+		s.addCookie(first.value[:len(first.value)-len(".Cookies.Add")], tokens[1:])
+		return nil
+	case first.kind == psWord && psInvokeCommands[strings.ToLower(first.value)]:
+		if s.request != nil {
+			s.warn("命令包含多个请求，仅解析第一个")
+			return nil
+		}
+		return s.invoke(tokens[1:])
+	default:
+		s.warn("忽略无法识别的语句: %s", first.value)
+		return nil
+	}
+}
+
+// variableName 变量名统一为小写且不含$（PowerShell 变量名不区分大小写）
+func variableName(token string) string {
+	return strings.ToLower(strings.TrimPrefix(token, "$"))
+}
+
+// assign 处理赋值语句：会话创建、$session.UserAgent 以及普通变量
+func (s *psScript) assign(target string, tokens []psToken) error {
+	if len(tokens) == 0 {
+		return fmt.Errorf("赋值语句缺少值: %s", target)
+	}
+
+	if base, property, ok := strings.Cut(variableName(target), "."); ok {
+		value, _, err := s.parseValue(tokens, 0)
+		if err != nil {
+			return err
+		}
+		if property == "useragent" {
+			s.session(base).userAgent = value.text
+		} else {
+			s.warn("忽略会话属性 %s", target)
+		}
+		return nil
+	}
+
+	name := variableName(target)
+	if tokens[0].kind == psWord && strings.EqualFold(tokens[0].value, "New-Object") {
+		if len(tokens) > 1 && strings.HasSuffix(strings.ToLower(tokens[1].value), "webrequestsession") {
+			s.session(name)
+		} else {
+			s.warn("忽略无法识别的语句: %s", target)
+		}
+		return nil
+	}
+
+	value, _, err := s.parseValue(tokens, 0)
+	if err != nil {
+		return err
+	}
+	s.variables[name] = value
+	return nil
+}
+
+// addCookie 处理 $session.Cookies.Add((New-Object System.Net.Cookie("name", "value", "/", "domain")))
+func (s *psScript) addCookie(target string, tokens []psToken) {
+	var values []string
+	for _, token := range tokens {
+		if token.kind == psString {
+			values = append(values, token.value)
+		}
+	}
+	if len(values) < 2 || values[0] == "" {
+		s.warn("无法解析的Cookie语句: %s.Cookies.Add", target)
+		return
+	}
+
+	session := s.session(variableName(target))
+	if _, exists := session.cookies[values[0]]; !exists {
+		session.cookieOrder = append(session.cookieOrder, values[0])
+	}
+	session.cookies[values[0]] = values[1]
+}
+
+// parseValue 从 tokens[i] 开始读取一个值，返回值与下一个位置
+func (s *psScript) parseValue(tokens []psToken, i int) (psValue, int, error) {
+	token := tokens[i]
+	switch {
+	case token.kind == psString || token.kind == psWord:
+		return psValue{text: token.value}, i + 1, nil
+
+	case token.kind == psVariable:
+		name := variableName(token.value)
+		if value, exists := s.variables[name]; exists {
+			return value, i + 1, nil
+		}
+		return psValue{variable: name}, i + 1, nil
+
+	case token.is(psPunct, "@{"):
+		value := psValue{isTable: true}
+		j := i + 1
+		for j < len(tokens) && !tokens[j].is(psPunct, "}") {
+			if tokens[j].kind == psEnd {
+				j++
+				continue
+			}
+			key := tokens[j].value
+			if j+2 >= len(tokens) || !tokens[j+1].is(psPunct, "=") {
+				return psValue{}, 0, fmt.Errorf("哈希表格式无效: %s", key)
+			}
+			entry, next, err := s.parseValue(tokens, j+2)
+			if err != nil {
+				return psValue{}, 0, err
+			}
+			value.table = append(value.table, psEntry{key: key, value: entry.text})
+			j = next
+		}
+		if j >= len(tokens) {
+			return psValue{}, 0, fmt.Errorf("哈希表缺少右括号")
+		}
+		return value, j + 1, nil
+
+	case token.is(psPunct, "(") || token.is(psPunct, "@("):
+		// 表达式只取其中的字符串，如 ([System.Text.Encoding]::UTF8.GetBytes("..."))
+		depth, end := 0, i
+		var texts []string
+		for ; end < len(tokens); end++ {
+			switch {
+			case tokens[end].is(psPunct, "(") || tokens[end].is(psPunct, "@("):
+				depth++
+			case tokens[end].is(psPunct, ")"):
+				depth--
+			case tokens[end].kind == psString:
+				texts = append(texts, tokens[end].value)
+			}
+			if depth == 0 {
+				break
+			}
+		}
+		if len(texts) != 1 {
+			s.warn("无法解析的表达式，已忽略")
+			return psValue{}, end + 1, nil
+		}
+		return psValue{text: texts[0]}, end + 1, nil
+
+	default:
+		return psValue{text: token.value}, i + 1, nil
+	}
+}
+
+// invoke 处理 Invoke-WebRequest/Invoke-RestMethod 的参数并生成请求
+func (s *psScript) invoke(tokens []psToken) error {
+	var uri, method, body, contentType, userAgent string
+	var headers []psEntry
+	var session *psSession
+	var bodyTable *psValue
+
+	for i := 0; i < len(tokens); {
+		token := tokens[i]
+		if token.kind != psParameter {
+			// 位置参数为 -Uri
+			value, next, err := s.parseValue(tokens, i)
+			if err != nil {
+				return err
+			}
+			if uri == "" {
+				uri = value.text
+			} else {
+				s.warn("忽略多余的参数: %s", token.value)
+			}
+			i = next
+			continue
+		}
+
+		name := strings.ToLower(strings.TrimPrefix(token.value, "-"))
+		if psIgnoredSwitches[name] || psSwitches[name] {
+			if psSwitches[name] {
+				s.warn("不支持的参数 %s，已忽略", token.value)
+			}
+			i++
+			// -Switch:$true 的值一并跳过
+			if i < len(tokens) && tokens[i].kind == psVariable && (tokens[i].value == "$true" || tokens[i].value == "$false") {
+				i++
+			}
+			continue
+		}
+		if i+1 >= len(tokens) || tokens[i+1].kind == psParameter {
+			return fmt.Errorf("参数 %s 缺少值", token.value)
+		}
+		value, next, err := s.parseValue(tokens, i+1)
+		if err != nil {
+			return err
+		}
+		i = next
+
+		switch name {
+		case "uri":
+			uri = value.text
+		case "method", "custommethod":
+			method = strings.ToUpper(value.text)
+		case "headers":
+			if !value.isTable {
+				s.warn("无法解析的请求头参数，已忽略")
+				continue
+			}
+			headers = value.table
+		case "body":
+			if value.isTable {
+				bodyTable = &value
+			} else {
+				body = value.text
+			}
+		case "contenttype":
+			contentType = value.text
+		case "useragent":
+			userAgent = value.text
+		case "websession":
+			if session = s.sessions[value.variable]; session == nil {
+				s.warn("未找到会话变量 $%s", value.variable)
+			}
+		case "sessionvariable":
+			// 仅用于保存响应会话，与请求无关
+		default:
+			s.warn("不支持的参数 %s，已忽略", token.value)
+		}
+	}
+
+	if uri == "" {
+		return fmt.Errorf("未找到请求URL")
+	}
+	if method == "" {
+		method = "GET"
+	}
+
+	req := &models.ParsedRequest{
+		Method:  method,
+		URL:     uri,
+		Headers: make(map[string]string),
+		Cookies: make(map[string]string),
+	}
+	setHeader := func(key, value string) {
+		if _, exists := req.Headers[key]; !exists {
+			req.HeaderOrder = append(req.HeaderOrder, key)
+		}
+		req.Headers[key] = value
+	}
+	addCookies := func(cookies map[string]string, order []string) {
+		for _, name := range order {
+			if _, exists := req.Cookies[name]; !exists {
+				req.CookieOrder = append(req.CookieOrder, name)
+			}
+			req.Cookies[name] = cookies[name]
+		}
+	}
+
+	if userAgent == "" && session != nil {
+		userAgent = session.userAgent
+	}
+	if userAgent != "" {
+		setHeader("User-Agent", userAgent)
+	}
+	if session != nil {
+		addCookies(session.cookies, session.cookieOrder)
+	}
+	for _, header := range headers {
+		key := strings.TrimPrefix(header.key, ":")
+		if psPseudoHeaders[strings.ToLower(key)] {
+			continue
+		}
+		setHeader(key, header.value)
+		if strings.EqualFold(key, "Cookie") {
+			addCookies(NewRawRequestParser().parseCookieHeader(header.value))
+		}
+	}
+	if contentType != "" {
+		if key, exists := headerKey(req.Headers, "Content-Type"); exists {
+			req.Headers[key] = contentType
+		} else {
+			setHeader("Content-Type", contentType)
+		}
+	}
+
+	if bodyTable != nil {
+		// 与 PowerShell 一致：哈希表请求体按表单编码，GET 请求追加到查询串
+		pairs := make([]string, 0, len(bodyTable.table))
+		for _, entry := range bodyTable.table {
+			pairs = append(pairs, url.QueryEscape(entry.key)+"="+url.QueryEscape(entry.value))
+		}
+		if method == "GET" {
+			req.URL = appendURLQuery(req.URL, strings.Join(pairs, "&"))
+		} else {
+			body = strings.Join(pairs, "&")
+			if _, exists := headerKey(req.Headers, "Content-Type"); !exists {
+				setHeader("Content-Type", "application/x-www-form-urlencoded")
+			}
+		}
+	}
+	req.Body = body
+	req.ContentType = headerValue(req.Headers, "Content-Type")
+
+	queryParams, err := NewRawRequestParser().parseQueryParams(req.URL)
+	if err != nil {
+		return fmt.Errorf("解析URL参数失败: %v", err)
+	}
+	req.QueryParams = queryParams
+	s.request = req
+	return nil
+}
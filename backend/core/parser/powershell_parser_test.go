@@ -0,0 +1,89 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPowerShellRequestParser_ChromeExport(t *testing.T) {
+	script := strings.Join([]string{
+		"$session = New-Object Microsoft.PowerShell.Commands.WebRequestSession",
+		"$session.UserAgent = \"Mozilla/5.0 (Windows NT 10.0)\"",
+		"$session.Cookies.Add((New-Object System.Net.Cookie(\"sid\", \"abc\", \"/\", \"example.com\")))",
+		"$session.Cookies.Add((New-Object System.Net.Cookie(\"theme\", \"dark\", \"/\", \".example.com\")))",
+		"Invoke-WebRequest -UseBasicParsing -Uri \"https://example.com/api?x=1\" `",
+		"-Method \"POST\" `",
+		"-WebSession $session `",
+		"-Headers @{",
+		"\"authority\"=\"example.com\"",
+		"  \"method\"=\"POST\"",
+		"  \"path\"=\"/api?x=1\"",
+		"  \"scheme\"=\"https\"",
+		"  \"accept\"=\"application/json\"",
+		"  \"x-price\"=\"`$5 `\"deal`\"\"",
+		"} `",
+		"-ContentType \"application/json\" `",
+		"-Body ([System.Text.Encoding]::UTF8.GetBytes(\"{`\"name`\":`\"caf$([char]233)`\"}\"))",
+	}, "\r\n")
+
+	parser := NewUnifiedRequestParser()
+	if inputType := parser.DetectInputType(script); inputType != "powershell" {
+		t.Fatalf("expected powershell input type, got %q", inputType)
+	}
+
+	req, err := parser.Parse(script)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "POST" || req.URL != "https://example.com/api?x=1" || req.QueryParams["x"] != "1" {
+		t.Fatalf("unexpected request line: %s %s", req.Method, req.URL)
+	}
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "User-Agent,accept,x-price,Content-Type" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if req.Headers["x-price"] != `$5 "deal"` || req.Headers["User-Agent"] != "Mozilla/5.0 (Windows NT 10.0)" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	if got := strings.Join(req.OrderedCookieNames(), ","); got != "sid,theme" || req.Cookies["sid"] != "abc" {
+		t.Fatalf("unexpected cookies: %v", req.Cookies)
+	}
+	if req.Body != `{"name":"café"}` || req.ContentType != "application/json" {
+		t.Fatalf("unexpected body: %q (%s)", req.Body, req.ContentType)
+	}
+	if len(req.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", req.Warnings)
+	}
+}
+
+func TestPowerShellRequestParser_VariablesAndHashtableBody(t *testing.T) {
+	script := strings.Join([]string{
+		"# 手写脚本",
+		"$headers = @{ Authorization = 'Bearer t''k'; 'X-Id' = 7 }",
+		"Write-Output 'start'",
+		"irm https://example.com/search -Headers $headers -Body @{ q = 'a b'; page = 2 } -TimeoutSec 5 -SkipCertificateCheck",
+	}, "\n")
+
+	req, err := NewPowerShellRequestParser().Parse(script)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "GET" || req.URL != "https://example.com/search?q=a+b&page=2" || req.Body != "" {
+		t.Fatalf("expected hashtable body in query, got %s %s %q", req.Method, req.URL, req.Body)
+	}
+	if req.Headers["Authorization"] != "Bearer t'k" || req.Headers["X-Id"] != "7" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	want := []string{"Write-Output", "-TimeoutSec", "-SkipCertificateCheck"}
+	if len(req.Warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %v", len(want), req.Warnings)
+	}
+	for i, fragment := range want {
+		if !strings.Contains(req.Warnings[i], fragment) {
+			t.Fatalf("expected warning %d to mention %s, got %q", i, fragment, req.Warnings[i])
+		}
+	}
+
+	if _, err := NewPowerShellRequestParser().Parse(`$x = "a"`); err == nil {
+		t.Fatalf("expected error without request command")
+	}
+}
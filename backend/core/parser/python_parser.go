@@ -0,0 +1,1248 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"RequestProbe/backend/models"
+)
+
+// pythonClientModules 可以直接发起请求的模块
+var pythonClientModules = map[string]bool{"requests": true, "httpx": true}
+
+// pythonRequestMethods 以HTTP方法命名的请求函数
+var pythonRequestMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "delete": true, "patch": true, "head": true, "options": true,
+}
+
+// pythonSessionConstructors 创建会话的调用
+var pythonSessionConstructors = map[string]bool{
+	"requests.Session": true, "requests.session": true, "httpx.Client": true, "httpx.AsyncClient": true,
+}
+
+// pythonIgnoredArguments 不改变请求内容的关键字参数
+var pythonIgnoredArguments = map[string]bool{
+	"timeout": true, "verify": true, "allow_redirects": true, "follow_redirects": true, "stream": true,
+	"http2": true,
+}
+
+// pythonCodePattern 导入 requests/httpx 或调用其请求函数
+var pythonCodePattern = regexp.MustCompile(`(?m)^\s*(import\s+(requests|httpx)\b|from\s+(requests|httpx)\s+import\b)|\b(requests|httpx)\.(get|post|put|delete|patch|head|options|request)\s*\(`)
+
+// PythonRequestParser Python requests/httpx 代码解析器（GeneratePythonCode 的逆过程）
+//
+// 不执行代码，只求值字面量：字符串（含前缀与三引号）、数字、True/False/None、dict/list/tuple、
+// 变量引用与字符串拼接；f-string 中的表达式原样保留为 {expr} 占位符。
+type PythonRequestParser struct{}
+
+// NewPythonRequestParser 创建Python解析器
+func NewPythonRequestParser() *PythonRequestParser {
+	return &PythonRequestParser{}
+}
+
+// pyTokenKind Python 词法单元类型
+type pyTokenKind int
+
+const (
+	pyName    pyTokenKind = iota // 标识符或关键字
+	pyString                     // 字符串字面量（已解码）
+	pyNumber                     // 数字字面量
+	pyOp                         // 运算符与标点
+	pyNewline                    // 逻辑行结束
+)
+
+// pyToken Python 词法单元，start/end 为在源码中的位置
+type pyToken struct {
+	kind  pyTokenKind
+	value string
+	start int
+	end   int
+}
+
+// is 判断是否为指定类型与值的词法单元
+func (t pyToken) is(kind pyTokenKind, value string) bool {
+	return t.kind == kind && t.value == value
+}
+
+// pyDict 保持键顺序的 dict 字面量
+type pyDict struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// pyNumberValue 数字字面量（保留原始写法）
+type pyNumberValue string
+
+// pyBinding 变量绑定：求值结果（求值失败时记录错误）与赋值右侧的词法单元
+type pyBinding struct {
+	value  interface{}
+	err    error
+	tokens []pyToken
+}
+
+// pySession requests.Session/httpx.Client 上的默认请求头、Cookie 与 base_url
+type pySession struct {
+	headers *pyDict
+	cookies *pyDict
+	baseURL string
+}
+
+// pyCall 函数调用：点分名称、位置参数与关键字参数（参数为未求值的词法单元）
+type pyCall struct {
+	callee string
+	args   [][]pyToken
+	kwargs []pyKeyword
+}
+
+// pyKeyword 关键字参数
+type pyKeyword struct {
+	name   string
+	tokens []pyToken
+}
+
+// pyScript 逐条处理语句时的状态
+type pyScript struct {
+	source   []rune
+	vars     map[string]pyBinding
+	sessions map[string]*pySession
+	request  *models.ParsedRequest
+	warnings []string
+}
+
+// Parse 解析Python代码
+func (p *PythonRequestParser) Parse(input string) (*models.ParsedRequest, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, fmt.Errorf("Python代码不能为空")
+	}
+
+	source := []rune(strings.ReplaceAll(input, "\r\n", "\n"))
+	lexer := &pyLexer{source: source}
+	tokens, err := lexer.lex()
+	if err != nil {
+		return nil, fmt.Errorf("解析Python代码失败: %v", err)
+	}
+
+	script := &pyScript{
+		source:   source,
+		vars:     make(map[string]pyBinding),
+		sessions: make(map[string]*pySession),
+	}
+	for _, placeholder := range lexer.placeholders {
+		script.warn("f-string 表达式 %s 保留为占位符", placeholder)
+	}
+	for _, statement := range splitPyStatements(tokens) {
+		if err := script.statement(statement); err != nil {
+			return nil, fmt.Errorf("解析Python代码失败: %v", err)
+		}
+	}
+	if script.request == nil {
+		return nil, fmt.Errorf("未找到 requests 或 httpx 请求调用")
+	}
+	script.request.Warnings = script.warnings
+	return script.request, nil
+}
+
+// IsPythonCode 检测是否为使用 requests/httpx 的Python代码
+func (p *PythonRequestParser) IsPythonCode(input string) bool {
+	return pythonCodePattern.MatchString(input)
+}
+
+// pyLexer Python 词法分析器（括号内的换行不结束逻辑行）
+type pyLexer struct {
+	source       []rune
+	pos          int
+	depth        int
+	tokens       []pyToken
+	placeholders []string
+}
+
+// errorf 生成带行号的错误
+func (l *pyLexer) errorf(pos int, format string, args ...interface{}) error {
+	line := 1 + strings.Count(string(l.source[:min(pos, len(l.source))]), "\n")
+	return fmt.Errorf("第%d行: %s", line, fmt.Sprintf(format, args...))
+}
+
+// emit 追加词法单元
+func (l *pyLexer) emit(kind pyTokenKind, value string, start int) {
+	l.tokens = append(l.tokens, pyToken{kind: kind, value: value, start: start, end: l.pos})
+}
+
+// lex 把源码拆分为词法单元
+func (l *pyLexer) lex() ([]pyToken, error) {
+	src := l.source
+	for l.pos < len(src) {
+		char := src[l.pos]
+		next := rune(0)
+		if l.pos+1 < len(src) {
+			next = src[l.pos+1]
+		}
+		start := l.pos
+
+		switch {
+		case char == '#':
+			for l.pos < len(src) && src[l.pos] != '\n' {
+				l.pos++
+			}
+		case char == '\\' && next == '\n':
+			l.pos += 2
+		case char == '\n':
+			l.pos++
+			if l.depth == 0 {
+				l.emit(pyNewline, "\n", start)
+			}
+		case char == ';' && l.depth == 0:
+			l.pos++
+			l.emit(pyNewline, ";", start)
+		case unicode.IsSpace(char):
+			l.pos++
+		case unicode.IsLetter(char) || char == '_':
+			for l.pos < len(src) && (unicode.IsLetter(src[l.pos]) || unicode.IsDigit(src[l.pos]) || src[l.pos] == '_') {
+				l.pos++
+			}
+			word := string(src[start:l.pos])
+			if l.pos < len(src) && (src[l.pos] == '"' || src[l.pos] == '\'') && isPyStringPrefix(word) {
+				if err := l.readString(start, strings.ToLower(word)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			l.emit(pyName, word, start)
+		case char == '"' || char == '\'':
+			if err := l.readString(start, ""); err != nil {
+				return nil, err
+			}
+		case unicode.IsDigit(char) || char == '.' && unicode.IsDigit(next):
+			for l.pos < len(src) {
+				c := src[l.pos]
+				exponentSign := (c == '+' || c == '-') && (src[l.pos-1] == 'e' || src[l.pos-1] == 'E') &&
+					!strings.HasPrefix(strings.ToLower(string(src[start:l.pos])), "0x")
+				if !(unicode.IsDigit(c) || unicode.IsLetter(c) || c == '_' || c == '.' || exponentSign) {
+					break
+				}
+				l.pos++
+			}
+			l.emit(pyNumber, string(src[start:l.pos]), start)
+		case char == '*' && next == '*':
+			l.pos += 2
+			l.emit(pyOp, "**", start)
+		case char == '=' && next == '=':
+			l.pos += 2
+			l.emit(pyOp, "==", start)
+		case strings.ContainsRune("([{", char):
+			l.depth++
+			l.pos++
+			l.emit(pyOp, string(char), start)
+		case strings.ContainsRune(")]}", char):
+			l.depth--
+			l.pos++
+			l.emit(pyOp, string(char), start)
+		default:
+			// 其余运算符只出现在被忽略的语句中，逐字符保留
+			l.pos++
+			l.emit(pyOp, string(char), start)
+		}
+	}
+	return l.tokens, nil
+}
+
+// isPyStringPrefix 是否为字符串前缀（r、b、u、f 及其组合）
+func isPyStringPrefix(word string) bool {
+	switch strings.ToLower(word) {
+	case "r", "u", "b", "f", "br", "rb", "fr", "rf":
+		return true
+	default:
+		return false
+	}
+}
+
+// readString 读取字符串字面量（prefix 为小写前缀）
+func (l *pyLexer) readString(start int, prefix string) error {
+	src := l.source
+	raw := strings.Contains(prefix, "r")
+	format := strings.Contains(prefix, "f")
+	quote := src[l.pos]
+	triple := l.pos+2 < len(src) && src[l.pos+1] == quote && src[l.pos+2] == quote
+	if triple {
+		l.pos += 3
+	} else {
+		l.pos++
+	}
+
+	var out strings.Builder
+	for {
+		if l.pos >= len(src) {
+			return l.errorf(start, "字符串未闭合")
+		}
+		char := src[l.pos]
+		next := rune(0)
+		if l.pos+1 < len(src) {
+			next = src[l.pos+1]
+		}
+
+		switch {
+		case char == quote && (!triple || l.pos+2 < len(src) && next == quote && src[l.pos+2] == quote):
+			if triple {
+				l.pos += 3
+			} else {
+				l.pos++
+			}
+			l.emit(pyString, out.String(), start)
+			return nil
+		case char == '\n' && !triple:
+			return l.errorf(l.pos, "字符串中不能直接换行")
+		case char == '\\' && raw:
+			// 原始字符串保留反斜杠，但反斜杠后的引号不结束字符串
+			out.WriteRune(char)
+			if next != 0 {
+				out.WriteRune(next)
+				l.pos++
+			}
+			l.pos++
+		case char == '\\':
+			l.pos = readPyEscape(src, l.pos+1, &out)
+		case format && char == '{' && next == '{':
+			out.WriteRune('{')
+			l.pos += 2
+		case format && char == '}' && next == '}':
+			out.WriteRune('}')
+			l.pos += 2
+		case format && char == '{':
+			end, depth := l.pos, 0
+			for ; end < len(src); end++ {
+				if src[end] == '{' {
+					depth++
+				} else if src[end] == '}' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			if end >= len(src) {
+				return l.errorf(l.pos, "f-string 表达式未闭合")
+			}
+			placeholder := string(src[l.pos : end+1])
+			out.WriteString(placeholder)
+			l.placeholders = append(l.placeholders, placeholder)
+			l.pos = end + 1
+		default:
+			out.WriteRune(char)
+			l.pos++
+		}
+	}
+}
+
+// readPyEscape 解码反斜杠之后的转义序列，返回之后的位置
+func readPyEscape(src []rune, pos int, out *strings.Builder) int {
+	if pos >= len(src) {
+		out.WriteRune('\\')
+		return pos
+	}
+	char := src[pos]
+	pos++
+	switch char {
+	case '\n':
+		// 续行
+	case '\\', '\'', '"':
+		out.WriteRune(char)
+	case 'a':
+		out.WriteByte('\a')
+	case 'b':
+		out.WriteByte('\b')
+	case 'f':
+		out.WriteByte('\f')
+	case 'n':
+		out.WriteByte('\n')
+	case 'r':
+		out.WriteByte('\r')
+	case 't':
+		out.WriteByte('\t')
+	case 'v':
+		out.WriteByte('\v')
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		value, n := readEscapeDigits(src, pos-1, 8, 3)
+		out.WriteRune(rune(value))
+		pos += n - 1
+	case 'x', 'u', 'U':
+		digits := map[rune]int{'x': 2, 'u': 4, 'U': 8}[char]
+		if value, n := readEscapeDigits(src, pos, 16, digits); n == digits {
+			out.WriteRune(rune(value))
+			pos += n
+		} else {
+			out.WriteRune('\\')
+			out.WriteRune(char)
+		}
+	default:
+		// 未知转义（含 \N{...}）保留原文
+		out.WriteRune('\\')
+		out.WriteRune(char)
+	}
+	return pos
+}
+
+// splitPyStatements 按逻辑行拆分语句
+func splitPyStatements(tokens []pyToken) [][]pyToken {
+	var statements [][]pyToken
+	var current []pyToken
+	for _, token := range tokens {
+		if token.kind == pyNewline {
+			if len(current) > 0 {
+				statements = append(statements, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, token)
+	}
+	if len(current) > 0 {
+		statements = append(statements, current)
+	}
+	return statements
+}
+
+// warn 记录解析警告
+func (s *pyScript) warn(format string, args ...interface{}) {
+	s.warnings = append(s.warnings, fmt.Sprintf(format, args...))
+}
+
+// warnOnce 记录解析警告（相同内容只记录一次）
+func (s *pyScript) warnOnce(format string, args ...interface{}) {
+	warning := fmt.Sprintf(format, args...)
+	for _, existing := range s.warnings {
+		if existing == warning {
+			return
+		}
+	}
+	s.warnings = append(s.warnings, warning)
+}
+
+// text 返回词法单元对应的源码原文
+func (s *pyScript) text(tokens []pyToken) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return string(s.source[tokens[0].start:tokens[len(tokens)-1].end])
+}
+
+// statement 处理一条语句
+func (s *pyScript) statement(tokens []pyToken) error {
+	first := tokens[0]
+	switch {
+	case first.is(pyName, "import") || first.is(pyName, "from"):
+		return nil
+	case first.is(pyName, "print") && len(tokens) > 1 && tokens[1].is(pyOp, "("):
+		return nil
+	case first.is(pyName, "with") || first.is(pyName, "async") && len(tokens) > 1 && tokens[1].is(pyName, "with"):
+		return s.with(tokens)
+	case first.kind == pyName && len(tokens) > 2 && tokens[1].is(pyOp, "="):
+		return s.assign(first.value, tokens[2:])
+	}
+
+	call, ok := parsePyCall(stripAwait(tokens))
+	if !ok {
+		s.warn("忽略无法识别的语句: %s", s.text(tokens))
+		return nil
+	}
+	if handled, err := s.invoke(call); handled || err != nil {
+		return err
+	}
+	if handled, err := s.sessionCall(call); handled || err != nil {
+		return err
+	}
+	s.warn("忽略无法识别的语句: %s", s.text(tokens))
+	return nil
+}
+
+// stripAwait 去掉开头的 await
+func stripAwait(tokens []pyToken) []pyToken {
+	if len(tokens) > 1 && tokens[0].is(pyName, "await") {
+		return tokens[1:]
+	}
+	return tokens
+}
+
+// with 处理 with httpx.Client(...) as client:
+func (s *pyScript) with(tokens []pyToken) error {
+	if tokens[0].is(pyName, "async") {
+		tokens = tokens[1:]
+	}
+	for i := len(tokens) - 1; i > 0; i-- {
+		if !tokens[i].is(pyName, "as") || i+1 >= len(tokens) || tokens[i+1].kind != pyName {
+			continue
+		}
+		if call, ok := parsePyCall(tokens[1:i]); ok && pythonSessionConstructors[call.callee] {
+			return s.newSession(tokens[i+1].value, call)
+		}
+	}
+	s.warn("忽略无法识别的语句: %s", s.text(tokens))
+	return nil
+}
+
+// assign 处理赋值：请求调用、会话创建或普通变量
+func (s *pyScript) assign(name string, tokens []pyToken) error {
+	tokens = stripAwait(tokens)
+	if call, ok := parsePyCall(tokens); ok {
+		if pythonSessionConstructors[call.callee] {
+			return s.newSession(name, call)
+		}
+		if handled, err := s.invoke(call); handled || err != nil {
+			return err
+		}
+	}
+
+	value, err := s.eval(tokens)
+	s.vars[name] = pyBinding{value: value, err: err, tokens: tokens}
+	return nil
+}
+
+// newSession 创建会话（读取 headers、cookies 与 base_url 参数）
+func (s *pyScript) newSession(name string, call *pyCall) error {
+	session := &pySession{}
+	for _, keyword := range call.kwargs {
+		switch keyword.name {
+		case "headers", "cookies":
+			value, err := s.eval(keyword.tokens)
+			if err != nil {
+				return err
+			}
+			dict, ok := value.(*pyDict)
+			if !ok {
+				return fmt.Errorf("%s 必须是 dict", keyword.name)
+			}
+			if keyword.name == "headers" {
+				session.headers = dict
+			} else {
+				session.cookies = dict
+			}
+		case "base_url":
+			value, err := s.eval(keyword.tokens)
+			if err != nil {
+				return err
+			}
+			session.baseURL, _ = value.(string)
+		default:
+			if !pythonIgnoredArguments[keyword.name] {
+				s.warn("不支持的参数 %s，已忽略", keyword.name)
+			}
+		}
+	}
+	s.sessions[name] = session
+	return nil
+}
+
+// sessionCall 处理 session.headers.update({...}) 与 session.cookies.update/set(...)
+func (s *pyScript) sessionCall(call *pyCall) (bool, error) {
+	parts := strings.Split(call.callee, ".")
+	if len(parts) != 3 || s.sessions[parts[0]] == nil || parts[1] != "headers" && parts[1] != "cookies" {
+		return false, nil
+	}
+	session := s.sessions[parts[0]]
+	target := &session.headers
+	if parts[1] == "cookies" {
+		target = &session.cookies
+	}
+	if *target == nil {
+		*target = &pyDict{values: make(map[string]interface{})}
+	}
+
+	switch {
+	case parts[2] == "update" && len(call.args) == 1:
+		value, err := s.eval(call.args[0])
+		if err != nil {
+			return true, err
+		}
+		dict, ok := value.(*pyDict)
+		if !ok {
+			return true, fmt.Errorf("%s.%s.update 的参数必须是 dict", parts[0], parts[1])
+		}
+		for _, key := range dict.keys {
+			(*target).set(key, dict.values[key])
+		}
+		return true, nil
+	case parts[2] == "set" && parts[1] == "cookies" && len(call.args) >= 2:
+		name, err := s.eval(call.args[0])
+		if err != nil {
+			return true, err
+		}
+		value, err := s.eval(call.args[1])
+		if err != nil {
+			return true, err
+		}
+		key, _ := pyText(name)
+		(*target).set(key, value)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// set 设置键值（新键追加在末尾）
+func (d *pyDict) set(key string, value interface{}) {
+	if _, exists := d.values[key]; !exists {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+}
+
+// parsePyCall 把 name.attr(...) 形式的词法单元解析为调用（调用之后的 .json() 等被忽略）
+func parsePyCall(tokens []pyToken) (*pyCall, bool) {
+	if len(tokens) < 3 || tokens[0].kind != pyName {
+		return nil, false
+	}
+	callee := tokens[0].value
+	i := 1
+	for i+1 < len(tokens) && tokens[i].is(pyOp, ".") && tokens[i+1].kind == pyName {
+		callee += "." + tokens[i+1].value
+		i += 2
+	}
+	if i >= len(tokens) || !tokens[i].is(pyOp, "(") {
+		return nil, false
+	}
+
+	call := &pyCall{callee: callee}
+	depth, argStart := 0, i+1
+	for j := i; j < len(tokens); j++ {
+		token := tokens[j]
+		if token.kind != pyOp {
+			continue
+		}
+		switch token.value {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth == 1 && token.value == "," || depth == 0 {
+			if arg := tokens[argStart:j]; len(arg) > 0 {
+				if len(arg) > 2 && arg[0].kind == pyName && arg[1].is(pyOp, "=") {
+					call.kwargs = append(call.kwargs, pyKeyword{name: arg[0].value, tokens: arg[2:]})
+				} else {
+					call.args = append(call.args, arg)
+				}
+			}
+			argStart = j + 1
+		}
+		if depth == 0 {
+			return call, true
+		}
+	}
+	return nil, false
+}
+
+// requestTarget 判断调用是否为请求：返回请求方法（request() 为空字符串）与所用会话
+func (s *pyScript) requestTarget(callee string) (string, *pySession, bool) {
+	dot := strings.LastIndex(callee, ".")
+	if dot < 0 {
+		return "", nil, false
+	}
+	object, function := callee[:dot], callee[dot+1:]
+
+	var session *pySession
+	if !pythonClientModules[object] {
+		if session = s.sessions[object]; session == nil {
+			return "", nil, false
+		}
+	}
+	switch {
+	case pythonRequestMethods[function]:
+		return function, session, true
+	case function == "request":
+		return "", session, true
+	default:
+		return "", nil, false
+	}
+}
+
+// invoke 处理请求调用
+func (s *pyScript) invoke(call *pyCall) (bool, error) {
+	method, session, ok := s.requestTarget(call.callee)
+	if !ok {
+		return false, nil
+	}
+	if s.request != nil {
+		s.warn("代码包含多个请求，仅解析第一个")
+		return true, nil
+	}
+
+	// 位置参数：request(method, url, ...)，get(url, params)，post/put/patch(url, data, json)
+	positional := []string{"url"}
+	switch method {
+	case "":
+		positional = []string{"method", "url"}
+	case "get":
+		positional = append(positional, "params")
+	case "post", "put", "patch":
+		positional = append(positional, "data", "json")
+	}
+	arguments := make(map[string][]pyToken)
+	var order []string
+	for i, arg := range call.args {
+		if i >= len(positional) {
+			s.warn("忽略多余的位置参数: %s", s.text(arg))
+			continue
+		}
+		arguments[positional[i]] = arg
+		order = append(order, positional[i])
+	}
+	for _, keyword := range call.kwargs {
+		if _, exists := arguments[keyword.name]; !exists {
+			order = append(order, keyword.name)
+		}
+		arguments[keyword.name] = keyword.tokens
+	}
+
+	if method == "" {
+		value, err := s.evalArgument(arguments, "method")
+		if err != nil {
+			return true, err
+		}
+		if method, _ = value.(string); method == "" {
+			return true, fmt.Errorf("request() 缺少请求方法")
+		}
+	}
+	value, err := s.evalArgument(arguments, "url")
+	if err != nil {
+		return true, err
+	}
+	requestURL, _ := value.(string)
+	if requestURL == "" {
+		return true, fmt.Errorf("未找到请求URL")
+	}
+	if session != nil && session.baseURL != "" && !strings.Contains(requestURL, "://") {
+		requestURL = strings.TrimSuffix(session.baseURL, "/") + "/" + strings.TrimPrefix(requestURL, "/")
+	}
+
+	req := &models.ParsedRequest{
+		Method:  strings.ToUpper(method),
+		Headers: make(map[string]string),
+		Cookies: make(map[string]string),
+	}
+	if session != nil {
+		s.applyHeaders(req, session.headers)
+		s.applyCookies(req, session.cookies)
+	}
+
+	// json= 与 dict 形式的 data= 由 requests 自动补充 Content-Type
+	defaultContentType := ""
+	for _, name := range order {
+		if name == "method" || name == "url" {
+			continue
+		}
+		if pythonIgnoredArguments[name] {
+			continue
+		}
+		if name == "json" {
+			body, err := s.jsonBody(arguments[name])
+			if err != nil {
+				return true, err
+			}
+			req.Body = body
+			defaultContentType = "application/json"
+			continue
+		}
+
+		value, err := s.evalArgument(arguments, name)
+		if err != nil {
+			return true, err
+		}
+		switch name {
+		case "headers":
+			dict, ok := value.(*pyDict)
+			if !ok && value != nil {
+				return true, fmt.Errorf("headers 必须是 dict")
+			}
+			s.applyHeaders(req, dict)
+		case "cookies":
+			dict, ok := value.(*pyDict)
+			if !ok && value != nil {
+				return true, fmt.Errorf("cookies 必须是 dict")
+			}
+			s.applyCookies(req, dict)
+		case "params":
+			query, err := pyFormEncode(value)
+			if err != nil {
+				return true, fmt.Errorf("params %v", err)
+			}
+			requestURL = appendURLQuery(requestURL, query)
+		case "data", "content":
+			if text, ok := value.(string); ok {
+				req.Body = text
+				continue
+			}
+			body, err := pyFormEncode(value)
+			if err != nil {
+				return true, fmt.Errorf("%s %v", name, err)
+			}
+			req.Body = body
+			defaultContentType = "application/x-www-form-urlencoded"
+		case "auth":
+			pair, ok := value.([]interface{})
+			if !ok || len(pair) != 2 {
+				s.warn("仅支持 (用户名, 密码) 形式的 auth 参数，已忽略")
+				continue
+			}
+			user, _ := pyText(pair[0])
+			password, _ := pyText(pair[1])
+			s.setHeader(req, "Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(user+":"+password)))
+		default:
+			s.warn("不支持的参数 %s，已忽略", name)
+		}
+	}
+
+	if _, exists := headerKey(req.Headers, "Content-Type"); !exists && defaultContentType != "" && req.Body != "" {
+		s.setHeader(req, "Content-Type", defaultContentType)
+	}
+	req.URL = requestURL
+	req.ContentType = headerValue(req.Headers, "Content-Type")
+	queryParams, err := NewRawRequestParser().parseQueryParams(requestURL)
+	if err != nil {
+		return true, fmt.Errorf("解析URL参数失败: %v", err)
+	}
+	req.QueryParams = queryParams
+	s.request = req
+	return true, nil
+}
+
+// evalArgument 求值指定参数（未提供时返回nil）
+func (s *pyScript) evalArgument(arguments map[string][]pyToken, name string) (interface{}, error) {
+	tokens, exists := arguments[name]
+	if !exists {
+		return nil, nil
+	}
+	return s.eval(tokens)
+}
+
+// jsonBody 生成 json= 参数对应的请求体：与JSON格式一致的字面量保留原文，否则按 json.dumps 默认格式序列化
+func (s *pyScript) jsonBody(tokens []pyToken) (string, error) {
+	var value interface{}
+	var err error
+	if bound, exists := s.vars[tokens[0].value]; len(tokens) == 1 && tokens[0].kind == pyName && exists {
+		tokens = bound.tokens
+		value, err = bound.value, bound.err
+	} else {
+		value, err = s.eval(tokens)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if text := s.jsonText(tokens); json.Valid([]byte(text)) {
+		return text, nil
+	}
+	var out strings.Builder
+	if err := writePyJSON(&out, value); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// jsonText 返回字面量原文，其中的 True/False/None 替换为 true/false/null
+func (s *pyScript) jsonText(tokens []pyToken) string {
+	var out strings.Builder
+	pos := tokens[0].start
+	for _, token := range tokens {
+		replacement, ok := map[string]string{"True": "true", "False": "false", "None": "null"}[token.value]
+		if token.kind != pyName || !ok {
+			continue
+		}
+		out.WriteString(string(s.source[pos:token.start]))
+		out.WriteString(replacement)
+		pos = token.end
+	}
+	out.WriteString(string(s.source[pos:tokens[len(tokens)-1].end]))
+	return out.String()
+}
+
+// setHeader 设置请求头并记录顺序
+func (s *pyScript) setHeader(req *models.ParsedRequest, key, value string) {
+	if _, exists := req.Headers[key]; !exists {
+		req.HeaderOrder = append(req.HeaderOrder, key)
+	}
+	req.Headers[key] = value
+}
+
+// applyHeaders 合并请求头（值为None的请求头不发送，Cookie头同时解析为Cookie字段）
+func (s *pyScript) applyHeaders(req *models.ParsedRequest, headers *pyDict) {
+	if headers == nil {
+		return
+	}
+	for _, key := range headers.keys {
+		value, ok := pyText(headers.values[key])
+		if !ok {
+			continue
+		}
+		s.setHeader(req, key, value)
+		if strings.EqualFold(key, "Cookie") {
+			cookies, names := NewRawRequestParser().parseCookieHeader(value)
+			for _, name := range names {
+				s.setCookie(req, name, cookies[name])
+			}
+		}
+	}
+}
+
+// applyCookies 合并Cookie
+func (s *pyScript) applyCookies(req *models.ParsedRequest, cookies *pyDict) {
+	if cookies == nil {
+		return
+	}
+	for _, name := range cookies.keys {
+		if value, ok := pyText(cookies.values[name]); ok {
+			s.setCookie(req, name, value)
+		}
+	}
+}
+
+// setCookie 设置Cookie并记录顺序
+func (s *pyScript) setCookie(req *models.ParsedRequest, name, value string) {
+	if _, exists := req.Cookies[name]; !exists {
+		req.CookieOrder = append(req.CookieOrder, name)
+	}
+	req.Cookies[name] = value
+}
+
+// pyText 把字符串、数字与布尔值转为文本（None 返回 false）
+func pyText(value interface{}) (string, bool) {
+	switch text := value.(type) {
+	case string:
+		return text, true
+	case pyNumberValue:
+		return string(text), true
+	case bool:
+		if text {
+			return "True", true
+		}
+		return "False", true
+	default:
+		return "", false
+	}
+}
+
+// pyFormEncode 按 requests 的规则把 dict 或 [(键, 值)] 编码为表单，字符串原样返回
+func pyFormEncode(value interface{}) (string, error) {
+	var pairs []string
+	add := func(key string, value interface{}) error {
+		values, isList := value.([]interface{})
+		if !isList {
+			values = []interface{}{value}
+		}
+		for _, item := range values {
+			if item == nil {
+				continue
+			}
+			text, ok := pyText(item)
+			if !ok {
+				return fmt.Errorf("的值必须是字符串或数字")
+			}
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(text))
+		}
+		return nil
+	}
+
+	switch form := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return form, nil
+	case *pyDict:
+		for _, key := range form.keys {
+			if err := add(key, form.values[key]); err != nil {
+				return "", err
+			}
+		}
+	case []interface{}:
+		for _, item := range form {
+			pair, ok := item.([]interface{})
+			if !ok || len(pair) != 2 {
+				return "", fmt.Errorf("必须是 dict 或 (键, 值) 列表")
+			}
+			key, _ := pyText(pair[0])
+			if err := add(key, pair[1]); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return "", fmt.Errorf("必须是 dict 或 (键, 值) 列表")
+	}
+	return strings.Join(pairs, "&"), nil
+}
+
+// writePyJSON 按 json.dumps 的默认格式（", " 与 ": " 分隔，非ASCII转义）输出值
+func writePyJSON(out *strings.Builder, value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		out.WriteString("null")
+	case bool:
+		if v {
+			out.WriteString("true")
+		} else {
+			out.WriteString("false")
+		}
+	case pyNumberValue:
+		out.WriteString(string(v))
+	case string:
+		out.WriteByte('"')
+		for _, char := range v {
+			switch {
+			case char == '"' || char == '\\':
+				out.WriteByte('\\')
+				out.WriteRune(char)
+			case char == '\n':
+				out.WriteString(`\n`)
+			case char == '\r':
+				out.WriteString(`\r`)
+			case char == '\t':
+				out.WriteString(`\t`)
+			case char == '\b':
+				out.WriteString(`\b`)
+			case char == '\f':
+				out.WriteString(`\f`)
+			case char < 0x20 || char > 0x7e && char <= 0xffff:
+				fmt.Fprintf(out, `\u%04x`, char)
+			case char > 0xffff:
+				char -= 0x10000
+				fmt.Fprintf(out, `\u%04x\u%04x`, 0xd800+(char>>10), 0xdc00+(char&0x3ff))
+			default:
+				out.WriteRune(char)
+			}
+		}
+		out.WriteByte('"')
+	case []interface{}:
+		out.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			if err := writePyJSON(out, item); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case *pyDict:
+		out.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				out.WriteString(", ")
+			}
+			writePyJSON(out, key)
+			out.WriteString(": ")
+			if err := writePyJSON(out, v.values[key]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return fmt.Errorf("无法序列化为JSON的值")
+	}
+	return nil
+}
+
+// eval 求值字面量表达式
+func (s *pyScript) eval(tokens []pyToken) (interface{}, error) {
+	e := &pyEvaluator{script: s, tokens: tokens}
+	value, err := e.expression()
+	if err != nil {
+		return nil, err
+	}
+	if e.pos < len(tokens) {
+		return nil, fmt.Errorf("不支持的表达式: %s", s.text(tokens))
+	}
+	return value, nil
+}
+
+// pyEvaluator 字面量表达式求值器
+type pyEvaluator struct {
+	script *pyScript
+	tokens []pyToken
+	pos    int
+}
+
+// peek 返回当前词法单元（已到末尾时返回空单元）
+func (e *pyEvaluator) peek() pyToken {
+	if e.pos >= len(e.tokens) {
+		return pyToken{kind: pyNewline}
+	}
+	return e.tokens[e.pos]
+}
+
+// expression 读取以 + 连接的字符串或列表
+func (e *pyEvaluator) expression() (interface{}, error) {
+	value, err := e.operand()
+	if err != nil {
+		return nil, err
+	}
+	for e.peek().is(pyOp, "+") {
+		e.pos++
+		right, err := e.operand()
+		if err != nil {
+			return nil, err
+		}
+		switch left := value.(type) {
+		case string:
+			text, ok := right.(string)
+			if !ok {
+				return nil, fmt.Errorf("只支持字符串与字符串相加")
+			}
+			value = left + text
+		case []interface{}:
+			items, ok := right.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("只支持列表与列表相加")
+			}
+			value = append(append([]interface{}{}, left...), items...)
+		default:
+			return nil, fmt.Errorf("不支持的 + 运算")
+		}
+	}
+	return value, nil
+}
+
+// operand 读取单个值
+func (e *pyEvaluator) operand() (interface{}, error) {
+	token := e.peek()
+	switch {
+	case token.kind == pyString:
+		// 相邻字符串自动拼接
+		var text strings.Builder
+		for e.peek().kind == pyString {
+			text.WriteString(e.peek().value)
+			e.pos++
+		}
+		return text.String(), nil
+
+	case token.kind == pyNumber:
+		e.pos++
+		return pyNumberValue(strings.ReplaceAll(token.value, "_", "")), nil
+
+	case token.is(pyOp, "-") && e.pos+1 < len(e.tokens) && e.tokens[e.pos+1].kind == pyNumber:
+		e.pos += 2
+		return pyNumberValue("-" + strings.ReplaceAll(e.tokens[e.pos-1].value, "_", "")), nil
+
+	case token.is(pyOp, "{"):
+		return e.dict()
+
+	case token.is(pyOp, "["):
+		e.pos++
+		return e.items("]")
+
+	case token.is(pyOp, "("):
+		e.pos++
+		items, err := e.items(")")
+		if err != nil {
+			return nil, err
+		}
+		// (x) 是括号表达式，(x,) 才是元组
+		if len(items) == 1 && !e.tokens[e.pos-2].is(pyOp, ",") {
+			return items[0], nil
+		}
+		return items, nil
+
+	case token.kind == pyName:
+		e.pos++
+		switch token.value {
+		case "True":
+			return true, nil
+		case "False":
+			return false, nil
+		case "None":
+			return nil, nil
+		}
+		if _, defined := e.script.vars[token.value]; !defined {
+			// 旧版生成器直接输出JSON，true/false/null 在Python中并不存在
+			if value, isJSON := map[string]interface{}{"true": true, "false": false, "null": nil}[token.value]; isJSON {
+				e.script.warnOnce("%s 不是Python字面量，已按JSON值处理", token.value)
+				return value, nil
+			}
+		}
+		if e.peek().is(pyOp, "(") {
+			return nil, fmt.Errorf("不支持函数调用: %s", token.value)
+		}
+		binding, exists := e.script.vars[token.value]
+		if !exists {
+			return nil, fmt.Errorf("未定义的变量: %s", token.value)
+		}
+		return binding.value, binding.err
+
+	default:
+		return nil, fmt.Errorf("不支持的表达式: %s", e.script.text(e.tokens[e.pos:]))
+	}
+}
+
+// dict 读取 dict 字面量（支持 **变量 展开）
+func (e *pyEvaluator) dict() (interface{}, error) {
+	e.pos++ // {
+	dict := &pyDict{values: make(map[string]interface{})}
+	for {
+		if e.peek().is(pyOp, "}") {
+			e.pos++
+			return dict, nil
+		}
+
+		if e.peek().is(pyOp, "**") {
+			e.pos++
+			value, err := e.operand()
+			if err != nil {
+				return nil, err
+			}
+			spread, ok := value.(*pyDict)
+			if !ok {
+				return nil, fmt.Errorf("** 只能展开 dict")
+			}
+			for _, key := range spread.keys {
+				dict.set(key, spread.values[key])
+			}
+		} else {
+			keyValue, err := e.expression()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := pyText(keyValue)
+			if !ok {
+				return nil, fmt.Errorf("dict 的键必须是字符串或数字")
+			}
+			if !e.peek().is(pyOp, ":") {
+				return nil, fmt.Errorf("dict 中应为 ':'")
+			}
+			e.pos++
+			value, err := e.expression()
+			if err != nil {
+				return nil, err
+			}
+			dict.set(key, value)
+		}
+
+		switch {
+		case e.peek().is(pyOp, ","):
+			e.pos++
+		case !e.peek().is(pyOp, "}"):
+			return nil, fmt.Errorf("dict 中应为 ',' 或 '}'")
+		}
+	}
+}
+
+// items 读取列表或元组的元素直到 closing
+func (e *pyEvaluator) items(closing string) ([]interface{}, error) {
+	items := []interface{}{}
+	for {
+		if e.peek().is(pyOp, closing) {
+			e.pos++
+			return items, nil
+		}
+		value, err := e.expression()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+
+		switch {
+		case e.peek().is(pyOp, ","):
+			e.pos++
+		case !e.peek().is(pyOp, closing):
+			return nil, fmt.Errorf("应为 ',' 或 '%s'", closing)
+		}
+	}
+}
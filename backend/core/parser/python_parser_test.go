@@ -0,0 +1,167 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPythonRequestParser_RoundTripGeneratedCode(t *testing.T) {
+	inputs := []string{
+		`curl 'https://example.com/api/search?q=a+b&page=2&tag=%E4%B8%AD' -H 'Accept: application/json' -H 'X-Quote: say "hi" \ bye' -b 'sid=abc; theme=dark'`,
+		`curl -X PUT 'https://example.com/api/items/1' -H 'Content-Type: application/json' -H 'X-Emoji: café 😀' --data-raw '{"name":"a\"b","ok":true,"tags":[1,2.5,null]}'`,
+		`curl 'https://example.com/login' -H 'Accept: */*' -d 'user=alice' -d 'pass=p%40ss'`,
+		`curl 'https://example.com/list?x=1&x=2&y=%E4%B8%AD'`,
+		`curl 'https://example.com/list?x=1&bad=%ZZ&flag&x=2'`,
+		`curl 'https://example.com/api' -H 'Content-Type: application/json' --data-raw '[{"path":"a/b","v":false}, null]'`,
+		`curl 'https://example.com/api' -H 'Content-Type: application/json' --data-raw '{"path":"a\/b","v":true}'`,
+		"GET /api/search?q=go HTTP/1.1\r\nHost: example.com\r\nX-Z: 1\r\nCookie: sid=abc; theme=dark\r\nAccept: */*\r\n\r\n",
+		"POST /api/items HTTP/1.1\nHost: example.com\ncookie: b=2; a=1\nContent-Type: application/json\n\n{\"url\":\"http:\\/\\/x\",\"ok\":null}",
+	}
+
+	parser := NewUnifiedRequestParser()
+	for _, input := range inputs {
+		original, err := parser.Parse(input)
+		if err != nil {
+			t.Fatalf("expected parse success for %q, got error: %v", input, err)
+		}
+
+		code := parser.GeneratePythonCode(original)
+		if inputType := parser.DetectInputType(code); inputType != "python" {
+			t.Fatalf("expected python input type, got %q", inputType)
+		}
+		parsed, err := parser.Parse(code)
+		if err != nil {
+			t.Fatalf("expected python parse success, got error: %v\n%s", err, code)
+		}
+		if strings.Contains(code, "json=data") && (strings.Contains(code, "true") || strings.Contains(code, "null")) {
+			t.Fatalf("expected python literals in generated code, got:\n%s", code)
+		}
+		if !reflect.DeepEqual(parsed, original) {
+			t.Fatalf("round trip mismatch:\n%s\nexpected %+v\ngot      %+v", code, original, parsed)
+		}
+	}
+}
+
+func TestPythonRequestParser_JSONNamesWarn(t *testing.T) {
+	input := `import requests
+
+data = {"ok":true,"tags":[1,null],"off":false}
+response = requests.post("https://example.com/api", json=data)
+`
+
+	req, err := NewPythonRequestParser().Parse(input)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Body != `{"ok":true,"tags":[1,null],"off":false}` {
+		t.Fatalf("unexpected json body: %s", req.Body)
+	}
+	if got := strings.Join(req.Warnings, ","); got != "true 不是Python字面量，已按JSON值处理,null 不是Python字面量，已按JSON值处理,false 不是Python字面量，已按JSON值处理" {
+		t.Fatalf("unexpected warnings: %v", req.Warnings)
+	}
+}
+
+func TestPythonRequestParser_HandwrittenScript(t *testing.T) {
+	input := `import requests
+from datetime import datetime
+
+BASE = "https://example.com"
+token = get_token()  # 无法求值，只有被用到时才报错
+
+common = {'Accept': 'application/json', "X-Trace": "1"}
+session = requests.Session()
+session.headers.update({**common, 'User-Agent': 'legacy-scraper/1.0'})
+session.cookies.set("sid", "abc")
+
+resp = session.request(
+    "post",
+    BASE + '/api/orders/' f"{order_id}",
+    headers={
+        'Authorization': f'Bearer {{fixed}} {api_key}',
+        'X-Trace': None,
+    },
+    params=[("page", 1), ("tag", "a b")],
+    data={"qty": 2, "note": """hello
+world""", "skip": None},
+    timeout=10,
+    proxies={"https": "http://127.0.0.1:8080"},
+)
+if resp.status_code != 200:
+    print(resp.text)
+`
+
+	parser := NewUnifiedRequestParser()
+	if inputType := parser.DetectInputType(input); inputType != "python" {
+		t.Fatalf("expected python input type, got %q", inputType)
+	}
+
+	req, err := parser.Parse(input)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "POST" || req.URL != "https://example.com/api/orders/{order_id}?page=1&tag=a+b" {
+		t.Fatalf("unexpected request line: %s %s", req.Method, req.URL)
+	}
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "Accept,X-Trace,User-Agent,Authorization,Content-Type" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if req.Headers["Authorization"] != "Bearer {fixed} {api_key}" || req.Headers["X-Trace"] != "1" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	if req.ContentType != "application/x-www-form-urlencoded" || req.Body != "qty=2&note=hello%0Aworld" {
+		t.Fatalf("unexpected body: %q (%s)", req.Body, req.ContentType)
+	}
+	if req.Cookies["sid"] != "abc" || req.QueryParams["page"] != "1" {
+		t.Fatalf("unexpected cookies or query: %v %v", req.Cookies, req.QueryParams)
+	}
+
+	warnings := strings.Join(req.Warnings, "\n")
+	for _, expected := range []string{"{order_id}", "{api_key}", "不支持的参数 proxies", "忽略无法识别的语句: if resp.status_code"} {
+		if !strings.Contains(warnings, expected) {
+			t.Fatalf("expected warning containing %q, got %v", expected, req.Warnings)
+		}
+	}
+}
+
+func TestPythonRequestParser_HttpxClientAndJSON(t *testing.T) {
+	input := `import httpx
+
+payload = {"name": "José", "active": True, "score": -1.5, "tags": ("a",)}
+
+async with httpx.AsyncClient(base_url="https://example.com/v2/", headers={"Accept": "*/*"}) as client:
+    r = await client.post("/users", json=payload, auth=("user", "pass"), follow_redirects=True)
+`
+
+	req, err := NewPythonRequestParser().Parse(input)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if req.Method != "POST" || req.URL != "https://example.com/v2/users" {
+		t.Fatalf("unexpected request line: %s %s", req.Method, req.URL)
+	}
+	if req.Body != `{"name": "Jos\u00e9", "active": true, "score": -1.5, "tags": ["a"]}` {
+		t.Fatalf("unexpected json body: %s", req.Body)
+	}
+	if req.Headers["Authorization"] != "Basic dXNlcjpwYXNz" || req.ContentType != "application/json" {
+		t.Fatalf("unexpected headers: %v", req.Headers)
+	}
+	if len(req.Warnings) != 0 {
+		t.Fatalf("expected no warnings, got %v", req.Warnings)
+	}
+}
+
+func TestPythonRequestParser_Errors(t *testing.T) {
+	parser := NewPythonRequestParser()
+	cases := map[string]string{
+		"import requests\nprint('hi')":                                 "未找到",
+		"import requests\nrequests.get(url)":                           "未定义的变量",
+		"import requests\nrequests.get('https://a.com/x":               "字符串未闭合",
+		"import requests\nrequests.post('https://a.com', json=make())": "不支持函数调用",
+	}
+	for input, expected := range cases {
+		if _, err := parser.Parse(input); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected error containing %q for %q, got %v", expected, input, err)
+		}
+	}
+}
@@ -38,6 +38,8 @@ func (p *RawRequestParser) Parse(rawRequest string) (*models.ParsedRequest, erro
 	// 解析Headers和Body
 	headers := make(map[string]string)
 	cookies := make(map[string]string)
+	var headerOrder, cookieOrder []string
+	headerValues := make(map[string][]string)
 	var body string
 	var bodyStartIndex int
 
@@ -53,13 +55,20 @@ func (p *RawRequestParser) Parse(rawRequest string) (*models.ParsedRequest, erro
 		if colonIndex := strings.Index(line, ":"); colonIndex > 0 {
 			key := strings.TrimSpace(line[:colonIndex])
 			value := strings.TrimSpace(line[colonIndex+1:])
+			if _, exists := headers[key]; !exists {
+				headerOrder = append(headerOrder, key)
+			}
 			headers[key] = value
+			headerValues[key] = append(headerValues[key], value)
 
 			// 特殊处理Cookie header
 			if strings.ToLower(key) == "cookie" {
-				cookieMap := p.parseCookieHeader(value)
-				for k, v := range cookieMap {
-					cookies[k] = v
+				cookieMap, names := p.parseCookieHeader(value)
+				for _, name := range names {
+					if _, exists := cookies[name]; !exists {
+						cookieOrder = append(cookieOrder, name)
+					}
+					cookies[name] = cookieMap[name]
 				}
 			}
 		}
@@ -92,9 +101,28 @@ func (p *RawRequestParser) Parse(rawRequest string) (*models.ParsedRequest, erro
 		Body:        body,
 		QueryParams: queryParams,
 		ContentType: contentType,
+		HeaderOrder: headerOrder,
+		CookieOrder: cookieOrder,
+
+		RepeatedHeaders: repeatedHeaders(headers, headerValues),
 	}, nil
 }
 
+// repeatedHeaders 从各请求头的全部取值中挑出出现多次、且最后一个取值仍是 Headers 中的值的请求头
+func repeatedHeaders(headers map[string]string, values map[string][]string) map[string][]string {
+	var repeated map[string][]string
+	for key, list := range values {
+		if value, exists := headers[key]; !exists || len(list) < 2 || list[len(list)-1] != value {
+			continue
+		}
+		if repeated == nil {
+			repeated = make(map[string][]string)
+		}
+		repeated[key] = list
+	}
+	return repeated
+}
+
 // parseRequestLine 解析请求行
 func (p *RawRequestParser) parseRequestLine(line string) (method, url string, err error) {
 	parts := strings.Fields(line)
@@ -122,9 +150,10 @@ func (p *RawRequestParser) parseRequestLine(line string) (method, url string, er
 	return method, url, nil
 }
 
-// parseCookieHeader 解析Cookie header
-func (p *RawRequestParser) parseCookieHeader(cookieHeader string) map[string]string {
+// parseCookieHeader 解析Cookie header，同时返回Cookie名称的出现顺序
+func (p *RawRequestParser) parseCookieHeader(cookieHeader string) (map[string]string, []string) {
 	cookies := make(map[string]string)
+	var order []string
 
 	// Cookie格式: name1=value1; name2=value2
 	pairs := strings.Split(cookieHeader, ";")
@@ -133,11 +162,14 @@ func (p *RawRequestParser) parseCookieHeader(cookieHeader string) map[string]str
 		if equalIndex := strings.Index(pair, "="); equalIndex > 0 {
 			name := strings.TrimSpace(pair[:equalIndex])
 			value := strings.TrimSpace(pair[equalIndex+1:])
+			if _, exists := cookies[name]; !exists {
+				order = append(order, name)
+			}
 			cookies[name] = value
 		}
 	}
 
-	return cookies
+	return cookies, order
 }
 
 // parseQueryParams 解析URL查询参数
@@ -1,7 +1,11 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"RequestProbe/backend/models"
@@ -14,15 +18,23 @@ type RequestParser interface {
 
 // UnifiedRequestParser 统一请求解析器
 type UnifiedRequestParser struct {
-	rawParser  *RawRequestParser
-	curlParser *CurlRequestParser
+	rawParser        *RawRequestParser
+	curlParser       *CurlRequestParser
+	harParser        *HarRequestParser
+	powerShellParser *PowerShellRequestParser
+	fetchParser      *FetchRequestParser
+	pythonParser     *PythonRequestParser
 }
 
 // NewUnifiedRequestParser 创建统一解析器
 func NewUnifiedRequestParser() *UnifiedRequestParser {
 	return &UnifiedRequestParser{
-		rawParser:  NewRawRequestParser(),
-		curlParser: NewCurlRequestParser(),
+		rawParser:        NewRawRequestParser(),
+		curlParser:       NewCurlRequestParser(),
+		harParser:        NewHarRequestParser(),
+		powerShellParser: NewPowerShellRequestParser(),
+		fetchParser:      NewFetchRequestParser(),
+		pythonParser:     NewPythonRequestParser(),
 	}
 }
 
@@ -38,10 +50,20 @@ func (p *UnifiedRequestParser) Parse(input string) (*models.ParsedRequest, error
 	switch inputType {
 	case "curl":
 		return p.curlParser.Parse(input)
+	case "curl-cmd":
+		return p.curlParser.ParseCmd(input)
+	case "powershell":
+		return p.powerShellParser.Parse(input)
+	case "fetch":
+		return p.fetchParser.Parse(input)
+	case "python":
+		return p.pythonParser.Parse(input)
 	case "raw":
 		return p.rawParser.Parse(input)
+	case "har":
+		return p.harParser.Parse(input)
 	default:
-		return nil, fmt.Errorf("无法识别的请求格式，请使用Raw HTTP格式或Curl命令")
+		return nil, fmt.Errorf("无法识别的请求格式，请使用Raw HTTP格式、Curl命令、PowerShell命令、fetch调用、Python代码或HAR文件")
 	}
 }
 
@@ -49,16 +71,39 @@ func (p *UnifiedRequestParser) Parse(input string) (*models.ParsedRequest, error
 func (p *UnifiedRequestParser) DetectInputType(input string) string {
 	trimmed := strings.TrimSpace(input)
 
-	// 检测是否为Curl命令
+	// 检测是否为Curl命令（Windows cmd 格式使用 ^ 转义）
+	if p.curlParser.IsCmdCommand(trimmed) {
+		return "curl-cmd"
+	}
 	if p.curlParser.IsCurlCommand(trimmed) {
 		return "curl"
 	}
 
+	// 检测是否为PowerShell命令
+	if p.powerShellParser.IsPowerShellCommand(trimmed) {
+		return "powershell"
+	}
+
+	// 检测是否为fetch调用
+	if p.fetchParser.IsFetchCommand(trimmed) {
+		return "fetch"
+	}
+
+	// 检测是否为Python requests/httpx代码
+	if p.pythonParser.IsPythonCode(trimmed) {
+		return "python"
+	}
+
 	// 检测是否为Raw HTTP请求
 	if p.rawParser.IsRawRequest(trimmed) {
 		return "raw"
 	}
 
+	// 检测是否为HAR文件
+	if p.harParser.IsHarFile(trimmed) {
+		return "har"
+	}
+
 	return "unknown"
 }
 
@@ -67,13 +112,33 @@ func (p *UnifiedRequestParser) ParseWithType(input, inputType string) (*models.P
 	switch strings.ToLower(inputType) {
 	case "curl":
 		return p.curlParser.Parse(input)
+	case "curl-cmd", "cmd":
+		return p.curlParser.ParseCmd(input)
+	case "powershell", "pwsh":
+		return p.powerShellParser.Parse(input)
+	case "fetch":
+		return p.fetchParser.Parse(input)
+	case "python", "requests":
+		return p.pythonParser.Parse(input)
 	case "raw", "http":
 		return p.rawParser.Parse(input)
+	case "har":
+		return p.harParser.Parse(input)
 	default:
 		return nil, fmt.Errorf("不支持的输入类型: %s", inputType)
 	}
 }
 
+// ListHarEntries 按条件列出HAR文件中的条目
+func (p *UnifiedRequestParser) ListHarEntries(input string, filter models.HarEntryFilter) ([]models.HarEntrySummary, error) {
+	return p.harParser.ListEntries(input, filter)
+}
+
+// ParseHarEntry 解析HAR文件中指定序号的条目，同时返回记录的响应
+func (p *UnifiedRequestParser) ParseHarEntry(input string, index int) (*models.HarImport, error) {
+	return p.harParser.ParseEntry(input, index)
+}
+
 // ValidateRequest 验证解析后的请求
 func (p *UnifiedRequestParser) ValidateRequest(req *models.ParsedRequest) error {
 	if req == nil {
@@ -112,53 +177,63 @@ func (p *UnifiedRequestParser) ValidateRequest(req *models.ParsedRequest) error
 
 // GeneratePythonCode 生成Python requests代码
 func (p *UnifiedRequestParser) GeneratePythonCode(req *models.ParsedRequest) string {
+	return GeneratePythonCode(req)
+}
+
+// GeneratePythonCode 生成Python requests代码（解析结果与精简结果共用）
+func GeneratePythonCode(req *models.ParsedRequest) string {
 	var code strings.Builder
 
 	code.WriteString("import requests\n\n")
 
+	// Cookie头能完整还原Cookies时直接写在headers中（requests 在已有Cookie头时忽略cookies参数），否则单独写cookies
+	cookieHeader := cookieHeaderName(req)
+	sendCookies := len(req.Cookies) > 0 && cookieHeader == ""
+
 	// Headers
 	if len(req.Headers) > 0 {
 		code.WriteString("headers = {\n")
-		for key, value := range req.Headers {
-			// 跳过Cookie header，因为会单独处理
-			if strings.ToLower(key) != "cookie" {
-				code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
+		for _, key := range req.OrderedHeaderNames() {
+			// 跳过无法还原Cookies的Cookie header，因为会单独处理
+			if strings.ToLower(key) != "cookie" || key == cookieHeader {
+				code.WriteString(fmt.Sprintf("    %s: %s,\n", strconv.Quote(key), strconv.Quote(req.Headers[key])))
 			}
 		}
 		code.WriteString("}\n")
 	}
 
 	// Cookies
-	if len(req.Cookies) > 0 {
+	if sendCookies {
 		code.WriteString("cookies = {\n")
-		for key, value := range req.Cookies {
-			code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
+		for _, key := range req.OrderedCookieNames() {
+			code.WriteString(fmt.Sprintf("    %s: %s,\n", strconv.Quote(key), strconv.Quote(req.Cookies[key])))
 		}
 		code.WriteString("}\n")
 	}
 
-	// 解析URL和参数
-	baseURL, queryParams := p.parseURLAndParams(req.URL)
-	code.WriteString(fmt.Sprintf("url = \"%s\"\n", baseURL))
+	// 解析URL和参数（列表形式保留重复参数和顺序，由requests负责编码）
+	baseURL, queryPairs := pythonQueryPairs(req.URL)
+	code.WriteString(fmt.Sprintf("url = %s\n", strconv.Quote(baseURL)))
 
-	// 查询参数
-	if len(queryParams) > 0 {
-		code.WriteString("params = {\n")
-		for key, value := range queryParams {
-			code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
+	if len(queryPairs) > 0 {
+		code.WriteString("params = [\n")
+		for _, pair := range queryPairs {
+			code.WriteString(fmt.Sprintf("    (%s, %s),\n", strconv.Quote(pair[0]), strconv.Quote(pair[1])))
 		}
-		code.WriteString("}\n")
+		code.WriteString("]\n")
 	}
 
 	// 请求体
 	var dataParam string
 	if req.Body != "" {
 		// 尝试判断是否为JSON
-		if strings.HasPrefix(strings.TrimSpace(req.Body), "{") || strings.HasPrefix(strings.TrimSpace(req.Body), "[") {
-			code.WriteString(fmt.Sprintf("data = %s\n", req.Body))
+		trimmedBody := strings.TrimSpace(req.Body)
+		literal, exact := jsonToPythonLiteral(trimmedBody)
+		if (strings.HasPrefix(trimmedBody, "{") || strings.HasPrefix(trimmedBody, "[")) && json.Valid([]byte(trimmedBody)) && exact {
+			code.WriteString(fmt.Sprintf("data = %s\n", literal))
 			dataParam = "json=data"
 		} else {
-			code.WriteString(fmt.Sprintf("data = \"%s\"\n", req.Body))
+			code.WriteString(fmt.Sprintf("data = %s\n", strconv.Quote(req.Body)))
 			dataParam = "data=data"
 		}
 	}
@@ -170,11 +245,11 @@ func (p *UnifiedRequestParser) GeneratePythonCode(req *models.ParsedRequest) str
 		code.WriteString(", headers=headers")
 	}
 
-	if len(req.Cookies) > 0 {
+	if sendCookies {
 		code.WriteString(", cookies=cookies")
 	}
 
-	if len(queryParams) > 0 {
+	if len(queryPairs) > 0 {
 		code.WriteString(", params=params")
 	}
 
@@ -189,21 +264,81 @@ func (p *UnifiedRequestParser) GeneratePythonCode(req *models.ParsedRequest) str
 	return code.String()
 }
 
-// parseURLAndParams 解析URL，分离基础URL和查询参数
-func (p *UnifiedRequestParser) parseURLAndParams(fullURL string) (string, map[string]string) {
-	parts := strings.Split(fullURL, "?")
-	baseURL := parts[0]
-	queryParams := make(map[string]string)
-
-	if len(parts) > 1 {
-		// 解析查询参数
-		paramPairs := strings.Split(parts[1], "&")
-		for _, pair := range paramPairs {
-			if keyValue := strings.Split(pair, "="); len(keyValue) == 2 {
-				queryParams[keyValue[0]] = keyValue[1]
-			}
+// cookieHeaderName 返回能按原顺序完整还原 Cookies 的 Cookie 请求头名称，没有时返回空字符串
+func cookieHeaderName(req *models.ParsedRequest) string {
+	for _, key := range req.OrderedHeaderNames() {
+		if strings.ToLower(key) != "cookie" || len(req.HeaderValues(key)) != 1 {
+			continue
+		}
+		cookies, order := NewRawRequestParser().parseCookieHeader(req.Headers[key])
+		if reflect.DeepEqual(cookies, req.Cookies) && reflect.DeepEqual(order, req.OrderedCookieNames()) {
+			return key
+		}
+	}
+	return ""
+}
+
+// pythonQueryPairs 把URL拆分为基础URL和按顺序排列的解码后参数（保留重复参数）
+//
+// 只有每个参数解码后再经 urlencode 编码能还原原文时才拆分，否则返回完整URL且不返回参数，查询串原样留在URL中，
+// 避免无法解码的 %ZZ、无值参数或非标准编码在 requests 重新编码后发生变化。
+func pythonQueryPairs(fullURL string) (string, [][2]string) {
+	baseURL, query, found := strings.Cut(fullURL, "?")
+	if !found || query == "" {
+		return fullURL, nil
+	}
+
+	var pairs [][2]string
+	for _, pair := range strings.Split(query, "&") {
+		rawKey, rawValue, hasValue := strings.Cut(pair, "=")
+		key, keyErr := url.QueryUnescape(rawKey)
+		value, valueErr := url.QueryUnescape(rawValue)
+		if !hasValue || keyErr != nil || valueErr != nil || rawKey == "" ||
+			url.QueryEscape(key) != rawKey || url.QueryEscape(value) != rawValue {
+			return fullURL, nil
 		}
+		pairs = append(pairs, [2]string{key, value})
 	}
+	return baseURL, pairs
+}
 
-	return baseURL, queryParams
+// jsonToPythonLiteral 把合法的JSON文本改写为等价的Python字面量（保留原有格式）
+//
+// 字符串之外的 true/false/null 替换为 True/False/None，其余JSON转义与Python一致。
+// Python 没有 \/ 转义，遇到时返回 false，由调用方改为原样发送JSON文本，避免请求体发生变化。
+func jsonToPythonLiteral(text string) (string, bool) {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		switch {
+		case ch == '"':
+			out.WriteByte(ch)
+			for i++; i < len(text); i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					if text[i+1] == '/' {
+						return "", false
+					}
+					out.WriteString(text[i : i+2])
+					i++
+					continue
+				}
+				out.WriteByte(text[i])
+				if text[i] == '"' {
+					break
+				}
+			}
+		case strings.HasPrefix(text[i:], "true"):
+			out.WriteString("True")
+			i += len("true") - 1
+		case strings.HasPrefix(text[i:], "false"):
+			out.WriteString("False")
+			i += len("false") - 1
+		case strings.HasPrefix(text[i:], "null"):
+			out.WriteString("None")
+			i += len("null") - 1
+		default:
+			out.WriteByte(ch)
+		}
+	}
+	return out.String(), true
 }
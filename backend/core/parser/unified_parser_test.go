@@ -7,26 +7,6 @@ import (
 	"RequestProbe/backend/models"
 )
 
-func TestUnifiedRequestParser_parseURLAndParams(t *testing.T) {
-	parser := NewUnifiedRequestParser()
-
-	baseURL, params := parser.parseURLAndParams("https://example.com/path?foo=bar&baz=qux")
-	if baseURL != "https://example.com/path" {
-		t.Fatalf("baseURL mismatch: got %q", baseURL)
-	}
-	if params["foo"] != "bar" || params["baz"] != "qux" || len(params) != 2 {
-		t.Fatalf("params mismatch: got %#v", params)
-	}
-
-	baseURL, params = parser.parseURLAndParams("https://example.com/noquery")
-	if baseURL != "https://example.com/noquery" {
-		t.Fatalf("baseURL mismatch: got %q", baseURL)
-	}
-	if len(params) != 0 {
-		t.Fatalf("expected empty params, got %#v", params)
-	}
-}
-
 func TestUnifiedRequestParser_ValidateRequest(t *testing.T) {
 	parser := NewUnifiedRequestParser()
 
@@ -61,7 +41,7 @@ func TestUnifiedRequestParser_GeneratePythonCode_UsesBaseURLAndParams(t *testing
 	if !strings.Contains(code, "url = \"https://example.com/api\"") {
 		t.Fatalf("expected code to contain base url, got:\n%s", code)
 	}
-	if !strings.Contains(code, "params = {") || !strings.Contains(code, "\"foo\": \"bar\"") {
+	if !strings.Contains(code, "params = [") || !strings.Contains(code, "(\"foo\", \"bar\")") {
 		t.Fatalf("expected code to contain params, got:\n%s", code)
 	}
 	if !strings.Contains(code, "headers = {") || !strings.Contains(code, "\"Accept\": \"application/json\"") {
@@ -72,3 +52,44 @@ func TestUnifiedRequestParser_GeneratePythonCode_UsesBaseURLAndParams(t *testing
 	}
 }
 
+func TestUnifiedRequestParser_RawPreservesHeaderAndCookieOrder(t *testing.T) {
+	parser := NewUnifiedRequestParser()
+
+	req, err := parser.Parse("GET /api HTTP/1.1\nHost: example.com\nX-Z: 1\nCookie: sid=1; _ga=2; lang=zh\nAccept: */*\n\n")
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+
+	if got := strings.Join(req.OrderedHeaderNames(), ","); got != "Host,X-Z,Cookie,Accept" {
+		t.Fatalf("unexpected header order: %s", got)
+	}
+	if got := strings.Join(req.OrderedCookieNames(), ","); got != "sid,_ga,lang" {
+		t.Fatalf("unexpected cookie order: %s", got)
+	}
+}
+
+func TestUnifiedRequestParser_RecordsRepeatedHeaders(t *testing.T) {
+	parser := NewUnifiedRequestParser()
+
+	req, err := parser.Parse("GET /api HTTP/1.1\nHost: example.com\nX-Tag: a\nAccept: */*\nX-Tag: b\n\n")
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if got := strings.Join(req.HeaderValues("X-Tag"), ","); got != "a,b" || req.Headers["X-Tag"] != "b" {
+		t.Fatalf("expected repeated X-Tag values, got %q (%v)", got, req.RepeatedHeaders)
+	}
+	if len(req.RepeatedHeaders) != 1 {
+		t.Fatalf("expected only X-Tag to be repeated, got %v", req.RepeatedHeaders)
+	}
+
+	req, err = parser.Parse(`curl 'https://example.com' -H 'X-Tag: a' -H 'X-Tag: b' -H 'X-Gone: 1' -H 'X-Gone: 2' -H 'X-Gone:'`)
+	if err != nil {
+		t.Fatalf("expected parse success, got error: %v", err)
+	}
+	if got := strings.Join(req.HeaderValues("X-Tag"), ","); got != "a,b" {
+		t.Fatalf("expected repeated X-Tag values, got %q", got)
+	}
+	if _, exists := req.RepeatedHeaders["X-Gone"]; exists || len(req.HeaderValues("X-Gone")) != 0 {
+		t.Fatalf("expected removed header to be dropped, got %v", req.RepeatedHeaders)
+	}
+}
@@ -0,0 +1,242 @@
+package tester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// 请求体类型
+const (
+	bodyKindNone = ""
+	bodyKindJSON = "json"
+	bodyKindForm = "form"
+)
+
+// bodyField 请求体中的一个可测试字段
+type bodyField struct {
+	Path  string // 字段路径，嵌套JSON字段用 . 连接
+	Value string // 字段值（JSON为原始JSON文本，表单为解码后的值）
+	Depth int    // 嵌套深度，顶层为0
+}
+
+// orderedJSONObject 保持键顺序的JSON对象
+type orderedJSONObject struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+// detectBodyKind 判断请求体是否为可拆分字段的JSON对象或表单
+func detectBodyKind(req *models.ParsedRequest) string {
+	body := strings.TrimSpace(req.Body)
+	if body == "" {
+		return bodyKindNone
+	}
+
+	contentType := strings.ToLower(req.ContentType)
+	if contentType == "" {
+		for key, value := range req.Headers {
+			if strings.EqualFold(key, "content-type") {
+				contentType = strings.ToLower(value)
+				break
+			}
+		}
+	}
+
+	switch {
+	case strings.Contains(contentType, "x-www-form-urlencoded"):
+		return bodyKindForm
+	case strings.Contains(contentType, "json"), contentType == "" && strings.HasPrefix(body, "{"):
+		if _, err := parseOrderedJSONObject([]byte(body)); err == nil {
+			return bodyKindJSON
+		}
+	}
+	return bodyKindNone
+}
+
+// listBodyFields 列出请求体中的全部字段（JSON按深度优先、父字段在前）
+func listBodyFields(req *models.ParsedRequest) []bodyField {
+	switch detectBodyKind(req) {
+	case bodyKindJSON:
+		object, err := parseOrderedJSONObject([]byte(strings.TrimSpace(req.Body)))
+		if err != nil {
+			return nil
+		}
+		var fields []bodyField
+		collectJSONFields(object, "", 0, &fields)
+		return fields
+
+	case bodyKindForm:
+		var fields []bodyField
+		seen := make(map[string]bool)
+		for _, pair := range strings.Split(strings.TrimSpace(req.Body), "&") {
+			if pair == "" {
+				continue
+			}
+			key := queryPairKey(pair)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			value := ""
+			if index := strings.Index(pair, "="); index >= 0 {
+				value = pair[index+1:]
+				if decoded, err := url.QueryUnescape(value); err == nil {
+					value = decoded
+				}
+			}
+			fields = append(fields, bodyField{Path: key, Value: value})
+		}
+		return fields
+	}
+	return nil
+}
+
+// collectJSONFields 递归收集JSON对象字段
+func collectJSONFields(object *orderedJSONObject, prefix string, depth int, fields *[]bodyField) {
+	for _, key := range object.keys {
+		path := joinBodyPath(prefix, key)
+		raw := object.values[key]
+		*fields = append(*fields, bodyField{Path: path, Value: string(raw), Depth: depth})
+
+		if nested, err := parseOrderedJSONObject(raw); err == nil {
+			collectJSONFields(nested, path, depth+1, fields)
+		}
+	}
+}
+
+// testableBodyFields 返回需要测试的请求体字段（未开启递归时只测试顶层字段）
+func testableBodyFields(req *models.ParsedRequest, config *models.ValidationConfig) []bodyField {
+	if !config.BodyFieldTest.Enabled {
+		return nil
+	}
+
+	var fields []bodyField
+	for _, field := range listBodyFields(req) {
+		if field.Depth == 0 || config.BodyFieldTest.Recursive {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// rebuildBody 只保留 keep 中存在的字段重建请求体
+func rebuildBody(req *models.ParsedRequest, keep map[string]string) string {
+	switch detectBodyKind(req) {
+	case bodyKindJSON:
+		object, err := parseOrderedJSONObject([]byte(strings.TrimSpace(req.Body)))
+		if err != nil {
+			return req.Body
+		}
+		var buf bytes.Buffer
+		if !writeFilteredJSON(&buf, object, "", keep) {
+			return req.Body
+		}
+		return buf.String()
+
+	case bodyKindForm:
+		body := strings.TrimSpace(req.Body)
+		if filtered := filterEncodedPairs(body, keep); filtered != body {
+			return filtered
+		}
+	}
+	return req.Body
+}
+
+// writeFilteredJSON 输出过滤后的JSON对象，返回是否有字段被移除
+func writeFilteredJSON(buf *bytes.Buffer, object *orderedJSONObject, prefix string, keep map[string]string) bool {
+	changed := false
+	buf.WriteByte('{')
+	first := true
+	for _, key := range object.keys {
+		path := joinBodyPath(prefix, key)
+		if _, ok := keep[path]; !ok {
+			changed = true
+			continue
+		}
+
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+
+		encodedKey, _ := json.Marshal(key)
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+
+		raw := object.values[key]
+		if nested, err := parseOrderedJSONObject(raw); err == nil {
+			if writeFilteredJSON(buf, nested, path, keep) {
+				changed = true
+			}
+			continue
+		}
+		buf.Write(raw)
+	}
+	buf.WriteByte('}')
+	return changed
+}
+
+// removeBodyFieldTree 从字段集合中移除指定字段及其全部子字段
+func removeBodyFieldTree(fields map[string]string, path string) {
+	delete(fields, path)
+	prefix := path + "."
+	for key := range fields {
+		if strings.HasPrefix(key, prefix) {
+			delete(fields, key)
+		}
+	}
+}
+
+// joinBodyPath 拼接字段路径
+func joinBodyPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// parseOrderedJSONObject 解析JSON对象并保留键顺序
+func parseOrderedJSONObject(data []byte) (*orderedJSONObject, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return nil, fmt.Errorf("不是JSON对象")
+	}
+
+	object := &orderedJSONObject{values: make(map[string]json.RawMessage)}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("无效的JSON键")
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if _, exists := object.values[key]; !exists {
+			object.keys = append(object.keys, key)
+		}
+		object.values[key] = raw
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("JSON对象后存在多余内容")
+	}
+	return object, nil
+}
@@ -0,0 +1,67 @@
+package tester
+
+import (
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestListAndRebuildJSONBodyFields(t *testing.T) {
+	req := &models.ParsedRequest{
+		Method:      "POST",
+		Body:        `{"page":1,"filter":{"kw":"go","sort":"desc"},"tags":["a","b"],"_t":1700000000}`,
+		ContentType: "application/json",
+	}
+
+	fields := listBodyFields(req)
+	var paths []string
+	for _, field := range fields {
+		paths = append(paths, field.Path)
+	}
+	want := []string{"page", "filter", "filter.kw", "filter.sort", "tags", "_t"}
+	if len(paths) != len(want) {
+		t.Fatalf("expected %v, got %v", want, paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, paths)
+		}
+	}
+
+	keep := make(map[string]string)
+	for _, field := range fields {
+		keep[field.Path] = field.Value
+	}
+	if got := rebuildBody(req, keep); got != req.Body {
+		t.Fatalf("expected body unchanged when nothing removed, got %s", got)
+	}
+
+	removeBodyFieldTree(keep, "_t")
+	removeBodyFieldTree(keep, "filter.sort")
+	if got := rebuildBody(req, keep); got != `{"page":1,"filter":{"kw":"go"},"tags":["a","b"]}` {
+		t.Fatalf("unexpected rebuilt body: %s", got)
+	}
+
+	removeBodyFieldTree(keep, "filter")
+	if _, exists := keep["filter.kw"]; exists {
+		t.Fatalf("expected nested fields removed with parent")
+	}
+}
+
+func TestRebuildFormBody(t *testing.T) {
+	req := &models.ParsedRequest{
+		Method:      "POST",
+		Body:        "user=alice&token=a%2Bb&spm=x.y",
+		ContentType: "application/x-www-form-urlencoded; charset=UTF-8",
+	}
+
+	fields := listBodyFields(req)
+	if len(fields) != 3 || fields[1].Value != "a+b" {
+		t.Fatalf("unexpected form fields: %#v", fields)
+	}
+
+	got := rebuildBody(req, map[string]string{"user": "alice", "token": "a+b"})
+	if got != "user=alice&token=a%2Bb" {
+		t.Fatalf("unexpected rebuilt form body: %s", got)
+	}
+}
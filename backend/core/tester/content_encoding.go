@@ -0,0 +1,103 @@
+package tester
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// maxDecodedBodySize 解码后响应体的大小上限，防止压缩炸弹
+const maxDecodedBodySize = 64 << 20
+
+// parseContentEncodings 解析 Content-Encoding 头（可能有多个值），返回按应用顺序排列的编码
+func parseContentEncodings(values []string) []string {
+	var codings []string
+	for _, value := range values {
+		for _, coding := range strings.Split(value, ",") {
+			coding = strings.ToLower(strings.TrimSpace(coding))
+			if coding == "" || coding == "identity" {
+				continue
+			}
+			codings = append(codings, coding)
+		}
+	}
+	return codings
+}
+
+// decodeContentEncoding 按 Content-Encoding 逆序解码响应体
+//
+// 多个编码按服务器应用的顺序列出，解码时从最后一个开始。返回解码后的字节和
+// 实际解码的编码（按解码顺序）；遇到不支持的编码或解码失败时返回已成功解码的部分和错误。
+func decodeContentEncoding(body []byte, values []string) ([]byte, []string, error) {
+	codings := parseContentEncodings(values)
+	var applied []string
+
+	data := body
+	for i := len(codings) - 1; i >= 0; i-- {
+		decoded, err := decodeOne(data, codings[i])
+		if err != nil {
+			return data, applied, fmt.Errorf("%s 解码失败: %v", codings[i], err)
+		}
+		data = decoded
+		applied = append(applied, codings[i])
+	}
+	return data, applied, nil
+}
+
+// decodeOne 解码单个内容编码
+func decodeOne(data []byte, coding string) ([]byte, error) {
+	switch coding {
+	case "gzip", "x-gzip":
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readLimited(reader)
+
+	case "deflate":
+		// 规范要求 zlib 格式，但不少服务器直接发送原始 deflate 数据
+		if reader, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+			defer reader.Close()
+			if decoded, err := readLimited(reader); err == nil {
+				return decoded, nil
+			}
+		}
+		reader := flate.NewReader(bytes.NewReader(data))
+		defer reader.Close()
+		return readLimited(reader)
+
+	case "br":
+		return readLimited(brotli.NewReader(bytes.NewReader(data)))
+
+	case "zstd":
+		reader, err := zstd.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return readLimited(reader)
+
+	default:
+		return nil, fmt.Errorf("不支持的内容编码")
+	}
+}
+
+// readLimited 读取全部数据，超过大小上限时报错
+func readLimited(reader io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxDecodedBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecodedBodySize {
+		return nil, fmt.Errorf("解码后超过 %d MB", maxDecodedBodySize>>20)
+	}
+	return data, nil
+}
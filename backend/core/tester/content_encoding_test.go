@@ -0,0 +1,133 @@
+package tester
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"RequestProbe/backend/models"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	writer.Write(data)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func brotliBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := brotli.NewWriter(&buf)
+	writer.Write(data)
+	if err := writer.Close(); err != nil {
+		t.Fatalf("brotli: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecodeContentEncoding(t *testing.T) {
+	plain := []byte(`{"message":"你好"}`)
+
+	var raw bytes.Buffer
+	flateWriter, _ := flate.NewWriter(&raw, flate.DefaultCompression)
+	flateWriter.Write(plain)
+	flateWriter.Close()
+
+	zstdEncoder, _ := zstd.NewWriter(nil)
+	zstdBody := zstdEncoder.EncodeAll(plain, nil)
+	zstdEncoder.Close()
+
+	tests := []struct {
+		name    string
+		body    []byte
+		header  []string
+		applied []string
+	}{
+		{"无编码", plain, nil, nil},
+		{"identity", plain, []string{"identity"}, nil},
+		{"gzip", gzipBytes(t, plain), []string{"gzip"}, []string{"gzip"}},
+		{"原始deflate", raw.Bytes(), []string{"deflate"}, []string{"deflate"}},
+		{"br", brotliBytes(t, plain), []string{"br"}, []string{"br"}},
+		{"zstd", zstdBody, []string{"zstd"}, []string{"zstd"}},
+		{"叠加编码", brotliBytes(t, gzipBytes(t, plain)), []string{"gzip, br"}, []string{"br", "gzip"}},
+		{"多个头", brotliBytes(t, gzipBytes(t, plain)), []string{"gzip", "BR"}, []string{"br", "gzip"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, applied, err := decodeContentEncoding(tt.body, tt.header)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(decoded, plain) {
+				t.Fatalf("expected %q, got %q", plain, decoded)
+			}
+			if !reflect.DeepEqual(applied, tt.applied) {
+				t.Fatalf("expected codings %v, got %v", tt.applied, applied)
+			}
+		})
+	}
+}
+
+func TestDecodeContentEncodingUnsupported(t *testing.T) {
+	body := gzipBytes(t, []byte("hello"))
+	decoded, applied, err := decodeContentEncoding(body, []string{"compress, gzip"})
+	if err == nil {
+		t.Fatalf("expected error for unsupported coding")
+	}
+	if string(decoded) != "hello" || !reflect.DeepEqual(applied, []string{"gzip"}) {
+		t.Fatalf("expected partially decoded body, got %q %v", decoded, applied)
+	}
+}
+
+func TestTestRequestDecodesContentEncoding(t *testing.T) {
+	plain := []byte(`{"ok":true}`)
+	wire := brotliBytes(t, gzipBytes(t, plain))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip, br")
+		w.Write(wire)
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:  "GET",
+		URL:     server.URL,
+		Headers: map[string]string{"Accept-Encoding": "gzip, br"},
+		Cookies: map[string]string{},
+	}
+	response, err := NewRequestTester().TestRequest(context.Background(), req, &models.ValidationConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Body != string(plain) {
+		t.Fatalf("expected decoded body, got %q", response.Body)
+	}
+	if !bytes.Equal(response.WireBody, wire) || response.WireLength != int64(len(wire)) {
+		t.Fatalf("expected wire bytes to be kept")
+	}
+	if response.ContentLength != int64(len(plain)) {
+		t.Fatalf("expected decoded length %d, got %d", len(plain), response.ContentLength)
+	}
+	if !reflect.DeepEqual(response.ContentEncodings, []string{"br", "gzip"}) {
+		t.Fatalf("unexpected codings: %v", response.ContentEncodings)
+	}
+	if response.DecodeError != "" {
+		t.Fatalf("unexpected decode error: %s", response.DecodeError)
+	}
+}
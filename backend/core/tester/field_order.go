@@ -0,0 +1,88 @@
+package tester
+
+import (
+	"path"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// fieldTestOrder 返回指定类型字段的测试顺序
+//
+// 默认按原始输入中的顺序，配置了优先级时匹配的字段按优先级列表顺序排在前面。
+func (t *RequestTester) fieldTestOrder(req *models.ParsedRequest, config *models.ValidationConfig, fieldType string) []string {
+	var names []string
+	switch fieldType {
+	case "header":
+		names = req.OrderedHeaderNames()
+	case "cookie":
+		names = req.OrderedCookieNames()
+	case "query":
+		params := originalQueryParams(req)
+		for _, name := range queryParamOrder(req.URL) {
+			if _, exists := params[name]; exists {
+				names = append(names, name)
+			}
+		}
+	case "body":
+		for _, field := range testableBodyFields(req, config) {
+			names = append(names, field.Path)
+		}
+	}
+	return prioritizeFields(fieldType, names, config.Minimization.Priority)
+}
+
+// prioritizeFields 按优先级列表重排字段，未匹配任何优先级的字段保持原顺序排在最后
+func prioritizeFields(fieldType string, names []string, priority []string) []string {
+	if len(priority) == 0 {
+		return names
+	}
+
+	ordered := make([]string, 0, len(names))
+	placed := make([]bool, len(names))
+	for _, pattern := range priority {
+		for i, name := range names {
+			if !placed[i] && matchFieldPattern(pattern, fieldType, name) {
+				placed[i] = true
+				ordered = append(ordered, name)
+			}
+		}
+	}
+	for i, name := range names {
+		if !placed[i] {
+			ordered = append(ordered, name)
+		}
+	}
+	return ordered
+}
+
+// matchFieldPattern 判断字段是否匹配优先级条目（类型:名称 或 名称，名称支持 * 通配符）
+func matchFieldPattern(pattern, fieldType, name string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if index := strings.Index(pattern, ":"); index > 0 {
+		switch prefix := strings.ToLower(pattern[:index]); prefix {
+		case "header", "cookie", "query", "body":
+			if prefix != fieldType {
+				return false
+			}
+			pattern = pattern[index+1:]
+		}
+	}
+
+	// Header 名称不区分大小写
+	if fieldType == "header" {
+		pattern = strings.ToLower(pattern)
+		name = strings.ToLower(name)
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+// orderedFieldRefs 返回全部待测试字段的顺序
+func orderedFieldRefs(candidates []candidateField) []models.FieldRef {
+	refs := make([]models.FieldRef, 0, len(candidates))
+	for _, field := range candidates {
+		refs = append(refs, models.FieldRef{Type: field.Type, Name: field.Name})
+	}
+	return refs
+}
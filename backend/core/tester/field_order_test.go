@@ -0,0 +1,32 @@
+package tester
+
+import (
+	"reflect"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestFieldTestOrderAppliesPriority(t *testing.T) {
+	req := &models.ParsedRequest{
+		URL:         "https://example.com/?b=1&a=2",
+		Headers:     map[string]string{"Accept": "*/*", "X-Token": "t", "user-agent": "ua"},
+		Cookies:     map[string]string{"sid": "1", "_ga": "2", "_gid": "3", "lang": "zh"},
+		HeaderOrder: []string{"X-Token", "Accept", "user-agent"},
+		CookieOrder: []string{"sid", "_ga", "lang", "_gid"},
+	}
+	config := &models.ValidationConfig{Minimization: models.MinimizationConfig{
+		Priority: []string{"cookie:_g*", "lang", "User-Agent"},
+	}}
+
+	tester := NewRequestTester()
+	if got, want := tester.fieldTestOrder(req, config, "cookie"), []string{"_ga", "_gid", "lang", "sid"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected cookie order %v, got %v", want, got)
+	}
+	if got, want := tester.fieldTestOrder(req, config, "header"), []string{"user-agent", "X-Token", "Accept"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected header order %v, got %v", want, got)
+	}
+	if got, want := tester.fieldTestOrder(req, config, "query"), []string{"b", "a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected query order %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,363 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// candidateField 参与最小化的一个字段
+type candidateField struct {
+	Type  string // 字段类型：header/cookie/query/body
+	Name  string // 字段名（请求体字段为路径）
+	Value string // 原始值
+}
+
+// normalizeStrategy 规范化最小化策略，默认为累积移除
+func normalizeStrategy(strategy string) string {
+	if strings.EqualFold(strings.TrimSpace(strategy), models.StrategyDDMin) {
+		return models.StrategyDDMin
+	}
+	return models.StrategyCumulative
+}
+
+// collectCandidateFields 按 Header、Cookie、查询参数、请求体的顺序收集待测试字段
+//
+// 同类型字段按 fieldTestOrder 排序；配置为保留的 User-Agent 不参与最小化。
+func (t *RequestTester) collectCandidateFields(req *models.ParsedRequest, config *models.ValidationConfig) []candidateField {
+	var candidates []candidateField
+	for _, name := range t.fieldTestOrder(req, config, "header") {
+		if config.PreserveUserAgent && strings.EqualFold(name, "user-agent") {
+			continue
+		}
+		candidates = append(candidates, candidateField{Type: "header", Name: name, Value: req.Headers[name]})
+	}
+	for _, name := range t.fieldTestOrder(req, config, "cookie") {
+		candidates = append(candidates, candidateField{Type: "cookie", Name: name, Value: req.Cookies[name]})
+	}
+	params := originalQueryParams(req)
+	for _, name := range t.fieldTestOrder(req, config, "query") {
+		candidates = append(candidates, candidateField{Type: "query", Name: name, Value: params[name]})
+	}
+	bodyValues := make(map[string]string)
+	for _, field := range testableBodyFields(req, config) {
+		bodyValues[field.Path] = field.Value
+	}
+	for _, name := range t.fieldTestOrder(req, config, "body") {
+		candidates = append(candidates, candidateField{Type: "body", Name: name, Value: bodyValues[name]})
+	}
+	return candidates
+}
+
+// stateWithFields 从完整状态中移除未保留的候选字段
+//
+// 保留的嵌套请求体字段会连同其父字段一起保留（父字段只作为容器）。
+func stateWithFields(full *models.CumulativeTestState, candidates []candidateField, kept map[int]bool) *models.CumulativeTestState {
+	state := full.DeepCopy()
+	for i, field := range candidates {
+		if kept[i] {
+			continue
+		}
+		switch field.Type {
+		case "header":
+			if strings.EqualFold(field.Name, "user-agent") {
+				// 与累积移除一致：User-Agent 置空而不是删除，避免Go自动补充默认值
+				state.Headers[field.Name] = ""
+			} else {
+				delete(state.Headers, field.Name)
+			}
+		case "cookie":
+			delete(state.Cookies, field.Name)
+		case "query":
+			delete(state.QueryParams, field.Name)
+		case "body":
+			removeBodyFieldTree(state.BodyFields, field.Name)
+		}
+	}
+
+	for i, field := range candidates {
+		if !kept[i] || field.Type != "body" {
+			continue
+		}
+		for path := field.Name; ; {
+			state.BodyFields[path] = full.BodyFields[path]
+			index := strings.LastIndex(path, ".")
+			if index < 0 {
+				break
+			}
+			path = path[:index]
+		}
+	}
+	return state
+}
+
+// ddmin 经典增量调试算法，返回使 test 通过的 1-最小子集（候选字段下标）
+//
+// 先尝试只保留某一块，再尝试去掉某一块；都不通过时把粒度加倍，直到每块只有一个字段。
+// 调用方需保证保留全部候选字段时 test 通过。
+func ddmin(count int, test func(subset []int) bool) []int {
+	current := make([]int, count)
+	for i := range current {
+		current[i] = i
+	}
+	if count == 0 || test(nil) {
+		return nil
+	}
+
+	n := 2
+	for len(current) >= 2 {
+		chunks := splitChunks(current, n)
+		reduced := false
+
+		// 只保留某一块
+		for _, chunk := range chunks {
+			if test(chunk) {
+				current = chunk
+				n = 2
+				reduced = true
+				break
+			}
+		}
+
+		// 去掉某一块
+		if !reduced && n > 2 {
+			for i := range chunks {
+				complement := complementChunk(chunks, i)
+				if test(complement) {
+					current = complement
+					n = max(n-1, 2)
+					reduced = true
+					break
+				}
+			}
+		}
+
+		if !reduced {
+			if n >= len(current) {
+				break
+			}
+			n = min(n*2, len(current))
+		}
+	}
+	return current
+}
+
+// splitChunks 把列表按顺序尽量均匀地分成 n 块
+func splitChunks(items []int, n int) [][]int {
+	chunks := make([][]int, 0, n)
+	start := 0
+	for i := 0; i < n; i++ {
+		end := start + (len(items)-start)/(n-i)
+		chunks = append(chunks, items[start:end])
+		start = end
+	}
+	return chunks
+}
+
+// complementChunk 返回除第 skip 块以外的全部元素
+func complementChunk(chunks [][]int, skip int) []int {
+	var complement []int
+	for i, chunk := range chunks {
+		if i != skip {
+			complement = append(complement, chunk...)
+		}
+	}
+	return complement
+}
+
+// testFieldsWithDDMin 使用增量调试算法测试字段
+//
+// 字段按块移除，整块移除失败时再二分，字段之间相互依赖时比逐个移除更省请求。
+// 被移除的字段记录使其移除成功的那次请求结果；最终保留的字段没有单独的移除测试，
+// 需要逐一确认时可开启复测。
+func (t *RequestTester) testFieldsWithDDMin(run *fieldTestRun) (*models.TestResults, *legacyTestResults) {
+	results := newTestResults()
+	legacyResults := newLegacyTestResults()
+	t.recordPreservedUserAgent(run, results, legacyResults)
+
+	candidates := t.collectCandidateFields(run.original, run.config)
+	full := newFullTestState(run.original, run.config)
+	removedBy := make([]*models.SingleRequestResult, len(candidates))
+
+	minimal := ddmin(len(candidates), func(subset []int) bool {
+		if run.stopped() {
+			return false
+		}
+		kept := make(map[int]bool, len(subset))
+		for _, index := range subset {
+			kept[index] = true
+		}
+
+		run.updateProgress(fmt.Sprintf("ddmin: 测试保留 %d/%d 个字段", len(subset), len(candidates)))
+		testResult := run.execute(t.buildRequestFromState(stateWithFields(full, candidates, kept), run.original))
+		run.advance()
+
+		if testResult.Success && !run.stopped() {
+			for i := range candidates {
+				if !kept[i] && removedBy[i] == nil {
+					removedBy[i] = testResult
+				}
+			}
+		}
+		return testResult.Success
+	})
+
+	required := make(map[int]bool, len(minimal))
+	for _, index := range minimal {
+		required[index] = true
+	}
+
+	for i, field := range candidates {
+		testResult := removedBy[i]
+		if run.stopped() && testResult == nil {
+			// 运行已取消：只记录已确认可移除的字段
+			continue
+		}
+		if required[i] {
+			testResult = &models.SingleRequestResult{Note: "ddmin 最小集合中的字段（未单独复测）"}
+		}
+		legacyResult := setFieldResult(results, legacyResults, field, required[i], testResult)
+		run.updateProgressWithResult(fmt.Sprintf("完成%s: %s", fieldTypeLabel(field.Type), field.Name), &legacyResult)
+	}
+
+	return results, legacyResults
+}
+
+// verifyMinimality 复测最终的最小集合，并逐一移除保留字段检查 1-最小性
+//
+// 最小集合本身未通过时返回 false（通常说明目标接口结果不稳定），不再逐一检查。
+// 逐一检查时发现可移除的字段会被改判为非必需并从集合中移除。
+func (t *RequestTester) verifyMinimality(run *fieldTestRun, results *models.TestResults, legacyResults *legacyTestResults) bool {
+	candidates := t.collectCandidateFields(run.original, run.config)
+	full := newFullTestState(run.original, run.config)
+
+	kept := make(map[int]bool)
+	for i, field := range candidates {
+		if result, exists := fieldResults(results, field.Type)[field.Name]; exists && (result.Required || result.Unstable) {
+			kept[i] = true
+		}
+	}
+
+	run.updateProgress(fmt.Sprintf("复测最小集合（%d 个字段）", len(kept)))
+	testResult := run.execute(t.buildRequestFromState(stateWithFields(full, candidates, kept), run.original))
+	run.advance()
+	if run.stopped() {
+		return false
+	}
+	if !testResult.Success {
+		run.updateProgress("最小集合复测未通过，结果可能不稳定")
+		return false
+	}
+
+	for i, field := range candidates {
+		if !kept[i] {
+			continue
+		}
+		if run.stopped() {
+			return false
+		}
+
+		run.updateProgress(fmt.Sprintf("复测%s: %s", fieldTypeLabel(field.Type), field.Name))
+		delete(kept, i)
+		testResult := run.execute(t.buildRequestFromState(stateWithFields(full, candidates, kept), run.original))
+		run.advance()
+		if run.stopped() {
+			return false
+		}
+
+		required := !testResult.Success
+		if required {
+			kept[i] = true
+		} else {
+			testResult.Note = strings.TrimSpace("复测发现字段可移除 " + testResult.Note)
+		}
+
+		legacyResult := setFieldResult(results, legacyResults, field, required, testResult)
+		run.updateProgressWithResult(fmt.Sprintf("复测完成%s: %s", fieldTypeLabel(field.Type), field.Name), &legacyResult)
+	}
+	return true
+}
+
+// recordPreservedUserAgent 记录配置为保留的 User-Agent（不测试，直接标记为必需）
+func (t *RequestTester) recordPreservedUserAgent(run *fieldTestRun, results *models.TestResults, legacyResults *legacyTestResults) {
+	if !run.config.PreserveUserAgent {
+		return
+	}
+	for _, name := range run.original.OrderedHeaderNames() {
+		if !strings.EqualFold(name, "user-agent") {
+			continue
+		}
+		field := candidateField{Type: "header", Name: name, Value: run.original.Headers[name]}
+		legacyResult := setFieldResult(results, legacyResults, field, true, &models.SingleRequestResult{Success: true})
+		run.updateProgressWithResult(fmt.Sprintf("完成Header: %s (保留)", name), &legacyResult)
+	}
+}
+
+// setFieldResult 同时写入累积格式与传统格式的字段结果（已存在时覆盖）
+func setFieldResult(results *models.TestResults, legacyResults *legacyTestResults, field candidateField, required bool, testResult *models.SingleRequestResult) models.TestResult {
+	fieldResult := newFieldTestResult(required, field.Value, testResult)
+	fieldResults(results, field.Type)[field.Name] = fieldResult
+	legacyResult := newLegacyTestResult(field.Name, field.Type, fieldResult)
+
+	list := legacyResultList(legacyResults, field.Type)
+	for i := range *list {
+		if (*list)[i].FieldName == field.Name {
+			if (*list)[i].TestPassed {
+				legacyResults.PassedTests--
+			}
+			(*list)[i] = legacyResult
+			if legacyResult.TestPassed {
+				legacyResults.PassedTests++
+			}
+			return legacyResult
+		}
+	}
+	*list = append(*list, legacyResult)
+	if legacyResult.TestPassed {
+		legacyResults.PassedTests++
+	}
+	return legacyResult
+}
+
+// fieldResults 返回指定字段类型的累积结果集合
+func fieldResults(results *models.TestResults, fieldType string) map[string]*models.FieldTestResult {
+	switch fieldType {
+	case "cookie":
+		return results.Cookies
+	case "query":
+		return results.QueryResults
+	case "body":
+		return results.BodyResults
+	default:
+		return results.Headers
+	}
+}
+
+// legacyResultList 返回指定字段类型的传统结果列表
+func legacyResultList(legacyResults *legacyTestResults, fieldType string) *[]models.TestResult {
+	switch fieldType {
+	case "cookie":
+		return &legacyResults.CookieResults
+	case "query":
+		return &legacyResults.QueryResults
+	case "body":
+		return &legacyResults.BodyResults
+	default:
+		return &legacyResults.HeaderResults
+	}
+}
+
+// fieldTypeLabel 字段类型的显示名称（与进度消息保持一致）
+func fieldTypeLabel(fieldType string) string {
+	switch fieldType {
+	case "cookie":
+		return "Cookie"
+	case "query":
+		return "Query参数"
+	case "body":
+		return "Body字段"
+	default:
+		return "Header"
+	}
+}
@@ -0,0 +1,73 @@
+package tester
+
+import (
+	"reflect"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestDDMinFindsInteractingFields(t *testing.T) {
+	required := map[int]bool{1: true, 4: true, 6: true}
+	calls := 0
+
+	minimal := ddmin(8, func(subset []int) bool {
+		calls++
+		present := make(map[int]bool, len(subset))
+		for _, index := range subset {
+			present[index] = true
+		}
+		for index := range required {
+			if !present[index] {
+				return false
+			}
+		}
+		return true
+	})
+
+	if want := []int{1, 4, 6}; !reflect.DeepEqual(minimal, want) {
+		t.Fatalf("expected %v, got %v", want, minimal)
+	}
+	if calls == 0 {
+		t.Fatalf("expected predicate to be called")
+	}
+
+	if minimal := ddmin(5, func(subset []int) bool { return true }); len(minimal) != 0 {
+		t.Fatalf("expected empty set when nothing is required, got %v", minimal)
+	}
+}
+
+func TestStateWithFieldsKeepsBodyAncestors(t *testing.T) {
+	req := &models.ParsedRequest{
+		Method:      "POST",
+		URL:         "https://example.com/api?a=1&b=2",
+		Headers:     map[string]string{"User-Agent": "ua", "X-Token": "t"},
+		Cookies:     map[string]string{"sid": "1"},
+		Body:        `{"page":1,"filter":{"kw":"go","sort":"desc"}}`,
+		ContentType: "application/json",
+	}
+	config := &models.ValidationConfig{BodyFieldTest: models.BodyFieldTestConfig{Enabled: true, Recursive: true}}
+
+	candidates := []candidateField{
+		{Type: "header", Name: "User-Agent"},
+		{Type: "header", Name: "X-Token"},
+		{Type: "cookie", Name: "sid"},
+		{Type: "query", Name: "a"},
+		{Type: "body", Name: "filter"},
+		{Type: "body", Name: "filter.kw"},
+	}
+	full := newFullTestState(req, config)
+	state := stateWithFields(full, candidates, map[int]bool{1: true, 5: true})
+
+	tester := NewRequestTester()
+	built := tester.buildRequestFromState(state, req)
+	if built.Headers["User-Agent"] != "" || built.Headers["X-Token"] != "t" || len(built.Cookies) != 0 {
+		t.Fatalf("unexpected headers/cookies: %#v %#v", built.Headers, built.Cookies)
+	}
+	if built.URL != "https://example.com/api?b=2" {
+		t.Fatalf("unexpected url: %s", built.URL)
+	}
+	if built.Body != `{"page":1,"filter":{"kw":"go"}}` {
+		t.Fatalf("unexpected body: %s", built.Body)
+	}
+}
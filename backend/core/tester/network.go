@@ -0,0 +1,150 @@
+package tester
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// normalizeHTTPVersion 规范化协议版本，无法识别时返回错误
+func normalizeHTTPVersion(version string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(version)) {
+	case "", models.HTTPVersionAuto:
+		return models.HTTPVersionAuto, nil
+	case models.HTTPVersion1, "http/1.1", "h1":
+		return models.HTTPVersion1, nil
+	case models.HTTPVersion2, "http/2", "h2":
+		return models.HTTPVersion2, nil
+	}
+	return "", fmt.Errorf("不支持的协议版本: %s", version)
+}
+
+// parseProxyURL 解析代理地址，支持 http、https、socks5、socks5h（可带用户名密码）
+func parseProxyURL(raw string) (*url.URL, error) {
+	proxy, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("无效的代理URL: %v", err)
+	}
+	switch strings.ToLower(proxy.Scheme) {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("不支持的代理协议: %s", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("代理URL缺少主机: %s", raw)
+	}
+	return proxy, nil
+}
+
+// newTLSConfig 按网络配置创建TLS配置
+func newTLSConfig(network models.NetworkConfig, httpVersion string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: network.InsecureSkipVerify}
+
+	if network.CACertFile != "" {
+		pem, err := os.ReadFile(network.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("读取CA证书失败: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA证书中没有有效的PEM证书: %s", network.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if network.ClientCertFile != "" || network.ClientKeyFile != "" {
+		if network.ClientCertFile == "" || network.ClientKeyFile == "" {
+			return nil, fmt.Errorf("客户端证书与私钥需要同时配置")
+		}
+		cert, err := tls.LoadX509KeyPair(network.ClientCertFile, network.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch httpVersion {
+	case models.HTTPVersion1:
+		tlsConfig.NextProtos = []string{"http/1.1"}
+	case models.HTTPVersion2:
+		tlsConfig.NextProtos = []string{"h2"}
+	}
+	return tlsConfig, nil
+}
+
+// newTransport 按网络配置创建传输层
+//
+// 网络配置中的代理优先于测试器的默认代理。强制 HTTP/2 时只通过 ALPN 声明 h2，
+// 服务器仍返回 HTTP/1.x 时由 sendRequest 报错（不支持明文 h2c）。
+// 开启原始写入时使用 rawTransport，只支持 HTTP/1.1。
+func newTransport(network models.NetworkConfig, defaultProxy *url.URL) (http.RoundTripper, error) {
+	httpVersion, err := normalizeHTTPVersion(network.HTTPVersion)
+	if err != nil {
+		return nil, err
+	}
+	if network.RawHTTP1 {
+		if httpVersion == models.HTTPVersion2 {
+			return nil, fmt.Errorf("原始写入模式只支持 HTTP/1.1")
+		}
+		httpVersion = models.HTTPVersion1
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// 不自动添加 Accept-Encoding，也不自动解压，由 decodeContentEncoding 统一处理
+	transport.DisableCompression = true
+
+	proxy := defaultProxy
+	if strings.TrimSpace(network.ProxyURL) != "" {
+		if proxy, err = parseProxyURL(network.ProxyURL); err != nil {
+			return nil, err
+		}
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if transport.TLSClientConfig, err = newTLSConfig(network, httpVersion); err != nil {
+		return nil, err
+	}
+	if network.RawHTTP1 {
+		return newRawTransport(transport.TLSClientConfig, proxy), nil
+	}
+
+	switch httpVersion {
+	case models.HTTPVersion1:
+		// 非 nil 的空映射会关闭 net/http 内置的 HTTP/2 支持
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	default:
+		// 自定义 TLSClientConfig 后需要显式开启 HTTP/2
+		transport.ForceAttemptHTTP2 = true
+	}
+	return transport, nil
+}
+
+// checkNegotiatedProtocol 检查协商的协议是否满足强制的协议版本
+func checkNegotiatedProtocol(network models.NetworkConfig, resp *http.Response) error {
+	httpVersion, err := normalizeHTTPVersion(network.HTTPVersion)
+	if err != nil {
+		return err
+	}
+	if httpVersion == models.HTTPVersion2 && resp.ProtoMajor != 2 {
+		return fmt.Errorf("服务器未协商 HTTP/2（实际为 %s）", resp.Proto)
+	}
+	return nil
+}
+
+// ValidateNetworkConfig 检查网络配置能否创建传输层（代理地址、证书文件、协议版本）
+func ValidateNetworkConfig(network models.NetworkConfig) error {
+	_, err := newTransport(network, nil)
+	return err
+}
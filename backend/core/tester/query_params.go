@@ -0,0 +1,96 @@
+package tester
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// queryParamOrder 按URL中出现的顺序返回查询参数名（去重）
+func queryParamOrder(rawURL string) []string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.RawQuery == "" {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var order []string
+	for _, pair := range strings.Split(parsedURL.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		key := queryPairKey(pair)
+		if !seen[key] {
+			seen[key] = true
+			order = append(order, key)
+		}
+	}
+	return order
+}
+
+// rebuildURLWithParams 用保留的查询参数重建URL
+func rebuildURLWithParams(rawURL string, keep map[string]string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	rawQuery := filterEncodedPairs(parsedURL.RawQuery, keep)
+	if rawQuery == parsedURL.RawQuery {
+		return rawURL
+	}
+	parsedURL.RawQuery = rawQuery
+	parsedURL.ForceQuery = false
+	return parsedURL.String()
+}
+
+// filterEncodedPairs 过滤 k1=v1&k2=v2 形式的编码串
+//
+// 原串中的参数按原顺序和原编码保留（同名多值一并保留），不在 keep 中的参数被移除；
+// keep 中存在但原串没有的参数追加在末尾。
+func filterEncodedPairs(encoded string, keep map[string]string) string {
+	seen := make(map[string]bool)
+	var pairs []string
+	if encoded != "" {
+		for _, pair := range strings.Split(encoded, "&") {
+			if pair == "" {
+				continue
+			}
+			key := queryPairKey(pair)
+			if _, ok := keep[key]; ok {
+				pairs = append(pairs, pair)
+				seen[key] = true
+			}
+		}
+	}
+
+	for _, key := range sortedKeys(keep) {
+		if !seen[key] {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(keep[key]))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// queryPairKey 解码 key=value 形式中的参数名
+func queryPairKey(pair string) string {
+	key := pair
+	if index := strings.Index(pair, "="); index >= 0 {
+		key = pair[:index]
+	}
+	if decoded, err := url.QueryUnescape(key); err == nil {
+		return decoded
+	}
+	return key
+}
+
+// sortedKeys 返回按字典序排列的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,31 @@
+package tester
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryParamOrder(t *testing.T) {
+	order := queryParamOrder("https://example.com/list?page=1&utm_source=x&spm=a.b&page=2&_t=123")
+	want := []string{"page", "utm_source", "spm", "_t"}
+	if !reflect.DeepEqual(order, want) {
+		t.Fatalf("expected %v, got %v", want, order)
+	}
+}
+
+func TestRebuildURLWithParams(t *testing.T) {
+	rawURL := "https://example.com/list?page=1&utm_source=x&q=a%20b&page=2#top"
+
+	got := rebuildURLWithParams(rawURL, map[string]string{"page": "1", "q": "a b"})
+	if got != "https://example.com/list?page=1&q=a%20b&page=2#top" {
+		t.Fatalf("unexpected rebuilt URL: %s", got)
+	}
+
+	if got := rebuildURLWithParams(rawURL, map[string]string{}); got != "https://example.com/list#top" {
+		t.Fatalf("expected all params removed, got %s", got)
+	}
+
+	if got := rebuildURLWithParams(rawURL, map[string]string{"page": "1", "utm_source": "x", "q": "a b"}); got != rawURL {
+		t.Fatalf("expected URL unchanged, got %s", got)
+	}
+}
@@ -0,0 +1,389 @@
+package tester
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"RequestProbe/backend/models"
+
+	"golang.org/x/net/proxy"
+)
+
+// headerOrderKey 请求 context 中原始请求头顺序的键
+type headerOrderKey struct{}
+
+// withHeaderOrder 记录请求头在原始输入中的顺序与大小写（原始写入模式使用）
+func withHeaderOrder(ctx context.Context, names []string) context.Context {
+	return context.WithValue(ctx, headerOrderKey{}, names)
+}
+
+// sentHeaderRecorderKey 请求 context 中已发送请求头记录器的键
+type sentHeaderRecorderKey struct{}
+
+// sentHeaderRecorder 记录实际写到连接上的请求头（重定向时只保留最后一次请求）
+type sentHeaderRecorder struct {
+	mu      sync.Mutex
+	headers []models.HeaderField
+}
+
+// withSentHeaderRecorder 返回携带新记录器的 context
+//
+// 标准传输层通过 httptrace 记录 net/http 实际写出的请求头（含其自动补充的头），
+// 原始写入模式由 rawTransport 直接记录。
+func withSentHeaderRecorder(ctx context.Context) (context.Context, *sentHeaderRecorder) {
+	recorder := &sentHeaderRecorder{}
+	ctx = context.WithValue(ctx, sentHeaderRecorderKey{}, recorder)
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(string) { recorder.reset() },
+		WroteHeaderField: func(key string, values []string) {
+			for _, value := range values {
+				recorder.add(models.HeaderField{Name: key, Value: value})
+			}
+		},
+	})
+	return ctx, recorder
+}
+
+// reset 清空记录（开始发送新的请求）
+func (r *sentHeaderRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = nil
+}
+
+// add 追加一个请求头
+func (r *sentHeaderRecorder) add(field models.HeaderField) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers = append(r.headers, field)
+}
+
+// list 返回已记录的请求头
+func (r *sentHeaderRecorder) list() []models.HeaderField {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.HeaderField(nil), r.headers...)
+}
+
+// markImplicitHeaders 标记不是由解析出的请求指定、由 net/http 自动补充的请求头
+func markImplicitHeaders(fields []models.HeaderField, req *models.ParsedRequest) []models.HeaderField {
+	explicit := make(map[string]bool, len(req.Headers)+1)
+	for name, value := range req.Headers {
+		if strings.EqualFold(name, "user-agent") && value == "" {
+			continue
+		}
+		explicit[strings.ToLower(name)] = true
+	}
+	if len(req.Cookies) > 0 {
+		explicit["cookie"] = true
+	}
+	for i := range fields {
+		fields[i].Implicit = !explicit[strings.ToLower(fields[i].Name)]
+	}
+	return fields
+}
+
+// rawTransport 原始 HTTP/1.1 写入传输层
+//
+// 请求头按解析出的顺序与大小写逐行写出，不做规范化；net/http 会隐式补充的 Host、
+// Content-Length 由这里显式写出并标记为隐式。每个请求使用新连接，不复用。
+type rawTransport struct {
+	tlsConfig *tls.Config
+	proxy     *url.URL
+	dialer    *net.Dialer
+}
+
+// newRawTransport 创建原始写入传输层
+func newRawTransport(tlsConfig *tls.Config, proxyURL *url.URL) *rawTransport {
+	return &rawTransport{
+		tlsConfig: tlsConfig,
+		proxy:     proxyURL,
+		dialer:    &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *rawTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %v", err)
+		}
+		body = data
+	}
+
+	conn, err := t.dial(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	requestURI := req.URL.RequestURI()
+	absoluteForm := t.proxy != nil && req.URL.Scheme == "http" && !isSOCKSProxy(t.proxy)
+	if absoluteForm {
+		// 经 HTTP 代理访问明文地址时使用绝对形式的请求目标
+		target := *req.URL
+		target.Fragment = ""
+		requestURI = target.String()
+	}
+
+	headers := rawRequestHeaders(req, body)
+	if absoluteForm && t.proxy.User != nil {
+		headers = append(headers, models.HeaderField{Name: "Proxy-Authorization", Value: proxyAuthorization(t.proxy), Implicit: true})
+	}
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s %s HTTP/1.1\r\n", req.Method, requestURI)
+	for _, field := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", field.Name, field.Value)
+	}
+	buf.WriteString("\r\n")
+
+	if recorder, ok := ctx.Value(sentHeaderRecorderKey{}).(*sentHeaderRecorder); ok {
+		recorder.reset()
+		for _, field := range headers {
+			recorder.add(field)
+		}
+	}
+
+	if _, err := io.WriteString(conn, buf.String()); err == nil && len(body) > 0 {
+		_, err = conn.Write(body)
+	}
+	if err != nil {
+		return nil, t.closeWithError(ctx, conn, stop, err)
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return nil, t.closeWithError(ctx, conn, stop, err)
+	}
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		state := tlsConn.ConnectionState()
+		resp.TLS = &state
+	}
+	resp.Body = &rawResponseBody{ReadCloser: resp.Body, conn: conn, stop: stop}
+	return resp, nil
+}
+
+// closeWithError 关闭连接并返回错误（context 已结束时返回 context 的错误）
+func (t *rawTransport) closeWithError(ctx context.Context, conn net.Conn, stop func() bool, err error) error {
+	stop()
+	conn.Close()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// CloseIdleConnections 原始写入模式不保留空闲连接
+func (t *rawTransport) CloseIdleConnections() {}
+
+// dial 建立到目标的连接（按需经过代理并完成TLS握手）
+func (t *rawTransport) dial(ctx context.Context, target *url.URL) (net.Conn, error) {
+	addr := hostPort(target)
+
+	var conn net.Conn
+	var err error
+	switch {
+	case t.proxy == nil:
+		conn, err = t.dialer.DialContext(ctx, "tcp", addr)
+	case isSOCKSProxy(t.proxy):
+		var dialer proxy.Dialer
+		if dialer, err = proxy.FromURL(t.proxy, t.dialer); err == nil {
+			conn, err = dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", addr)
+		}
+	default:
+		conn, err = t.dialHTTPProxy(ctx, target, addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Scheme != "https" {
+		return conn, nil
+	}
+	tlsConfig := t.tlsConfig.Clone()
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = target.Hostname()
+	}
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// dialHTTPProxy 连接 HTTP 代理；目标为 HTTPS 时通过 CONNECT 建立隧道
+func (t *rawTransport) dialHTTPProxy(ctx context.Context, target *url.URL, addr string) (net.Conn, error) {
+	conn, err := t.dialer.DialContext(ctx, "tcp", hostPort(t.proxy))
+	if err != nil {
+		return nil, err
+	}
+	if t.proxy.Scheme == "https" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: t.proxy.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+	if target.Scheme != "https" {
+		return conn, nil
+	}
+
+	connect := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", addr, addr)
+	if t.proxy.User != nil {
+		connect += "Proxy-Authorization: " + proxyAuthorization(t.proxy) + "\r\n"
+	}
+	if _, err := io.WriteString(conn, connect+"\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), &http.Request{Method: http.MethodConnect})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("代理CONNECT失败: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("代理CONNECT失败: %s", resp.Status)
+	}
+	return conn, nil
+}
+
+// rawRequestHeaders 按原始顺序生成要写出的请求头
+//
+// 先按记录的顺序输出（名称保持原始大小写，重复的请求头逐行连续写在首次出现的位置），再按名称排序输出
+// 其余请求头（如重定向时 net/http 补充的 Referer）。缺少 Host 时补在最前，有请求体
+// 或方法需要时补充 Content-Length；已有的 Content-Length 按实际请求体长度改写。
+func rawRequestHeaders(req *http.Request, body []byte) []models.HeaderField {
+	order, _ := req.Context().Value(headerOrderKey{}).([]string)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	// 只有首个请求使用用户指定的 Host，重定向到其他主机时使用目标地址
+	if value := req.Header.Get("Host"); value != "" && req.Response == nil {
+		host = value
+	}
+	contentLength := strconv.Itoa(len(body))
+	needsLength := len(body) > 0 || req.Method == http.MethodPost || req.Method == http.MethodPut || req.Method == http.MethodPatch
+
+	var fields []models.HeaderField
+	seen := make(map[string]bool)
+	hasHost, hasLength := false, false
+	emit := func(name string, implicit bool) {
+		key := http.CanonicalHeaderKey(name)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		switch key {
+		case "Host":
+			hasHost = true
+			fields = append(fields, models.HeaderField{Name: name, Value: host, Implicit: implicit})
+		case "Content-Length":
+			if needsLength {
+				hasLength = true
+				fields = append(fields, models.HeaderField{Name: name, Value: contentLength, Implicit: implicit})
+			}
+		case "Transfer-Encoding":
+			// 请求体总是按 Content-Length 发送
+		default:
+			for _, value := range req.Header[key] {
+				fields = append(fields, models.HeaderField{Name: name, Value: value, Implicit: implicit})
+			}
+		}
+	}
+
+	for _, name := range order {
+		if _, exists := req.Header[http.CanonicalHeaderKey(name)]; exists {
+			emit(name, false)
+		}
+	}
+	rest := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		// Cookie 由 Cookies 生成，不算隐式
+		emit(key, key != "Cookie")
+	}
+
+	if !hasHost {
+		fields = append([]models.HeaderField{{Name: "Host", Value: host, Implicit: true}}, fields...)
+	}
+	if needsLength && !hasLength {
+		fields = append(fields, models.HeaderField{Name: "Content-Length", Value: contentLength, Implicit: true})
+	}
+	return fields
+}
+
+// rawResponseBody 读取完毕后关闭底层连接
+type rawResponseBody struct {
+	io.ReadCloser
+	conn net.Conn
+	stop func() bool
+}
+
+// Close 关闭响应体与连接
+func (b *rawResponseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.stop()
+	b.conn.Close()
+	return err
+}
+
+// isSOCKSProxy 是否为 SOCKS5 代理
+func isSOCKSProxy(proxyURL *url.URL) bool {
+	scheme := strings.ToLower(proxyURL.Scheme)
+	return scheme == "socks5" || scheme == "socks5h"
+}
+
+// proxyAuthorization 生成代理的 Basic 认证头
+func proxyAuthorization(proxyURL *url.URL) string {
+	password, _ := proxyURL.User.Password()
+	credentials := proxyURL.User.Username() + ":" + password
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// hostPort 返回 URL 的 host:port（缺省端口按协议补全）
+func hostPort(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "https":
+		return net.JoinHostPort(u.Hostname(), "443")
+	case "socks5", "socks5h":
+		return net.JoinHostPort(u.Hostname(), "1080")
+	default:
+		return net.JoinHostPort(u.Hostname(), "80")
+	}
+}
@@ -0,0 +1,169 @@
+package tester
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+// captureRawRequest 启动只接受一个连接的服务器，返回收到的原始请求头部分
+func captureRawRequest(t *testing.T) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	captured := make(chan string, 1)
+	go func() {
+		defer listener.Close()
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		var head strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			head.WriteString(line)
+			if line == "\r\n" {
+				break
+			}
+		}
+		captured <- head.String()
+		conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok"))
+	}()
+	return "http://" + listener.Addr().String(), captured
+}
+
+func TestRawHTTP1WritesHeadersInParsedOrderAndCase(t *testing.T) {
+	serverURL, captured := captureRawRequest(t)
+
+	req := &models.ParsedRequest{
+		Method: "GET",
+		URL:    serverURL + "/path?q=1",
+		Headers: map[string]string{
+			"sec-ch-ua":  `"Chromium";v="124"`,
+			"user-agent": "Mozilla/5.0",
+			"Cookie":     "b=2; a=1",
+			"accept":     "*/*",
+		},
+		Cookies:     map[string]string{"b": "2", "a": "1"},
+		HeaderOrder: []string{"sec-ch-ua", "user-agent", "Cookie", "accept"},
+		CookieOrder: []string{"b", "a"},
+	}
+	config := &models.ValidationConfig{Network: models.NetworkConfig{RawHTTP1: true}}
+	response, err := NewRequestTester().TestRequest(context.Background(), req, config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	host := strings.TrimPrefix(serverURL, "http://")
+	expected := "GET /path?q=1 HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"sec-ch-ua: \"Chromium\";v=\"124\"\r\n" +
+		"user-agent: Mozilla/5.0\r\n" +
+		"Cookie: b=2; a=1\r\n" +
+		"accept: */*\r\n" +
+		"\r\n"
+	if head := <-captured; head != expected {
+		t.Fatalf("unexpected raw request:\n%q\nwant:\n%q", head, expected)
+	}
+
+	if len(response.SentHeaders) != 5 || response.SentHeaders[0].Name != "Host" || !response.SentHeaders[0].Implicit {
+		t.Fatalf("expected implicit Host to be reported first, got %#v", response.SentHeaders)
+	}
+	for _, field := range response.SentHeaders[1:] {
+		if field.Implicit {
+			t.Fatalf("expected parsed header %s to be explicit", field.Name)
+		}
+	}
+	if response.Protocol != "HTTP/1.1" || response.Body != "ok" {
+		t.Fatalf("unexpected response: %s %q", response.Protocol, response.Body)
+	}
+}
+
+func TestRawHTTP1WritesRepeatedHeaders(t *testing.T) {
+	serverURL, captured := captureRawRequest(t)
+
+	req := &models.ParsedRequest{
+		Method:          "GET",
+		URL:             serverURL + "/",
+		Headers:         map[string]string{"x-tag": "b", "accept": "*/*", "X-Changed": "new"},
+		HeaderOrder:     []string{"x-tag", "accept", "X-Changed"},
+		RepeatedHeaders: map[string][]string{"x-tag": {"a", "b"}, "X-Changed": {"1", "2"}},
+	}
+	config := &models.ValidationConfig{Network: models.NetworkConfig{RawHTTP1: true}}
+	if _, err := NewRequestTester().TestRequest(context.Background(), req, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	host := strings.TrimPrefix(serverURL, "http://")
+	// X-Changed 的值已被改写，只发送改写后的值
+	expected := "GET / HTTP/1.1\r\n" +
+		"Host: " + host + "\r\n" +
+		"x-tag: a\r\n" +
+		"x-tag: b\r\n" +
+		"accept: */*\r\n" +
+		"X-Changed: new\r\n" +
+		"\r\n"
+	if head := <-captured; head != expected {
+		t.Fatalf("unexpected raw request:\n%q\nwant:\n%q", head, expected)
+	}
+}
+
+func TestRawHTTP1AddsContentLengthForBody(t *testing.T) {
+	serverURL, captured := captureRawRequest(t)
+
+	req := &models.ParsedRequest{
+		Method:      "POST",
+		URL:         serverURL + "/submit",
+		Headers:     map[string]string{"content-type": "application/json", "Content-Length": "999"},
+		Cookies:     map[string]string{},
+		Body:        `{"a":1}`,
+		HeaderOrder: []string{"content-type", "Content-Length"},
+	}
+	config := &models.ValidationConfig{Network: models.NetworkConfig{RawHTTP1: true}}
+	if _, err := NewRequestTester().TestRequest(context.Background(), req, config); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	head := <-captured
+	if !strings.Contains(head, "content-type: application/json\r\nContent-Length: 7\r\n") {
+		t.Fatalf("expected Content-Length to follow parsed position with real length, got %q", head)
+	}
+}
+
+func TestSentHeadersReportImplicitHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{Method: "POST", URL: server.URL, Headers: map[string]string{"X-Token": "t"}, Cookies: map[string]string{}, Body: "a=1"}
+	response, err := NewRequestTester().TestRequest(context.Background(), req, &models.ValidationConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	implicit := make(map[string]bool)
+	for _, field := range response.SentHeaders {
+		implicit[field.Name] = field.Implicit
+	}
+	for _, name := range []string{"Host", "User-Agent", "Content-Length"} {
+		if isImplicit, exists := implicit[name]; !exists || !isImplicit {
+			t.Fatalf("expected %s to be reported as implicit, got %#v", name, response.SentHeaders)
+		}
+	}
+	if isImplicit, exists := implicit["X-Token"]; !exists || isImplicit {
+		t.Fatalf("expected X-Token to be reported as explicit, got %#v", response.SentHeaders)
+	}
+}
@@ -0,0 +1,70 @@
+package tester
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"RequestProbe/backend/models"
+)
+
+// maxRedirects 跟随重定向的最大次数（与 net/http 默认值一致）
+const maxRedirects = 10
+
+// redirectRecorderKey 请求 context 中重定向记录器的键
+type redirectRecorderKey struct{}
+
+// redirectRecorder 记录一次请求跟随的全部重定向
+//
+// 记录器挂在请求的 context 上，重定向产生的后续请求共享同一个 context，
+// 因此同一会话中并发的请求各自记录，互不干扰。
+type redirectRecorder struct {
+	mu   sync.Mutex
+	hops []models.RedirectHop
+}
+
+// withRedirectRecorder 返回携带新记录器的 context
+func withRedirectRecorder(ctx context.Context) (context.Context, *redirectRecorder) {
+	recorder := &redirectRecorder{}
+	return context.WithValue(ctx, redirectRecorderKey{}, recorder), recorder
+}
+
+// record 追加一次跳转
+func (r *redirectRecorder) record(hop models.RedirectHop) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hops = append(r.hops, hop)
+}
+
+// list 返回已记录的跳转
+func (r *redirectRecorder) list() []models.RedirectHop {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.RedirectHop(nil), r.hops...)
+}
+
+// newCheckRedirect 按配置创建 http.Client 的 CheckRedirect
+//
+// 不跟随时直接返回重定向响应本身（3xx），由验证规则判定；
+// 跟随时记录每一跳的状态码、Location 与 Set-Cookie。
+func newCheckRedirect(follow bool) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if !follow {
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("重定向次数超过 %d 次", maxRedirects)
+		}
+
+		if recorder, ok := req.Context().Value(redirectRecorderKey{}).(*redirectRecorder); ok && req.Response != nil {
+			recorder.record(models.RedirectHop{
+				StatusCode: req.Response.StatusCode,
+				URL:        via[len(via)-1].URL.String(),
+				Location:   req.URL.String(),
+				SetCookies: req.Response.Header.Values("Set-Cookie"),
+			})
+		}
+		return nil
+	}
+}
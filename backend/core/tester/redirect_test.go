@@ -0,0 +1,66 @@
+package tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func newLoginRedirectServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/protected", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "return_to", Value: "protected"})
+		http.Redirect(w, r, "/login?next=/protected", http.StatusFound)
+	})
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("please login"))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestTestRequestRecordsRedirectChain(t *testing.T) {
+	server := newLoginRedirectServer()
+	defer server.Close()
+
+	req := &models.ParsedRequest{Method: "GET", URL: server.URL + "/protected", Headers: map[string]string{}, Cookies: map[string]string{}}
+	response, err := NewRequestTester().TestRequest(context.Background(), req, &models.ValidationConfig{FollowRedirect: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.StatusCode != http.StatusOK || !strings.HasSuffix(response.URL, "/login?next=/protected") {
+		t.Fatalf("expected to land on login page, got %d %s", response.StatusCode, response.URL)
+	}
+	if len(response.Redirects) != 1 {
+		t.Fatalf("expected 1 redirect hop, got %#v", response.Redirects)
+	}
+	hop := response.Redirects[0]
+	if hop.StatusCode != http.StatusFound || hop.URL != server.URL+"/protected" || hop.Location != server.URL+"/login?next=/protected" {
+		t.Fatalf("unexpected hop: %#v", hop)
+	}
+	if len(hop.SetCookies) != 1 || !strings.HasPrefix(hop.SetCookies[0], "return_to=protected") {
+		t.Fatalf("expected hop Set-Cookie to be recorded, got %v", hop.SetCookies)
+	}
+}
+
+func TestTestRequestHonorsFollowRedirect(t *testing.T) {
+	server := newLoginRedirectServer()
+	defer server.Close()
+
+	req := &models.ParsedRequest{Method: "GET", URL: server.URL + "/protected", Headers: map[string]string{}, Cookies: map[string]string{}}
+	response, err := NewRequestTester().TestRequest(context.Background(), req, &models.ValidationConfig{FollowRedirect: false})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.StatusCode != http.StatusFound || len(response.Redirects) != 0 {
+		t.Fatalf("expected the 302 itself, got %d with %d hops", response.StatusCode, len(response.Redirects))
+	}
+	if response.Headers["Location"] != "/login?next=/protected" {
+		t.Fatalf("expected Location header, got %q", response.Headers["Location"])
+	}
+}
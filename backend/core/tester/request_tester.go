@@ -2,6 +2,9 @@ package tester
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"RequestProbe/backend/core/parser"
 	"RequestProbe/backend/core/validator"
 	"RequestProbe/backend/models"
 
@@ -18,56 +22,73 @@ import (
 )
 
 // RequestTester 请求测试器
+//
+// 测试器本身只保存默认配置，每次测试都会创建独立的请求会话，可被多个运行并发使用。
 type RequestTester struct {
-	client    *http.Client
+	mu        sync.RWMutex
+	timeout   time.Duration            // 默认请求超时（验证配置未指定时使用）
+	proxy     *url.URL                 // 默认代理
 	Validator *validator.SafeValidator // 导出字段
 }
 
 // NewRequestTester 创建请求测试器
 func NewRequestTester() *RequestTester {
 	return &RequestTester{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		timeout:   30 * time.Second,
 		Validator: validator.NewSafeValidator(),
 	}
 }
 
-// SetTimeout 设置请求超时时间
+// SetTimeout 设置默认请求超时时间（只影响之后开始的测试）
 func (t *RequestTester) SetTimeout(timeout time.Duration) {
-	t.client.Timeout = timeout
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.timeout = timeout
 }
 
-// SetProxy 设置代理
+// SetProxy 设置默认代理（只影响之后开始的测试，验证配置中的网络代理优先）
 func (t *RequestTester) SetProxy(proxyURL string) error {
 	if proxyURL == "" {
-		t.client.Transport = nil
+		t.mu.Lock()
+		t.proxy = nil
+		t.mu.Unlock()
 		return nil
 	}
 
-	proxy, err := url.Parse(proxyURL)
+	proxy, err := parseProxyURL(proxyURL)
 	if err != nil {
-		return fmt.Errorf("无效的代理URL: %v", err)
+		return err
 	}
 
-	transport := &http.Transport{
-		Proxy: http.ProxyURL(proxy),
-	}
-	t.client.Transport = transport
+	t.mu.Lock()
+	t.proxy = proxy
+	t.mu.Unlock()
 	return nil
 }
 
 // TestRequest 测试单个请求
-func (t *RequestTester) TestRequest(req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
-	// 创建HTTP请求
-	httpReq, err := t.createHTTPRequest(req)
+func (t *RequestTester) TestRequest(ctx context.Context, req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
+	session, err := t.newSession("", config)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	return t.sendRequest(ctx, session, req, config)
+}
+
+// sendRequest 使用指定会话发送请求
+func (t *RequestTester) sendRequest(ctx context.Context, session *requestSession, req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
+	// 创建HTTP请求（挂载重定向与已发送请求头记录器）
+	ctx, redirects := withRedirectRecorder(ctx)
+	ctx, sentHeaders := withSentHeaderRecorder(ctx)
+	httpReq, err := t.createHTTPRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("创建HTTP请求失败: %v", err)
 	}
 
 	// 执行请求
 	start := time.Now()
-	resp, err := t.client.Do(httpReq)
+	resp, err := session.client.Do(httpReq)
 	duration := time.Since(start)
 
 	if err != nil {
@@ -75,12 +96,19 @@ func (t *RequestTester) TestRequest(req *models.ParsedRequest, config *models.Va
 	}
 	defer resp.Body.Close()
 
-	// 读取响应体
-	body, err := io.ReadAll(resp.Body)
+	if err := checkNegotiatedProtocol(session.network, resp); err != nil {
+		return nil, err
+	}
+
+	// 读取响应体（线上传输的字节）
+	wireBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应体失败: %v", err)
 	}
 
+	// 按 Content-Encoding 解码，解码失败时保留已解码的部分并记录错误
+	body, contentEncodings, decodeErr := decodeContentEncoding(wireBody, resp.Header.Values("Content-Encoding"))
+
 	// 自动检测并转换编码
 	decodedBody, detectedEncoding := t.autoDetectAndDecodeResponse(body, resp.Header.Get("Content-Type"))
 	if decodedBody == "" {
@@ -95,10 +123,25 @@ func (t *RequestTester) TestRequest(req *models.ParsedRequest, config *models.Va
 		Cookies:          make([]models.ResponseCookie, 0),
 		URL:              resp.Request.URL.String(),
 		Duration:         duration,
-		ContentLength:    int64(len(body)),         // 原始字节长度
+		ContentLength:    int64(len(body)),         // 内容解码后的字节长度
 		CharacterCount:   len([]rune(decodedBody)), // 解码后字符长度
-		RawBody:          body,                     // 保存原始字节数据
+		RawBody:          body,                     // 保存内容解码后的字节数据
 		DetectedEncoding: detectedEncoding,         // 保存检测到的编码
+		WireBody:         wireBody,
+		WireLength:       int64(len(wireBody)),
+		ContentEncodings: contentEncodings,
+		Redirects:        redirects.list(),
+		Protocol:         resp.Proto,
+		SentHeaders:      sentHeaders.list(),
+	}
+	if !session.network.RawHTTP1 {
+		responseData.SentHeaders = markImplicitHeaders(responseData.SentHeaders, req)
+	}
+	if resp.TLS != nil {
+		responseData.TLSVersion = tls.VersionName(resp.TLS.Version)
+	}
+	if decodeErr != nil {
+		responseData.DecodeError = decodeErr.Error()
 	}
 
 	// 转换响应头
@@ -130,18 +173,18 @@ func (t *RequestTester) ValidateResponse(response *models.ResponseData, expressi
 	return t.Validator.EvaluateExpression(expression, response)
 }
 
-// ValidateResponseWithConfig 使用新配置验证响应
-func (t *RequestTester) ValidateResponseWithConfig(response *models.ResponseData, config *models.ValidationConfig) (bool, error) {
+// ValidateResponseWithConfig 使用新配置验证响应，返回各规则的判定明细
+func (t *RequestTester) ValidateResponseWithConfig(response *models.ResponseData, config *models.ValidationConfig) (*models.ValidationVerdict, error) {
 	return t.Validator.EvaluateConfig(config, response)
 }
 
 // TestFieldNecessity 测试字段必要性（带重试机制）
-func (t *RequestTester) TestFieldNecessity(originalReq *models.ParsedRequest, fieldName, fieldType string, config *models.ValidationConfig) (*models.TestResult, error) {
+func (t *RequestTester) TestFieldNecessity(ctx context.Context, originalReq *models.ParsedRequest, fieldName, fieldType string, config *models.ValidationConfig) (*models.TestResult, error) {
 	// 创建测试请求（移除指定字段）
 	testReq := t.createTestRequest(originalReq, fieldName, fieldType)
 
 	// 执行测试请求（带重试）
-	response, err := t.TestRequestWithRetry(testReq, config)
+	response, err := t.TestRequestWithRetry(ctx, testReq, config)
 	if err != nil {
 		return &models.TestResult{
 			FieldName:  fieldName,
@@ -153,7 +196,7 @@ func (t *RequestTester) TestFieldNecessity(originalReq *models.ParsedRequest, fi
 	}
 
 	// 使用新的验证配置
-	passed, err := t.ValidateResponseWithConfig(response, config)
+	verdict, err := t.ValidateResponseWithConfig(response, config)
 	if err != nil {
 		return &models.TestResult{
 			FieldName:   fieldName,
@@ -166,6 +209,7 @@ func (t *RequestTester) TestFieldNecessity(originalReq *models.ParsedRequest, fi
 		}, nil
 	}
 
+	passed := verdict.Passed
 	return &models.TestResult{
 		FieldName:   fieldName,
 		FieldType:   fieldType,
@@ -176,41 +220,68 @@ func (t *RequestTester) TestFieldNecessity(originalReq *models.ParsedRequest, fi
 	}, nil
 }
 
-// TestRequestWithRetry 带重试机制的请求测试
-func (t *RequestTester) TestRequestWithRetry(req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
-	maxRetries := config.MaxRetries
-	if maxRetries <= 0 {
-		maxRetries = 3 // 默认重试3次
+// TestRequestWithRetry 带重试机制的请求测试（context 结束时立即停止重试）
+func (t *RequestTester) TestRequestWithRetry(ctx context.Context, req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
+	session, err := t.newSession("", config)
+	if err != nil {
+		return nil, err
 	}
+	defer session.close()
+	return t.sendWithRetry(ctx, session, req, config)
+}
 
-	var lastErr error
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		response, err := t.TestRequest(req, config)
-		if err == nil {
-			return response, nil
+// sendWithRetry 使用指定会话发送请求，按重试策略重试
+func (t *RequestTester) sendWithRetry(ctx context.Context, session *requestSession, req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
+	response, _, err := t.sendCountingRetries(ctx, session, req, config)
+	return response, err
+}
+
+// sendCountingRetries 与 sendWithRetry 相同，额外返回实际重试的次数
+//
+// 请求错误与可重试状态码都会重试；重试耗尽仍为可重试状态码时返回最后的响应和
+// retryExhaustedError。主机已熔断时不发送请求，直接返回 retryExhaustedError。
+func (t *RequestTester) sendCountingRetries(ctx context.Context, session *requestSession, req *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, int, error) {
+	schedule := newRetrySchedule(config)
+	host := requestHost(req.URL)
+
+	for attempt := 0; ; attempt++ {
+		if err := session.breakers.allow(host, schedule.breaker); err != nil {
+			return nil, attempt, err
 		}
 
-		lastErr = err
+		response, err := t.sendRequest(ctx, session, req, config)
+		if ctx.Err() != nil {
+			return nil, attempt, ctx.Err()
+		}
+		retryable := err != nil || schedule.retryableStatus(response.StatusCode)
+		session.breakers.report(host, schedule.breaker, !retryable)
+		if !retryable {
+			return response, attempt, nil
+		}
 
-		// 如果不是最后一次尝试，等待一段时间再重试
-		if attempt < maxRetries {
-			// 指数退避：100ms, 200ms, 400ms...
-			waitTime := time.Duration(100*(1<<attempt)) * time.Millisecond
-			time.Sleep(waitTime)
+		if attempt >= schedule.maxRetries {
+			if err != nil {
+				return nil, attempt, fmt.Errorf("重试 %d 次后仍然失败: %v", attempt, err)
+			}
+			return response, attempt, &retryExhaustedError{fmt.Sprintf("重试 %d 次后仍然返回状态码 %d", attempt, response.StatusCode)}
 		}
-	}
 
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %v", maxRetries, lastErr)
+		if err := sleepContext(ctx, schedule.delay(attempt, response)); err != nil {
+			return nil, attempt, err
+		}
+	}
 }
 
 // createHTTPRequest 创建HTTP请求
-func (t *RequestTester) createHTTPRequest(req *models.ParsedRequest) (*http.Request, error) {
+func (t *RequestTester) createHTTPRequest(ctx context.Context, req *models.ParsedRequest) (*http.Request, error) {
 	var body io.Reader
 	if req.Body != "" {
 		body = bytes.NewBufferString(req.Body)
 	}
 
-	httpReq, err := http.NewRequest(req.Method, req.URL, body)
+	// 原始写入模式按此顺序与大小写写出请求头
+	ctx = withHeaderOrder(ctx, req.OrderedHeaderNames())
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, req.URL, body)
 	if err != nil {
 		return nil, err
 	}
@@ -224,11 +295,17 @@ func (t *RequestTester) createHTTPRequest(req *models.ParsedRequest) (*http.Requ
 		if strings.ToLower(key) == "user-agent" && value == "" {
 			continue // 跳过，不设置User-Agent header
 		}
-		httpReq.Header.Set(key, value)
+		// 解析出Cookie字段时以Cookies为准，避免Cookie头与逐个添加的Cookie重复
+		if strings.ToLower(key) == "cookie" && len(req.Cookies) > 0 {
+			continue
+		}
+		// 重复的请求头逐行发送
+		httpReq.Header[http.CanonicalHeaderKey(key)] = append([]string(nil), req.HeaderValues(key)...)
 	}
 
-	// 设置Cookies
-	for name, value := range req.Cookies {
+	// 设置Cookies（按原始顺序）
+	for _, name := range req.OrderedCookieNames() {
+		value := req.Cookies[name]
 		cookie := &http.Cookie{
 			Name:  name,
 			Value: value,
@@ -250,6 +327,10 @@ func (t *RequestTester) createTestRequest(original *models.ParsedRequest, fieldN
 		Body:        original.Body,
 		QueryParams: make(map[string]string),
 		ContentType: original.ContentType,
+		HeaderOrder: original.HeaderOrder,
+		CookieOrder: original.CookieOrder,
+
+		RepeatedHeaders: original.RepeatedHeaders,
 	}
 
 	// 拷贝Headers（除了要测试的字段）
@@ -276,29 +357,57 @@ func (t *RequestTester) createTestRequest(original *models.ParsedRequest, fieldN
 	return testReq
 }
 
-// BatchTestFieldNecessity 批量测试字段必要性（累积移除算法）
-func (t *RequestTester) BatchTestFieldNecessity(req *models.ParsedRequest, config *models.ValidationConfig, progressCallback func(*models.TestProgress)) (*models.BatchTestResult, error) {
+// BatchTestFieldNecessity 批量测试字段必要性（按配置的最小化策略）
+//
+// 每次运行使用独立的请求会话，可与其他运行并发执行。ctx 被取消时停止发送新的请求，
+// 返回只包含已完成字段的部分结果（Canceled 为 true），未测试的字段在简化请求中原样保留。
+func (t *RequestTester) BatchTestFieldNecessity(ctx context.Context, req *models.ParsedRequest, config *models.ValidationConfig, opts RunOptions) (*models.BatchTestResult, error) {
 	start := time.Now()
+	progressCallback := opts.Progress
+
+	session, err := t.newSession(opts.RunID, config)
+	if err != nil {
+		return nil, err
+	}
+	defer session.close()
+	session.transcript = newTranscriptRecorder(session.id, config.Transcript, opts.Transcript)
 
 	result := &models.BatchTestResult{
 		OriginalRequest: req,
 		HeaderResults:   []models.TestResult{},
 		CookieResults:   []models.TestResult{},
+		QueryResults:    []models.TestResult{},
+		BodyResults:     []models.TestResult{},
+		Strategy:        normalizeStrategy(config.Minimization.Strategy),
+		RunID:           session.id,
 	}
+	// 任何返回路径都附上运行记录
+	defer func() { result.Transcript = session.transcript.list() }()
 
-	// 计算总测试数
-	totalTests := len(req.Headers) + len(req.Cookies) + 1 // +1 for original request test
+	// 计算总测试数（ddmin 与复测阶段的实际请求数不固定，按此估算进度）
+	totalTests := len(req.Headers) + len(req.Cookies) + len(originalQueryParams(req)) + len(testableBodyFields(req, config)) + 1 // +1 for original request test
 	result.TotalTests = totalTests
-	currentStep := 0
+
+	run := &fieldTestRun{
+		ctx:        ctx,
+		control:    opts.Control,
+		session:    session,
+		tester:     t,
+		original:   req,
+		config:     config,
+		totalSteps: totalTests,
+	}
 
 	// 更新进度
-	updateProgress := func(message string) {
+	run.updateProgress = func(message string) {
+		run.step = message
 		if progressCallback != nil {
 			progress := &models.TestProgress{
+				RunID:          session.id,
 				CurrentStep:    message,
 				TotalSteps:     totalTests,
-				CompletedSteps: currentStep,
-				Progress:       float64(currentStep) / float64(totalTests) * 100,
+				CompletedSteps: run.currentStep,
+				Progress:       float64(run.currentStep) / float64(totalTests) * 100,
 				Message:        message,
 			}
 			progressCallback(progress)
@@ -306,13 +415,15 @@ func (t *RequestTester) BatchTestFieldNecessity(req *models.ParsedRequest, confi
 	}
 
 	// 更新进度并发送字段测试结果
-	updateProgressWithResult := func(message string, fieldResult *models.TestResult) {
+	run.updateProgressWithResult = func(message string, fieldResult *models.TestResult) {
+		run.step = message
 		if progressCallback != nil {
 			progress := &models.TestProgress{
+				RunID:          session.id,
 				CurrentStep:    message,
 				TotalSteps:     totalTests,
-				CompletedSteps: currentStep,
-				Progress:       float64(currentStep) / float64(totalTests) * 100,
+				CompletedSteps: run.currentStep,
+				Progress:       float64(run.currentStep) / float64(totalTests) * 100,
 				Message:        message,
 				FieldResult:    fieldResult,
 			}
@@ -321,9 +432,18 @@ func (t *RequestTester) BatchTestFieldNecessity(req *models.ParsedRequest, confi
 	}
 
 	// 首先测试原始请求
-	updateProgress("测试原始请求...")
-	originalResponse, err := t.TestRequestWithRetry(req, config)
+	run.updateProgress("测试原始请求...")
+	phaseStart := time.Now()
+	originalAttempt := transcriptAttempt{step: run.step, started: time.Now(), request: req}
+	originalResponse, retries, err := t.sendCountingRetries(ctx, session, req, config)
+	originalAttempt.response, originalAttempt.retries = originalResponse, retries
+	result.PhaseCosts = append(result.PhaseCosts, models.PhaseCost{Phase: models.PhaseOriginal, Requests: 1, Duration: time.Since(phaseStart)})
+	if ctx.Err() != nil {
+		return t.finishCanceledRun(run, result, start), nil
+	}
 	if err != nil {
+		originalAttempt.result = &models.SingleRequestResult{Success: false, Error: err.Error()}
+		session.transcript.record(originalAttempt)
 		result.OriginalPassed = false
 		result.OriginalError = err.Error()
 		// 提供更详细的错误信息
@@ -331,24 +451,90 @@ func (t *RequestTester) BatchTestFieldNecessity(req *models.ParsedRequest, confi
 		return result, fmt.Errorf(detailedError)
 	}
 
-	// 使用新的验证配置验证原始请求
-	passed, err := t.ValidateResponseWithConfig(originalResponse, config)
-	if err != nil {
-		result.OriginalPassed = false
-		result.OriginalError = fmt.Sprintf("原始请求验证失败: %v", err)
-		return result, err
+	if config.Baseline.Enabled && config.Baseline.Reference != nil {
+		// 基线模式（指定参照响应）：原始请求的响应也必须与参照相似
+		run.baseline = config.Baseline.Reference
+		originalAttempt.result = t.judgeResponse(originalResponse, config, run.baseline)
+		session.transcript.record(originalAttempt)
+		result.OriginalPassed = originalAttempt.result.Success
+		if !result.OriginalPassed {
+			result.OriginalError = "原始请求的响应与参照响应不一致：" + originalAttempt.result.Note
+			return result, fmt.Errorf("%s", result.OriginalError)
+		}
+	} else if config.Baseline.Enabled {
+		// 基线模式：原始响应即为参照，后续请求按相似度判定
+		run.baseline = originalResponse
+		result.OriginalPassed = true
+		originalAttempt.result = &models.SingleRequestResult{Success: true, Note: "基线响应"}
+		session.transcript.record(originalAttempt)
+	} else {
+		// 使用新的验证配置验证原始请求
+		verdict, err := t.ValidateResponseWithConfig(originalResponse, config)
+		if err != nil {
+			originalAttempt.result = &models.SingleRequestResult{Success: false, Error: err.Error()}
+			session.transcript.record(originalAttempt)
+			result.OriginalPassed = false
+			result.OriginalError = fmt.Sprintf("原始请求验证失败: %v", err)
+			return result, err
+		}
+		originalAttempt.result = &models.SingleRequestResult{Success: verdict.Passed, Verdict: verdict}
+		session.transcript.record(originalAttempt)
+
+		result.OriginalVerdict = verdict
+		result.OriginalPassed = verdict.Passed
+		if !verdict.Passed {
+			result.OriginalError = "原始请求未通过验证条件"
+			return result, fmt.Errorf("原始请求未通过验证，无法继续测试")
+		}
+	}
+
+	// 重复发送原始请求，确认验证条件本身稳定
+	if run.config.Trials.Preflight > 0 {
+		phaseStart = time.Now()
+		preflight := t.preflightOriginal(run)
+		result.Preflight = preflight.Trials
+		result.PhaseCosts = append(result.PhaseCosts, models.PhaseCost{Phase: models.PhasePreflight, Requests: run.requests, Duration: time.Since(phaseStart)})
+		if run.stopped() {
+			return t.finishCanceledRun(run, result, start), nil
+		}
+		// 预检只要出现过不一致（哪怕多数通过）就说明验证条件不可靠，继续测试会把抖动误判为字段必需
+		if !preflight.Success || preflight.Trials.Flipped {
+			result.OriginalPassed = false
+			result.OriginalError = fmt.Sprintf("原始请求预检未通过（%d/%d 次通过），验证条件或目标接口不稳定", preflight.Trials.Passed, preflight.Trials.Trials)
+			return result, fmt.Errorf("%s", result.OriginalError)
+		}
 	}
 
-	result.OriginalPassed = passed
-	if !passed {
-		result.OriginalError = "原始请求未通过验证条件"
-		return result, fmt.Errorf("原始请求未通过验证，无法继续测试")
+	run.currentStep++
+	result.FieldOrder = orderedFieldRefs(t.collectCandidateFields(req, config))
+
+	// 按策略测试字段
+	var cumulativeResults *models.TestResults
+	var legacyResults *legacyTestResults
+	phaseStart = time.Now()
+	requestsBefore := run.requests
+	if result.Strategy == models.StrategyDDMin {
+		cumulativeResults, legacyResults = t.testFieldsWithDDMin(run)
+	} else {
+		cumulativeResults, legacyResults = t.testFieldsWithCumulativeRemoval(run)
 	}
+	result.PhaseCosts = append(result.PhaseCosts, models.PhaseCost{Phase: result.Strategy, Requests: run.requests - requestsBefore, Duration: time.Since(phaseStart)})
 
-	currentStep++
+	// 复测最小集合并逐一检查 1-最小性
+	if config.Minimization.Verify && !run.stopped() {
+		phaseStart = time.Now()
+		requestsBefore = run.requests
+		result.MinimalityVerified = t.verifyMinimality(run, cumulativeResults, legacyResults)
+		result.PhaseCosts = append(result.PhaseCosts, models.PhaseCost{Phase: models.PhaseVerify, Requests: run.requests - requestsBefore, Duration: time.Since(phaseStart)})
+	}
 
-	// 使用累积移除算法测试字段
-	cumulativeResults, legacyResults := t.testFieldsWithCumulativeRemoval(req, config, updateProgress, updateProgressWithResult, &currentStep)
+	// 探测必需字段是只检查存在、检查格式还是校验具体值
+	if config.ValueProbe.Enabled && !run.stopped() {
+		phaseStart = time.Now()
+		requestsBefore = run.requests
+		t.probeValueSensitivity(run, cumulativeResults)
+		result.PhaseCosts = append(result.PhaseCosts, models.PhaseCost{Phase: models.PhaseValueProbe, Requests: run.requests - requestsBefore, Duration: time.Since(phaseStart)})
+	}
 
 	// 设置累积测试结果
 	result.CumulativeResults = cumulativeResults
@@ -356,235 +542,212 @@ func (t *RequestTester) BatchTestFieldNecessity(req *models.ParsedRequest, confi
 	// 转换为传统格式以保持兼容性
 	result.HeaderResults = legacyResults.HeaderResults
 	result.CookieResults = legacyResults.CookieResults
+	result.QueryResults = legacyResults.QueryResults
+	result.BodyResults = legacyResults.BodyResults
 	result.PassedTests = legacyResults.PassedTests
 
 	// 生成简化请求
 	result.SimplifiedRequest = t.generateSimplifiedRequestFromCumulative(req, cumulativeResults)
-	result.SimplifiedCode = t.generateSimplifiedPythonCode(result.SimplifiedRequest)
+	result.SimplifiedCode = parser.GeneratePythonCode(result.SimplifiedRequest)
 	result.TestDuration = time.Since(start)
 
-	updateProgress("测试完成")
-	return result, nil
-}
-
-// generateSimplifiedRequest 生成简化请求
-func (t *RequestTester) generateSimplifiedRequest(original *models.ParsedRequest, result *models.BatchTestResult) *models.ParsedRequest {
-	simplified := &models.ParsedRequest{
-		Method:      original.Method,
-		URL:         original.URL,
-		Headers:     make(map[string]string),
-		Cookies:     make(map[string]string),
-		Body:        original.Body,
-		QueryParams: make(map[string]string),
-		ContentType: original.ContentType,
-	}
-
-	// 只保留必需的Headers
-	for _, headerResult := range result.HeaderResults {
-		if headerResult.IsRequired {
-			if value, exists := original.Headers[headerResult.FieldName]; exists {
-				simplified.Headers[headerResult.FieldName] = value
-			}
-		}
+	if run.stopped() {
+		result.Canceled = true
+		run.updateProgress("测试已取消")
+		return result, nil
 	}
 
-	// 只保留必需的Cookies
-	for _, cookieResult := range result.CookieResults {
-		if cookieResult.IsRequired {
-			if value, exists := original.Cookies[cookieResult.FieldName]; exists {
-				simplified.Cookies[cookieResult.FieldName] = value
-			}
-		}
-	}
-
-	// 保留所有查询参数
-	for key, value := range original.QueryParams {
-		simplified.QueryParams[key] = value
-	}
-
-	return simplified
+	run.updateProgress("测试完成")
+	return result, nil
 }
 
-// generateSimplifiedPythonCode 生成简化的Python代码
-func (t *RequestTester) generateSimplifiedPythonCode(req *models.ParsedRequest) string {
-	var code strings.Builder
-
-	code.WriteString("import requests\n\n")
+// finishCanceledRun 在开始测试字段之前被取消时，返回不包含字段结果的部分结果
+func (t *RequestTester) finishCanceledRun(run *fieldTestRun, result *models.BatchTestResult, start time.Time) *models.BatchTestResult {
+	result.Canceled = true
+	result.OriginalError = "测试已取消"
+	result.SimplifiedRequest = t.generateSimplifiedRequestFromCumulative(run.original, newTestResults())
+	result.SimplifiedCode = parser.GeneratePythonCode(result.SimplifiedRequest)
+	result.TestDuration = time.Since(start)
+	run.updateProgress("测试已取消")
+	return result
+}
 
-	// Headers (只包含必需的)
-	if len(req.Headers) > 0 {
-		code.WriteString("headers = {\n")
-		for key, value := range req.Headers {
-			if strings.ToLower(key) != "cookie" {
-				code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
-			}
-		}
-		code.WriteString("}\n")
-	}
+// fieldTestRun 一次字段必要性测试的运行状态
+type fieldTestRun struct {
+	ctx      context.Context
+	control  *RunControl
+	session  *requestSession
+	tester   *RequestTester
+	original *models.ParsedRequest
+	config   *models.ValidationConfig
+	baseline *models.ResponseData // 基线模式下的参照响应
+
+	updateProgress           func(string)
+	updateProgressWithResult func(string, *models.TestResult)
+	step                     string // 最近一次进度消息（写入运行记录）
+	currentStep              int
+	totalSteps               int
+	requests                 int // 已发送的测试请求数
+}
 
-	// Cookies (只包含必需的)
-	if len(req.Cookies) > 0 {
-		code.WriteString("cookies = {\n")
-		for key, value := range req.Cookies {
-			code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
-		}
-		code.WriteString("}\n")
+// execute 发送测试请求，启用多次试验时按投票结果判定
+func (r *fieldTestRun) execute(request *models.ParsedRequest) *models.SingleRequestResult {
+	count := r.config.Trials.Count
+	if count <= 1 {
+		return r.executeOnce(request)
 	}
 
-	// 解析URL和参数
-	baseURL, queryParams := t.parseURLAndParams(req.URL)
-	code.WriteString(fmt.Sprintf("url = \"%s\"\n", baseURL))
-
-	// 查询参数 (只包含必需的)
-	if len(queryParams) > 0 {
-		code.WriteString("params = {\n")
-		for key, value := range queryParams {
-			code.WriteString(fmt.Sprintf("    \"%s\": \"%s\",\n", key, value))
-		}
-		code.WriteString("}\n")
+	results := make([]*models.SingleRequestResult, 0, count)
+	for i := 0; i < count; i++ {
+		results = append(results, r.executeOnce(request))
 	}
+	return voteTrials(results, r.config.Trials.Voting)
+}
 
-	// 请求体
-	var dataParam string
-	if req.Body != "" {
-		if strings.HasPrefix(strings.TrimSpace(req.Body), "{") || strings.HasPrefix(strings.TrimSpace(req.Body), "[") {
-			code.WriteString(fmt.Sprintf("data = %s\n", req.Body))
-			dataParam = "json=data"
-		} else {
-			code.WriteString(fmt.Sprintf("data = \"%s\"\n", req.Body))
-			dataParam = "data=data"
-		}
+// executeOnce 发送一次测试请求并计数（暂停期间在此等待）
+func (r *fieldTestRun) executeOnce(request *models.ParsedRequest) *models.SingleRequestResult {
+	if err := r.control.wait(r.ctx); err != nil {
+		return &models.SingleRequestResult{Success: false, Error: err.Error()}
 	}
+	r.requests++
+	return r.tester.executeRequest(r.ctx, r.session, request, r.config, r.baseline, r.step)
+}
 
-	// 构建请求调用
-	code.WriteString(fmt.Sprintf("response = requests.%s(url", strings.ToLower(req.Method)))
+// stopped 运行是否已被取消
+func (r *fieldTestRun) stopped() bool {
+	return r.ctx.Err() != nil
+}
 
-	if len(req.Headers) > 0 {
-		code.WriteString(", headers=headers")
+// advance 推进进度（请求数不固定的阶段使用，完成前不超过总步数）
+func (r *fieldTestRun) advance() {
+	if r.currentStep < r.totalSteps-1 {
+		r.currentStep++
 	}
+}
 
-	if len(req.Cookies) > 0 {
-		code.WriteString(", cookies=cookies")
-	}
+// legacyTestResults 传统格式的测试结果（保持兼容性）
+type legacyTestResults struct {
+	HeaderResults []models.TestResult
+	CookieResults []models.TestResult
+	QueryResults  []models.TestResult
+	BodyResults   []models.TestResult
+	PassedTests   int
+}
 
-	if len(queryParams) > 0 {
-		code.WriteString(", params=params")
+// newLegacyTestResults 创建空的传统格式结果
+func newLegacyTestResults() *legacyTestResults {
+	return &legacyTestResults{
+		HeaderResults: []models.TestResult{},
+		CookieResults: []models.TestResult{},
+		QueryResults:  []models.TestResult{},
+		BodyResults:   []models.TestResult{},
 	}
+}
 
-	if dataParam != "" {
-		code.WriteString(fmt.Sprintf(", %s", dataParam))
+// newTestResults 创建空的累积测试结果
+func newTestResults() *models.TestResults {
+	return &models.TestResults{
+		Headers:      make(map[string]*models.FieldTestResult),
+		Cookies:      make(map[string]*models.FieldTestResult),
+		QueryResults: make(map[string]*models.FieldTestResult),
+		BodyResults:  make(map[string]*models.FieldTestResult),
 	}
+}
 
-	code.WriteString(")\n\n")
-	code.WriteString("print(response.text)\n")
-	code.WriteString("print(response)")
+// newFieldTestResult 根据测试结果创建字段结论（试验结果翻转时标记为不稳定，不强行判定）
+func newFieldTestResult(required bool, value string, testResult *models.SingleRequestResult) *models.FieldTestResult {
+	result := &models.FieldTestResult{
+		Required:   required,
+		Value:      value,
+		TestResult: testResult,
+		Similarity: testResult.Similarity,
+		Trials:     testResult.Trials,
+		Retries:    testResult.Retries,
+		Status:     models.FieldStatusOptional,
+	}
+	switch {
+	case testResult.RetryExhausted:
+		// 限流或服务不可用导致无法判定，保守保留
+		result.Required = false
+		result.Unstable = true
+		result.Status = models.FieldStatusUnstable
+	case testResult.Trials != nil && testResult.Trials.Flipped:
+		result.Required = false
+		result.Unstable = true
+		result.Status = models.FieldStatusUnstable
+	case required:
+		result.Status = models.FieldStatusRequired
+	}
+	return result
+}
 
-	return code.String()
+// newLegacyTestResult 根据字段结论创建传统格式结果
+func newLegacyTestResult(fieldName, fieldType string, fieldResult *models.FieldTestResult) models.TestResult {
+	testResult := fieldResult.TestResult
+	legacyResult := models.TestResult{
+		FieldName:  fieldName,
+		FieldType:  fieldType,
+		IsRequired: fieldResult.Required,
+		Unstable:   fieldResult.Unstable,
+		TestPassed: testResult.Success,
+		ErrorMsg:   testResult.Error,
+	}
+	if testResult.ResponseInfo != nil {
+		legacyResult.StatusCode = testResult.ResponseInfo.StatusCode
+	}
+	return legacyResult
 }
 
-// parseURLAndParams 解析URL，分离基础URL和查询参数
-func (t *RequestTester) parseURLAndParams(fullURL string) (string, map[string]string) {
-	parts := strings.Split(fullURL, "?")
-	baseURL := parts[0]
-	queryParams := make(map[string]string)
+// newFullTestState 创建包含原始请求全部字段的测试状态
+//
+// 请求体字段：需要测试时状态中保存全部字段（含嵌套字段），只对需要测试的字段做移除。
+func newFullTestState(req *models.ParsedRequest, config *models.ValidationConfig) *models.CumulativeTestState {
+	state := &models.CumulativeTestState{
+		Headers:     make(map[string]string),
+		Cookies:     make(map[string]string),
+		QueryParams: originalQueryParams(req),
+	}
 
-	if len(parts) > 1 {
-		// 解析查询参数
-		paramPairs := strings.Split(parts[1], "&")
-		for _, pair := range paramPairs {
-			if keyValue := strings.Split(pair, "="); len(keyValue) == 2 {
-				queryParams[keyValue[0]] = keyValue[1]
-			}
+	if len(testableBodyFields(req, config)) > 0 {
+		state.BodyFields = make(map[string]string)
+		for _, field := range listBodyFields(req) {
+			state.BodyFields[field.Path] = field.Value
 		}
 	}
 
-	return baseURL, queryParams
-}
-
-// testFieldsConcurrently 并发测试字段
-func (t *RequestTester) testFieldsConcurrently(req *models.ParsedRequest, fields map[string]string, fieldType string, config *models.ValidationConfig, updateProgress func(string), currentStep *int) []models.TestResult {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	results := make([]models.TestResult, 0, len(fields))
-
-	// 限制并发数量，避免过多请求
-	semaphore := make(chan struct{}, 5) // 最多5个并发
-
-	for fieldName := range fields {
-		wg.Add(1)
-		go func(name string) {
-			defer wg.Done()
-
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// 更新进度
-			updateProgress(fmt.Sprintf("测试%s: %s", fieldType, name))
-
-			testResult, err := t.TestFieldNecessity(req, name, fieldType, config)
-			if err != nil {
-				testResult = &models.TestResult{
-					FieldName:  name,
-					FieldType:  fieldType,
-					IsRequired: true,
-					TestPassed: false,
-					ErrorMsg:   err.Error(),
-				}
-			}
-
-			// 线程安全地添加结果
-			mu.Lock()
-			results = append(results, *testResult)
-			*currentStep++
-			mu.Unlock()
-		}(fieldName)
+	for k, v := range req.Headers {
+		state.Headers[k] = v
 	}
-
-	wg.Wait()
-	return results
+	for k, v := range req.Cookies {
+		state.Cookies[k] = v
+	}
+	return state
 }
 
 // testFieldsWithCumulativeRemoval 使用累积移除算法测试字段
-func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.ParsedRequest, config *models.ValidationConfig, updateProgress func(string), updateProgressWithResult func(string, *models.TestResult), currentStep *int) (*models.TestResults, *struct {
-	HeaderResults []models.TestResult
-	CookieResults []models.TestResult
-	PassedTests   int
-}) {
-	// 创建累积测试状态
-	cumulativeState := &models.CumulativeTestState{
-		Headers: make(map[string]string),
-		Cookies: make(map[string]string),
-	}
+func (t *RequestTester) testFieldsWithCumulativeRemoval(run *fieldTestRun) (*models.TestResults, *legacyTestResults) {
+	originalReq, config := run.original, run.config
+	updateProgress, updateProgressWithResult := run.updateProgress, run.updateProgressWithResult
+	currentStep := &run.currentStep
 
-	// 深拷贝原始请求数据
-	for k, v := range originalReq.Headers {
-		cumulativeState.Headers[k] = v
-	}
-	for k, v := range originalReq.Cookies {
-		cumulativeState.Cookies[k] = v
+	// 创建累积测试状态
+	cumulativeState := newFullTestState(originalReq, config)
+	bodyFields := make(map[string]bodyField)
+	for _, field := range testableBodyFields(originalReq, config) {
+		bodyFields[field.Path] = field
 	}
 
 	// 创建结果结构
-	cumulativeResults := &models.TestResults{
-		Headers: make(map[string]*models.FieldTestResult),
-		Cookies: make(map[string]*models.FieldTestResult),
-	}
+	cumulativeResults := newTestResults()
 
 	// 用于兼容性的传统结果
-	legacyResults := &struct {
-		HeaderResults []models.TestResult
-		CookieResults []models.TestResult
-		PassedTests   int
-	}{
-		HeaderResults: []models.TestResult{},
-		CookieResults: []models.TestResult{},
-		PassedTests:   0,
-	}
+	legacyResults := newLegacyTestResults()
 
-	// 按原始顺序测试Headers（累积移除算法）
-	headerOrder := t.getOriginalHeaderOrder(originalReq)
-	for _, headerName := range headerOrder {
+	// 按测试顺序测试Headers（累积移除算法）
+	for _, headerName := range t.fieldTestOrder(originalReq, config, "header") {
+		if run.stopped() {
+			break
+		}
 		// 更新进度
 		updateProgress(fmt.Sprintf("测试Header: %s", headerName))
 
@@ -603,6 +766,7 @@ func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.Pars
 				Required:   true, // 强制标记为必需
 				Value:      removedValue,
 				TestResult: &models.SingleRequestResult{Success: true}, // 假设成功
+				Status:     models.FieldStatusRequired,
 			}
 
 			// 记录传统测试结果
@@ -635,37 +799,27 @@ func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.Pars
 		testRequest := t.buildRequestFromState(cumulativeState, originalReq)
 
 		// 执行测试
-		testResult := t.executeRequest(testRequest, config)
+		testResult := run.execute(testRequest)
+		if run.stopped() {
+			// 运行已取消：本次结果不可信，不记录
+			break
+		}
 
 		// 判断字段是否必需
 		isRequired := !testResult.Success
+		fieldResult := newFieldTestResult(isRequired, removedValue, testResult)
 
-		if isRequired {
-			// 字段是必需的，恢复到累积状态中
+		if fieldResult.Required || fieldResult.Unstable {
+			// 字段是必需的或结论不稳定，恢复到累积状态中，后续测试不能建立在未确认的移除之上
 			cumulativeState.Headers[headerName] = removedValue
-		} else if !isUserAgent {
-			// 如果字段不是必需的且不是User-Agent，则保持从累积状态中移除
-			// User-Agent已经设置为空字符串，保持这个状态
 		}
+		// 否则保持移除（User-Agent保持为空字符串）
 
 		// 记录累积测试结果
-		cumulativeResults.Headers[headerName] = &models.FieldTestResult{
-			Required:   isRequired,
-			Value:      removedValue,
-			TestResult: testResult,
-		}
+		cumulativeResults.Headers[headerName] = fieldResult
 
 		// 记录传统测试结果
-		legacyResult := models.TestResult{
-			FieldName:  headerName,
-			FieldType:  "header",
-			IsRequired: isRequired,
-			TestPassed: testResult.Success,
-			ErrorMsg:   testResult.Error,
-		}
-		if testResult.ResponseInfo != nil {
-			legacyResult.StatusCode = testResult.ResponseInfo.StatusCode
-		}
+		legacyResult := newLegacyTestResult(headerName, "header", fieldResult)
 		legacyResults.HeaderResults = append(legacyResults.HeaderResults, legacyResult)
 
 		if testResult.Success {
@@ -678,9 +832,11 @@ func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.Pars
 		updateProgressWithResult(fmt.Sprintf("完成Header: %s", headerName), &legacyResult)
 	}
 
-	// 按原始顺序测试Cookies（累积移除算法）
-	cookieOrder := t.getOriginalCookieOrder(originalReq)
-	for _, cookieName := range cookieOrder {
+	// 按测试顺序测试Cookies（累积移除算法）
+	for _, cookieName := range t.fieldTestOrder(originalReq, config, "cookie") {
+		if run.stopped() {
+			break
+		}
 		updateProgress(fmt.Sprintf("测试Cookie: %s", cookieName))
 
 		// 检查字段是否还存在于累积状态中
@@ -695,36 +851,134 @@ func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.Pars
 
 		// 构建测试请求（基于当前累积状态）
 		testRequest := t.buildRequestFromState(cumulativeState, originalReq)
-		testResult := t.executeRequest(testRequest, config)
+		testResult := run.execute(testRequest)
+		if run.stopped() {
+			// 运行已取消：本次结果不可信，不记录
+			break
+		}
 
 		// 判断字段是否必需
 		isRequired := !testResult.Success
+		fieldResult := newFieldTestResult(isRequired, removedValue, testResult)
 
-		if isRequired {
-			// 字段是必需的，恢复到累积状态中
+		if fieldResult.Required || fieldResult.Unstable {
+			// 字段是必需的或结论不稳定，恢复到累积状态中
 			cumulativeState.Cookies[cookieName] = removedValue
 		}
-		// 如果字段不是必需的，则保持从累积状态中移除
+		// 否则保持从累积状态中移除
 
 		// 记录累积测试结果
-		cumulativeResults.Cookies[cookieName] = &models.FieldTestResult{
-			Required:   isRequired,
-			Value:      removedValue,
-			TestResult: testResult,
+		cumulativeResults.Cookies[cookieName] = fieldResult
+
+		// 记录传统测试结果
+		legacyResult := newLegacyTestResult(cookieName, "cookie", fieldResult)
+		legacyResults.CookieResults = append(legacyResults.CookieResults, legacyResult)
+
+		if testResult.Success {
+			legacyResults.PassedTests++
+		}
+
+		*currentStep++
+
+		// 立即发送包含字段测试结果的进度更新
+		updateProgressWithResult(fmt.Sprintf("完成Cookie: %s", cookieName), &legacyResult)
+	}
+
+	// 按测试顺序（默认为URL中的顺序）测试查询参数（累积移除算法）
+	for _, paramName := range t.fieldTestOrder(originalReq, config, "query") {
+		if run.stopped() {
+			break
+		}
+		updateProgress(fmt.Sprintf("测试Query参数: %s", paramName))
+
+		// 检查字段是否还存在于累积状态中
+		removedValue, exists := cumulativeState.QueryParams[paramName]
+		if !exists {
+			continue
+		}
+
+		// 临时从累积状态中移除当前参数，并据此重建URL
+		delete(cumulativeState.QueryParams, paramName)
+
+		testRequest := t.buildRequestFromState(cumulativeState, originalReq)
+		testResult := run.execute(testRequest)
+		if run.stopped() {
+			// 运行已取消：本次结果不可信，不记录
+			break
+		}
+
+		// 判断参数是否必需
+		isRequired := !testResult.Success
+		fieldResult := newFieldTestResult(isRequired, removedValue, testResult)
+
+		if fieldResult.Required || fieldResult.Unstable {
+			// 参数是必需的或结论不稳定，恢复到累积状态中
+			cumulativeState.QueryParams[paramName] = removedValue
 		}
 
+		// 记录累积测试结果
+		cumulativeResults.QueryResults[paramName] = fieldResult
+
 		// 记录传统测试结果
-		legacyResult := models.TestResult{
-			FieldName:  cookieName,
-			FieldType:  "cookie",
-			IsRequired: isRequired,
-			TestPassed: testResult.Success,
-			ErrorMsg:   testResult.Error,
+		legacyResult := newLegacyTestResult(paramName, "query", fieldResult)
+		legacyResults.QueryResults = append(legacyResults.QueryResults, legacyResult)
+
+		if testResult.Success {
+			legacyResults.PassedTests++
 		}
-		if testResult.ResponseInfo != nil {
-			legacyResult.StatusCode = testResult.ResponseInfo.StatusCode
+
+		*currentStep++
+
+		// 立即发送包含字段测试结果的进度更新
+		updateProgressWithResult(fmt.Sprintf("完成Query参数: %s", paramName), &legacyResult)
+	}
+
+	// 按测试顺序（默认为请求体中的顺序，父字段先于子字段）测试请求体字段（累积移除算法）
+	for _, path := range t.fieldTestOrder(originalReq, config, "body") {
+		if run.stopped() {
+			break
 		}
-		legacyResults.CookieResults = append(legacyResults.CookieResults, legacyResult)
+		field := bodyFields[path]
+		updateProgress(fmt.Sprintf("测试Body字段: %s", field.Path))
+
+		// 字段或其父字段已在之前的测试中被移除，跳过
+		if _, exists := cumulativeState.BodyFields[field.Path]; !exists {
+			continue
+		}
+
+		// 临时移除当前字段及其子字段
+		removedFields := make(map[string]string)
+		for path, value := range cumulativeState.BodyFields {
+			if path == field.Path || strings.HasPrefix(path, field.Path+".") {
+				removedFields[path] = value
+			}
+		}
+		removeBodyFieldTree(cumulativeState.BodyFields, field.Path)
+
+		testRequest := t.buildRequestFromState(cumulativeState, originalReq)
+		testResult := run.execute(testRequest)
+		if run.stopped() {
+			// 运行已取消：本次结果不可信，不记录
+			break
+		}
+
+		// 判断字段是否必需
+		isRequired := !testResult.Success
+		fieldResult := newFieldTestResult(isRequired, field.Value, testResult)
+
+		if fieldResult.Required || fieldResult.Unstable {
+			// 字段是必需的或结论不稳定，恢复到累积状态中
+			for path, value := range removedFields {
+				cumulativeState.BodyFields[path] = value
+			}
+		}
+
+		// 记录累积测试结果
+		cumulativeResults.BodyResults[field.Path] = fieldResult
+
+		// 记录传统测试结果
+		legacyResult := newLegacyTestResult(field.Path, "body", fieldResult)
+		legacyResults.BodyResults = append(legacyResults.BodyResults, legacyResult)
 
 		if testResult.Success {
 			legacyResults.PassedTests++
@@ -733,28 +987,27 @@ func (t *RequestTester) testFieldsWithCumulativeRemoval(originalReq *models.Pars
 		*currentStep++
 
 		// 立即发送包含字段测试结果的进度更新
-		updateProgressWithResult(fmt.Sprintf("完成Cookie: %s", cookieName), &legacyResult)
+		updateProgressWithResult(fmt.Sprintf("完成Body字段: %s", field.Path), &legacyResult)
 	}
 
 	return cumulativeResults, legacyResults
 }
 
-// getOriginalHeaderOrder 获取原始Header顺序
-func (t *RequestTester) getOriginalHeaderOrder(req *models.ParsedRequest) []string {
-	order := make([]string, 0, len(req.Headers))
-	for headerName := range req.Headers {
-		order = append(order, headerName)
+// originalQueryParams 获取原始请求的查询参数（QueryParams 为空时从URL解析）
+func originalQueryParams(req *models.ParsedRequest) map[string]string {
+	params := make(map[string]string)
+	for k, v := range req.QueryParams {
+		params[k] = v
 	}
-	return order
-}
 
-// getOriginalCookieOrder 获取原始Cookie顺序
-func (t *RequestTester) getOriginalCookieOrder(req *models.ParsedRequest) []string {
-	order := make([]string, 0, len(req.Cookies))
-	for cookieName := range req.Cookies {
-		order = append(order, cookieName)
+	if parsedURL, err := url.Parse(req.URL); err == nil {
+		for key, values := range parsedURL.Query() {
+			if _, exists := params[key]; !exists && len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
 	}
-	return order
+	return params
 }
 
 // buildRequestFromState 从累积状态构建请求
@@ -767,6 +1020,10 @@ func (t *RequestTester) buildRequestFromState(state *models.CumulativeTestState,
 		Body:        original.Body,
 		QueryParams: make(map[string]string),
 		ContentType: original.ContentType,
+		HeaderOrder: original.HeaderOrder,
+		CookieOrder: original.CookieOrder,
+
+		RepeatedHeaders: original.RepeatedHeaders,
 	}
 
 	// 复制累积状态中的headers
@@ -779,116 +1036,85 @@ func (t *RequestTester) buildRequestFromState(state *models.CumulativeTestState,
 		testRequest.Cookies[k] = v
 	}
 
-	// 复制查询参数（保持不变）
-	for k, v := range original.QueryParams {
+	// 复制累积状态中的查询参数，并用其重建URL
+	for k, v := range state.QueryParams {
 		testRequest.QueryParams[k] = v
 	}
+	testRequest.URL = rebuildURLWithParams(original.URL, state.QueryParams)
+
+	// 按累积状态重建请求体
+	if state.BodyFields != nil {
+		testRequest.Body = rebuildBody(original, state.BodyFields)
+	}
 
 	return testRequest
 }
 
-// 全局测试计数器
-var testCounter int
-
-// executeRequest 执行请求并返回结果
-func (t *RequestTester) executeRequest(request *models.ParsedRequest, config *models.ValidationConfig) *models.SingleRequestResult {
-	// 增加测试计数器
-	testCounter++
+// executeRequest 执行请求并返回结果，同时写入运行记录
+//
+// baseline 不为空时按与基线响应的相似度判定，否则使用验证配置判定。
+func (t *RequestTester) executeRequest(ctx context.Context, session *requestSession, request *models.ParsedRequest, config *models.ValidationConfig, baseline *models.ResponseData, step string) *models.SingleRequestResult {
+	attempt := transcriptAttempt{step: step, started: time.Now(), request: request}
+	defer func() { session.transcript.record(attempt) }()
 
-	// 按照用户要求的格式打印日志
-	fmt.Printf("\n=========== 第%d次测试 ===========\n", testCounter)
-
-	// 打印headers
-	fmt.Printf("headers：{")
-	headerCount := 0
-	for name, value := range request.Headers {
-		if headerCount > 0 {
-			fmt.Printf(", ")
-		}
-		fmt.Printf("\"%s\": \"%s\"", name, value)
-		headerCount++
+	// 先检查请求能否创建，避免对无效请求重试
+	if _, err := t.createHTTPRequest(ctx, request); err != nil {
+		attempt.result = &models.SingleRequestResult{Success: false, Error: err.Error()}
+		return attempt.result
 	}
-	fmt.Printf("}\n")
 
-	// 打印cookies
-	fmt.Printf("cookies：{")
-	cookieCount := 0
-	for name, value := range request.Cookies {
-		if cookieCount > 0 {
-			fmt.Printf(", ")
-		}
-		fmt.Printf("\"%s\": \"%s\"", name, value)
-		cookieCount++
-	}
-	fmt.Printf("}\n")
-
-	// 创建HTTP请求以检查实际发送的headers
-	httpReq, err := t.createHTTPRequest(request)
+	// 发送HTTP请求
+	response, retries, err := t.sendCountingRetries(ctx, session, request, config)
+	attempt.response, attempt.retries = response, retries
 	if err != nil {
-		fmt.Printf("表达式求值：创建请求失败 - %s\n", err.Error())
-		fmt.Printf("返回包前100字符：无\n")
-		return &models.SingleRequestResult{
-			Success: false,
-			Error:   err.Error(),
+		var exhausted *retryExhaustedError
+		attempt.result = &models.SingleRequestResult{
+			Success:        false,
+			Error:          err.Error(),
+			Retries:        retries,
+			RetryExhausted: errors.As(err, &exhausted),
 		}
+		return attempt.result
 	}
 
-	// 打印实际发送的headers（包括Go自动添加的默认headers）
-	fmt.Printf("实际发送的headers：{")
-	actualHeaderCount := 0
-	for name, values := range httpReq.Header {
-		if actualHeaderCount > 0 {
-			fmt.Printf(", ")
-		}
-		fmt.Printf("\"%s\": \"%s\"", name, values[0])
-		actualHeaderCount++
-	}
-	fmt.Printf("}\n")
+	attempt.result = t.judgeResponse(response, config, baseline)
+	attempt.result.Retries = retries
+	return attempt.result
+}
 
-	// 额外检查：打印Go可能自动添加的headers
-	fmt.Printf("Go可能自动添加的headers：\n")
-	fmt.Printf("  Host: %s\n", httpReq.Host)
-	fmt.Printf("  URL: %s\n", httpReq.URL.String())
-	fmt.Printf("  Method: %s\n", httpReq.Method)
+// judgeResponse 判定响应：基线模式按相似度，否则按验证配置
+func (t *RequestTester) judgeResponse(response *models.ResponseData, config *models.ValidationConfig, baseline *models.ResponseData) *models.SingleRequestResult {
+	// 构建响应信息
+	responseInfo := &models.ResponseInfo{
+		StatusCode: response.StatusCode,
+		URL:        response.URL,
+		Headers:    response.Headers,
+	}
 
-	// 发送HTTP请求
-	response, err := t.TestRequestWithRetry(request, config)
-	if err != nil {
-		fmt.Printf("表达式求值：请求失败 - %s\n", err.Error())
-		fmt.Printf("返回包前100字符：无\n")
+	// 基线模式：按相似度判定
+	if baseline != nil {
+		similarity := compareWithBaseline(baseline, response, config.Baseline)
 		return &models.SingleRequestResult{
-			Success: false,
-			Error:   err.Error(),
+			Success:      similarity.Passed,
+			Note:         describeSimilarity(similarity),
+			ResponseInfo: responseInfo,
+			Similarity:   similarity,
 		}
 	}
 
 	// 执行验证
-	validationResult, err := t.ValidateResponseWithConfig(response, config)
+	verdict, err := t.ValidateResponseWithConfig(response, config)
 	if err != nil {
-		fmt.Printf("表达式求值：验证失败 - %s\n", err.Error())
-		fmt.Printf("返回包前100字符：%s\n", truncateString(response.Body, 100))
 		return &models.SingleRequestResult{
 			Success: false,
 			Error:   err.Error(),
 		}
 	}
 
-	// 打印表达式求值结果
-	fmt.Printf("表达式求值：%t\n", validationResult)
-
-	// 打印返回包前100字符
-	fmt.Printf("返回包前100字符：%s\n", truncateString(response.Body, 100))
-
-	// 构建响应信息
-	responseInfo := &models.ResponseInfo{
-		StatusCode: response.StatusCode,
-		URL:        response.URL,
-		Headers:    response.Headers,
-	}
-
 	return &models.SingleRequestResult{
-		Success:      validationResult,
+		Success:      verdict.Passed,
 		ResponseInfo: responseInfo,
+		Verdict:      verdict,
 	}
 }
 
@@ -902,48 +1128,58 @@ func (t *RequestTester) generateSimplifiedRequestFromCumulative(original *models
 		Body:        original.Body,
 		QueryParams: make(map[string]string),
 		ContentType: original.ContentType,
+		HeaderOrder: original.HeaderOrder,
+		CookieOrder: original.CookieOrder,
+
+		RepeatedHeaders: original.RepeatedHeaders,
 	}
 
-	// 只保留必需的Headers
-	for headerName, result := range results.Headers {
-		if result.Required {
-			if value, exists := original.Headers[headerName]; exists {
-				simplified.Headers[headerName] = value
-			}
+	// 只保留必需的Headers（结果不稳定或未测试的字段保守保留）
+	for headerName, value := range original.Headers {
+		if result, tested := results.Headers[headerName]; tested && !result.Required && !result.Unstable {
+			continue
 		}
+		simplified.Headers[headerName] = value
 	}
 
-	// 只保留必需的Cookies
-	for cookieName, result := range results.Cookies {
-		if result.Required {
-			if value, exists := original.Cookies[cookieName]; exists {
-				simplified.Cookies[cookieName] = value
-			}
+	// 只保留必需的Cookies（结果不稳定或未测试的字段保守保留）
+	for cookieName, value := range original.Cookies {
+		if result, tested := results.Cookies[cookieName]; tested && !result.Required && !result.Unstable {
+			continue
 		}
+		simplified.Cookies[cookieName] = value
 	}
 
-	// 保留所有查询参数
-	for key, value := range original.QueryParams {
+	// 只保留必需的查询参数（未参与测试的参数原样保留）
+	for key, value := range originalQueryParams(original) {
+		if result, tested := results.QueryResults[key]; tested && !result.Required && !result.Unstable {
+			continue
+		}
 		simplified.QueryParams[key] = value
 	}
+	simplified.URL = rebuildURLWithParams(original.URL, simplified.QueryParams)
 
-	return simplified
-}
-
-// truncateString 截断字符串到指定长度
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+	// 只保留必需的请求体字段
+	if len(results.BodyResults) > 0 {
+		keep := make(map[string]string)
+		for _, field := range listBodyFields(original) {
+			keep[field.Path] = field.Value
+		}
+		for path, result := range results.BodyResults {
+			if !result.Required && !result.Unstable {
+				removeBodyFieldTree(keep, path)
+			}
+		}
+		simplified.Body = rebuildBody(original, keep)
 	}
-	return s[:maxLen] + "..."
+
+	return simplified
 }
 
 // autoDetectAndDecodeResponse 自动检测编码并解码响应
 func (t *RequestTester) autoDetectAndDecodeResponse(body []byte, contentType string) (string, string) {
 	// 使用charset包自动检测编码
-	encoding, name, certain := charset.DetermineEncoding(body, contentType)
-
-	fmt.Printf("自动检测编码: %s (确定性: %v, Content-Type: %s)\n", name, certain, contentType)
+	encoding, name, _ := charset.DetermineEncoding(body, contentType)
 
 	// 如果检测到的编码不是UTF-8，进行转换
 	if name != "utf-8" && name != "" {
@@ -952,7 +1188,6 @@ func (t *RequestTester) autoDetectAndDecodeResponse(body []byte, contentType str
 
 		decoded, err := io.ReadAll(reader)
 		if err != nil {
-			fmt.Printf("编码转换失败: %v\n", err)
 			return string(body), name // 返回原始内容和检测到的编码名
 		}
 
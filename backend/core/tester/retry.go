@@ -0,0 +1,189 @@
+package tester
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+// 重试策略的默认值
+const (
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 30 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 30 * time.Second
+	legacyMaxRetries        = 3 // 未启用重试策略且 MaxRetries<=0 时的重试次数
+)
+
+// retryExhaustedError 重试耗尽仍为可重试状态码，或主机已熔断：请求结果无法用于判定字段
+type retryExhaustedError struct {
+	message string
+}
+
+func (e *retryExhaustedError) Error() string {
+	return e.message
+}
+
+// retrySchedule 按验证配置整理后的重试参数
+type retrySchedule struct {
+	maxRetries        int
+	retryStatuses     map[int]bool
+	respectRetryAfter bool
+	baseDelay         time.Duration
+	maxDelay          time.Duration
+	jitter            float64
+	breaker           models.CircuitBreakerConfig
+}
+
+// newRetrySchedule 根据验证配置生成重试参数（未启用重试策略时沿用旧规则）
+func newRetrySchedule(config *models.ValidationConfig) retrySchedule {
+	policy := config.RetryPolicy
+	if !policy.Enabled {
+		maxRetries := config.MaxRetries
+		if maxRetries <= 0 {
+			maxRetries = legacyMaxRetries
+		}
+		return retrySchedule{maxRetries: maxRetries, baseDelay: defaultRetryBaseDelay}
+	}
+
+	schedule := retrySchedule{
+		maxRetries:        max(policy.MaxRetries, 0),
+		retryStatuses:     make(map[int]bool),
+		respectRetryAfter: policy.RespectRetryAfter,
+		baseDelay:         policy.BaseDelay,
+		maxDelay:          policy.MaxDelay,
+		jitter:            min(max(policy.Jitter, 0), 1),
+		breaker:           policy.CircuitBreaker,
+	}
+	for _, status := range policy.RetryStatuses {
+		schedule.retryStatuses[status] = true
+	}
+	if schedule.baseDelay <= 0 {
+		schedule.baseDelay = defaultRetryBaseDelay
+	}
+	if schedule.maxDelay <= 0 {
+		schedule.maxDelay = defaultRetryMaxDelay
+	}
+	if schedule.breaker.FailureThreshold <= 0 {
+		schedule.breaker.FailureThreshold = defaultBreakerThreshold
+	}
+	if schedule.breaker.Cooldown <= 0 {
+		schedule.breaker.Cooldown = defaultBreakerCooldown
+	}
+	return schedule
+}
+
+// retryableStatus 状态码是否需要重试
+func (r retrySchedule) retryableStatus(statusCode int) bool {
+	return r.retryStatuses[statusCode]
+}
+
+// delay 计算第 attempt 次（从0开始）失败后的等待时间
+//
+// 响应带有 Retry-After 且启用时优先使用（不加抖动），否则按指数退避并加抖动；两者都不超过上限。
+func (r retrySchedule) delay(attempt int, response *models.ResponseData) time.Duration {
+	if r.respectRetryAfter && response != nil {
+		if wait, ok := parseRetryAfter(response.Headers["Retry-After"], time.Now()); ok {
+			return min(wait, r.maxDelay)
+		}
+	}
+
+	wait := r.baseDelay << min(attempt, 30)
+	if wait <= 0 || r.maxDelay > 0 && wait > r.maxDelay {
+		wait = r.maxDelay
+	}
+	if r.jitter > 0 {
+		wait -= time.Duration(float64(wait) * r.jitter * rand.Float64())
+	}
+	return wait
+}
+
+// parseRetryAfter 解析 Retry-After（秒数或 HTTP 日期）
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// circuitBreakers 一次运行内按主机统计连续失败的熔断器
+type circuitBreakers struct {
+	mu    sync.Mutex
+	hosts map[string]*breakerState
+}
+
+// breakerState 单个主机的熔断状态
+type breakerState struct {
+	failures  int       // 连续失败次数
+	openUntil time.Time // 熔断截止时间（之后放行试探请求，失败则再次熔断）
+}
+
+// newCircuitBreakers 创建熔断器
+func newCircuitBreakers() *circuitBreakers {
+	return &circuitBreakers{hosts: make(map[string]*breakerState)}
+}
+
+// allow 检查主机是否允许发送请求
+func (b *circuitBreakers) allow(host string, config models.CircuitBreakerConfig) error {
+	if b == nil || !config.Enabled {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.hosts[host]
+	if state != nil && time.Now().Before(state.openUntil) {
+		return &retryExhaustedError{fmt.Sprintf("主机 %s 连续失败 %d 次已熔断，%s 后恢复",
+			host, state.failures, time.Until(state.openUntil).Round(time.Second))}
+	}
+	return nil
+}
+
+// report 记录一次请求结果（请求错误或可重试状态码视为失败）
+func (b *circuitBreakers) report(host string, config models.CircuitBreakerConfig, success bool) {
+	if b == nil || !config.Enabled {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.hosts[host]
+	if state == nil {
+		state = &breakerState{}
+		b.hosts[host] = state
+	}
+	if success {
+		state.failures = 0
+		state.openUntil = time.Time{}
+		return
+	}
+	state.failures++
+	if state.failures >= config.FailureThreshold {
+		state.openUntil = time.Now().Add(config.Cooldown)
+	}
+}
+
+// requestHost 返回请求URL的主机（含端口），用作熔断键
+func requestHost(rawURL string) string {
+	if parsedURL, err := url.Parse(rawURL); err == nil && parsedURL.Host != "" {
+		return strings.ToLower(parsedURL.Host)
+	}
+	return rawURL
+}
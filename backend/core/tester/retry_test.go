@@ -0,0 +1,205 @@
+package tester
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		wait  time.Duration
+		ok    bool
+	}{
+		{"3", 3 * time.Second, true},
+		{" 0 ", 0, true},
+		{"Mon, 01 Jan 2024 00:00:10 GMT", 10 * time.Second, true},
+		{"Sun, 31 Dec 2023 23:59:00 GMT", 0, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		wait, ok := parseRetryAfter(tt.value, now)
+		if wait != tt.wait || ok != tt.ok {
+			t.Errorf("%q: expected %s/%v, got %s/%v", tt.value, tt.wait, tt.ok, wait, ok)
+		}
+	}
+}
+
+func TestRetryScheduleDelay(t *testing.T) {
+	legacy := newRetrySchedule(&models.ValidationConfig{})
+	if legacy.maxRetries != legacyMaxRetries || legacy.delay(2, nil) != 400*time.Millisecond {
+		t.Fatalf("expected legacy schedule, got %#v", legacy)
+	}
+
+	schedule := newRetrySchedule(&models.ValidationConfig{RetryPolicy: models.RetryPolicy{
+		Enabled:           true,
+		RespectRetryAfter: true,
+		BaseDelay:         time.Second,
+		MaxDelay:          5 * time.Second,
+		Jitter:            0.5,
+	}})
+	if schedule.maxRetries != 0 {
+		t.Fatalf("expected explicit zero retries, got %d", schedule.maxRetries)
+	}
+	for attempt := 0; attempt < 40; attempt++ {
+		wait := schedule.delay(attempt, nil)
+		ceiling := min(time.Second<<min(attempt, 30), 5*time.Second)
+		if wait < ceiling/2 || wait > ceiling {
+			t.Fatalf("attempt %d: delay %s outside [%s, %s]", attempt, wait, ceiling/2, ceiling)
+		}
+	}
+
+	response := &models.ResponseData{Headers: map[string]string{"Retry-After": "2"}}
+	if wait := schedule.delay(0, response); wait != 2*time.Second {
+		t.Fatalf("expected Retry-After to be honoured, got %s", wait)
+	}
+	response.Headers["Retry-After"] = "120"
+	if wait := schedule.delay(0, response); wait != 5*time.Second {
+		t.Fatalf("expected Retry-After to be capped, got %s", wait)
+	}
+}
+
+func TestSendRetriesRetryableStatus(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tester := NewRequestTester()
+	req := &models.ParsedRequest{Method: "GET", URL: server.URL + "/"}
+	config := &models.ValidationConfig{RetryPolicy: models.RetryPolicy{
+		Enabled:           true,
+		MaxRetries:        3,
+		RetryStatuses:     []int{429},
+		RespectRetryAfter: true,
+	}}
+	session, err := tester.newSession("", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	response, retries, err := tester.sendCountingRetries(context.Background(), session, req, config)
+	if err != nil || response.StatusCode != http.StatusOK || retries != 2 {
+		t.Fatalf("expected success after 2 retries, got %v %d %v", response, retries, err)
+	}
+
+	// 显式设置不重试
+	atomic.StoreInt32(&hits, 0)
+	config.RetryPolicy.MaxRetries = 0
+	response, retries, err = tester.sendCountingRetries(context.Background(), session, req, config)
+	var exhausted *retryExhaustedError
+	if !errors.As(err, &exhausted) || retries != 0 || atomic.LoadInt32(&hits) != 1 {
+		t.Fatalf("expected a single attempt, got retries=%d hits=%d err=%v", retries, hits, err)
+	}
+	if response == nil || response.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected last response to be returned, got %v", response)
+	}
+}
+
+func TestCircuitBreakerOpensPerHost(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tester := NewRequestTester()
+	req := &models.ParsedRequest{Method: "GET", URL: server.URL + "/"}
+	config := &models.ValidationConfig{RetryPolicy: models.RetryPolicy{
+		Enabled:        true,
+		RetryStatuses:  []int{503},
+		CircuitBreaker: models.CircuitBreakerConfig{Enabled: true, FailureThreshold: 2, Cooldown: time.Hour},
+	}}
+	session, err := tester.newSession("", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		_, _, err := tester.sendCountingRetries(context.Background(), session, req, config)
+		var exhausted *retryExhaustedError
+		if !errors.As(err, &exhausted) {
+			t.Fatalf("request %d: expected retryExhaustedError, got %v", i+1, err)
+		}
+	}
+	if hits != 2 {
+		t.Fatalf("expected breaker to stop requests after 2 failures, got %d hits", hits)
+	}
+
+	// 其他主机不受影响
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer other.Close()
+	if _, _, err := tester.sendCountingRetries(context.Background(), session, &models.ParsedRequest{Method: "GET", URL: other.URL + "/"}, config); err != nil {
+		t.Fatalf("unexpected error for another host: %v", err)
+	}
+}
+
+func TestBatchTestFieldNecessityReportsRetries(t *testing.T) {
+	var throttled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("X-Gateway") == "":
+			// 缺少该头时网关一直不可用：无法判定
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.Header.Get("X-Optional") == "" && atomic.AddInt32(&throttled, 1) == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-Gateway": "1", "X-Optional": "1"},
+		HeaderOrder: []string{"X-Gateway", "X-Optional"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+		RetryPolicy: models.RetryPolicy{
+			Enabled:           true,
+			MaxRetries:        1,
+			RetryStatuses:     []int{429, 503},
+			RespectRetryAfter: true,
+			BaseDelay:         time.Millisecond,
+		},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	gateway := result.CumulativeResults.Headers["X-Gateway"]
+	if gateway == nil || !gateway.Unstable || gateway.Required || gateway.Status != models.FieldStatusUnstable || gateway.Retries != 1 || !gateway.TestResult.RetryExhausted {
+		t.Fatalf("expected X-Gateway to be inconclusive after 1 retry, got %#v", gateway)
+	}
+	optional := result.CumulativeResults.Headers["X-Optional"]
+	if optional == nil || optional.Required || optional.Unstable || optional.Retries != 1 {
+		t.Fatalf("expected X-Optional to be optional after 1 retry, got %#v", optional)
+	}
+	if _, kept := result.SimplifiedRequest.Headers["X-Gateway"]; !kept {
+		t.Fatalf("expected inconclusive field to be kept")
+	}
+}
@@ -0,0 +1,80 @@
+package tester
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RunControl 字段测试运行控制（暂停/恢复）
+//
+// 暂停只在两次请求之间生效，正在发送的请求会先完成；取消通过运行的 context 完成。
+type RunControl struct {
+	mu     sync.Mutex
+	paused bool
+	resume chan struct{}
+}
+
+// NewRunControl 创建运行控制
+func NewRunControl() *RunControl {
+	return &RunControl{}
+}
+
+// Pause 暂停运行
+func (c *RunControl) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.paused {
+		c.paused = true
+		c.resume = make(chan struct{})
+	}
+}
+
+// Resume 恢复运行
+func (c *RunControl) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.paused {
+		c.paused = false
+		close(c.resume)
+	}
+}
+
+// Paused 是否处于暂停状态
+func (c *RunControl) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.paused
+}
+
+// wait 暂停期间阻塞，直到恢复或 context 结束
+func (c *RunControl) wait(ctx context.Context) error {
+	if c == nil {
+		return ctx.Err()
+	}
+
+	c.mu.Lock()
+	paused, resume := c.paused, c.resume
+	c.mu.Unlock()
+
+	if paused {
+		select {
+		case <-resume:
+		case <-ctx.Done():
+		}
+	}
+	return ctx.Err()
+}
+
+// sleepContext 等待指定时长，context 结束时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,134 @@
+package tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+func TestRunControlPauseAndResume(t *testing.T) {
+	control := NewRunControl()
+	control.Pause()
+	if !control.Paused() {
+		t.Fatalf("expected control to be paused")
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- control.wait(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatalf("expected wait to block while paused")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	control.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected wait to return after resume")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	control.Pause()
+	cancel()
+	if err := control.wait(ctx); err != context.Canceled {
+		t.Fatalf("expected canceled wait, got %v", err)
+	}
+}
+
+func TestBatchTestFieldNecessityReturnsPartialResultOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 原始请求 + 第一个字段测试后取消
+		if atomic.AddInt32(&requests, 1) == 2 {
+			cancel()
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-A": "1", "X-B": "2", "X-C": "3"},
+		HeaderOrder: []string{"X-A", "X-B", "X-C"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(ctx, req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.Canceled {
+		t.Fatalf("expected result to be marked canceled")
+	}
+	if len(result.HeaderResults) != 0 {
+		t.Fatalf("expected untrusted in-flight result to be dropped, got %#v", result.HeaderResults)
+	}
+	if len(result.SimplifiedRequest.Headers) != 3 {
+		t.Fatalf("expected untested headers to be kept, got %#v", result.SimplifiedRequest.Headers)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Fatalf("expected no requests after cancel, got %d", got)
+	}
+}
+
+func TestBatchTestFieldNecessitySimplifiedCodeIsValidPython(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if len(query["q"]) == 2 && query.Get("k") == "x=y" {
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "POST",
+		URL:         server.URL + "/api?q=a+b&k=x%3Dy&q=c",
+		Headers:     map[string]string{"Content-Type": "application/json", "X-Quote": `say "hi"`},
+		HeaderOrder: []string{"Content-Type", "X-Quote"},
+		Body:        `{"name":"a\"b","ok":true,"tags":null}`,
+		ContentType: "application/json",
+		QueryParams: map[string]string{"q": "a b", "k": "x=y"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	code := result.SimplifiedCode
+	for _, expected := range []string{
+		`params = [
+    ("q", "a b"),
+    ("k", "x=y"),
+    ("q", "c"),
+]`,
+		`data = {"name":"a\"b","ok":True,"tags":None}`,
+		"json=data",
+	} {
+		if !strings.Contains(code, expected) {
+			t.Fatalf("expected simplified code to contain %q, got:\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "X-Quote") || strings.Contains(code, "%") {
+		t.Fatalf("unexpected simplified code:\n%s", code)
+	}
+}
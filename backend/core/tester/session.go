@@ -0,0 +1,74 @@
+package tester
+
+import (
+	"fmt"
+	"net/http"
+
+	"RequestProbe/backend/models"
+
+	"github.com/google/uuid"
+)
+
+// RunOptions 字段必要性测试的运行选项
+type RunOptions struct {
+	RunID      string                        // 运行ID（为空时自动生成）
+	Control    *RunControl                   // 暂停/恢复控制（可为 nil）
+	Progress   func(*models.TestProgress)    // 进度回调（可为 nil）
+	Transcript func(*models.TranscriptEntry) // 运行记录回调，每发送一个测试请求回调一次（可为 nil）
+}
+
+// requestSession 一次运行独占的请求会话
+//
+// 会话创建时固定HTTP客户端配置（超时、网络配置、重定向策略），之后不再修改，
+// 因此并发的多个运行之间、运行与单次测试之间互不影响。
+type requestSession struct {
+	id         string
+	network    models.NetworkConfig // 创建会话时的网络配置
+	client     *http.Client
+	transcript *transcriptRecorder // 运行记录（单次测试时为 nil）
+	breakers   *circuitBreakers    // 按主机的熔断状态
+}
+
+// newSession 按测试器默认值与验证配置创建请求会话
+//
+// 网络配置无效（如代理地址错误、证书无法加载）时返回错误。
+func (t *RequestTester) newSession(id string, config *models.ValidationConfig) (*requestSession, error) {
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	t.mu.RLock()
+	timeout, proxy := t.timeout, t.proxy
+	t.mu.RUnlock()
+
+	var network models.NetworkConfig
+	if config != nil {
+		network = config.Network
+	}
+	if config != nil && config.Timeout > 0 {
+		timeout = config.Timeout
+	}
+	// 没有验证配置时（如单独调用）沿用 net/http 的默认行为：跟随重定向
+	followRedirect := config == nil || config.FollowRedirect
+
+	transport, err := newTransport(network, proxy)
+	if err != nil {
+		return nil, fmt.Errorf("网络配置错误: %v", err)
+	}
+
+	return &requestSession{
+		id:       id,
+		network:  network,
+		breakers: newCircuitBreakers(),
+		client: &http.Client{
+			Timeout:       timeout,
+			Transport:     transport,
+			CheckRedirect: newCheckRedirect(followRedirect),
+		},
+	}, nil
+}
+
+// close 释放会话的空闲连接
+func (s *requestSession) close() {
+	s.client.CloseIdleConnections()
+}
@@ -0,0 +1,182 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// 相似度各部分权重（状态码不一致时直接判定失败）
+const (
+	similarityStatusWeight = 0.2
+	similarityHeaderWeight = 0.2
+	similarityBodyWeight   = 0.6
+
+	defaultSimilarityThreshold = 0.9
+	shingleSize                = 3
+)
+
+// volatileResponseHeaders 每次请求都可能变化的响应头，不参与响应头集合比较
+var volatileResponseHeaders = map[string]bool{
+	"date":              true,
+	"age":               true,
+	"expires":           true,
+	"etag":              true,
+	"last-modified":     true,
+	"content-length":    true,
+	"set-cookie":        true,
+	"x-request-id":      true,
+	"x-trace-id":        true,
+	"x-response-time":   true,
+	"cf-ray":            true,
+	"server-timing":     true,
+	"x-amz-cf-id":       true,
+	"x-amzn-requestid":  true,
+	"x-amzn-trace-id":   true,
+	"x-cache":           true,
+	"x-served-by":       true,
+	"x-timer":           true,
+	"report-to":         true,
+	"nel":               true,
+	"transfer-encoding": true,
+}
+
+var (
+	digitRunPattern   = regexp.MustCompile(`\d+`)
+	textTokenPattern  = regexp.MustCompile(`[\p{L}\p{N}_]+|[^\s\p{L}\p{N}_]`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// compareWithBaseline 计算响应与基线响应的相似度
+func compareWithBaseline(baseline, response *models.ResponseData, config models.BaselineConfig) *models.SimilarityScore {
+	threshold := config.Threshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultSimilarityThreshold
+	}
+
+	score := &models.SimilarityScore{
+		StatusMatch: baseline.StatusCode == response.StatusCode,
+		HeaderScore: headerSetSimilarity(baseline.Headers, response.Headers),
+		Threshold:   threshold,
+	}
+	score.BodyScore, score.BodyMethod = bodySimilarity(baseline.Body, response.Body)
+
+	statusScore := 0.0
+	if score.StatusMatch {
+		statusScore = 1
+	}
+	score.Score = statusScore*similarityStatusWeight + score.HeaderScore*similarityHeaderWeight + score.BodyScore*similarityBodyWeight
+	score.Passed = score.StatusMatch && score.Score >= threshold
+
+	return score
+}
+
+// describeSimilarity 生成相似度说明
+func describeSimilarity(score *models.SimilarityScore) string {
+	if !score.StatusMatch {
+		return fmt.Sprintf("状态码与基线不一致，相似度 %.2f", score.Score)
+	}
+	return fmt.Sprintf("与基线相似度 %.2f（响应头 %.2f，响应体[%s] %.2f，阈值 %.2f）",
+		score.Score, score.HeaderScore, score.BodyMethod, score.BodyScore, score.Threshold)
+}
+
+// headerSetSimilarity 比较响应头名称集合（忽略易变响应头）
+func headerSetSimilarity(a, b map[string]string) float64 {
+	return jaccard(headerNameSet(a), headerNameSet(b))
+}
+
+func headerNameSet(headers map[string]string) map[string]bool {
+	set := make(map[string]bool, len(headers))
+	for name := range headers {
+		lower := strings.ToLower(name)
+		if !volatileResponseHeaders[lower] {
+			set[lower] = true
+		}
+	}
+	return set
+}
+
+// bodySimilarity 比较响应体：两边都是JSON时做结构比较，否则做文本 shingle 比较
+func bodySimilarity(a, b string) (float64, string) {
+	var jsonA, jsonB interface{}
+	if json.Unmarshal([]byte(a), &jsonA) == nil && json.Unmarshal([]byte(b), &jsonB) == nil {
+		return jaccard(jsonStructure(jsonA), jsonStructure(jsonB)), "json"
+	}
+	return jaccard(textShingles(a), textShingles(b)), "shingle"
+}
+
+// jsonStructure 将JSON展开为 路径:类型 集合，数组下标统一折叠为 []
+func jsonStructure(value interface{}) map[string]bool {
+	set := make(map[string]bool)
+	var walk func(path string, v interface{})
+	walk = func(path string, v interface{}) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			set[path+":object"] = true
+			keys := make([]string, 0, len(node))
+			for key := range node {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(path+"."+key, node[key])
+			}
+		case []interface{}:
+			set[path+":array"] = true
+			for _, item := range node {
+				walk(path+"[]", item)
+			}
+		case string:
+			set[path+":string"] = true
+		case float64:
+			set[path+":number"] = true
+		case bool:
+			set[path+":bool"] = true
+		case nil:
+			set[path+":null"] = true
+		}
+	}
+	walk("$", value)
+	return set
+}
+
+// textShingles 归一化文本后生成词级 shingle 集合
+//
+// 归一化会转小写、合并空白并把连续数字替换为 0，避免时间戳、ID 等易变内容影响相似度。
+func textShingles(text string) map[string]bool {
+	normalized := strings.ToLower(text)
+	normalized = digitRunPattern.ReplaceAllString(normalized, "0")
+	normalized = whitespacePattern.ReplaceAllString(normalized, " ")
+	tokens := textTokenPattern.FindAllString(normalized, -1)
+
+	set := make(map[string]bool)
+	if len(tokens) < shingleSize {
+		for _, token := range tokens {
+			set[token] = true
+		}
+		return set
+	}
+	for i := 0; i+shingleSize <= len(tokens); i++ {
+		set[strings.Join(tokens[i:i+shingleSize], " ")] = true
+	}
+	return set
+}
+
+// jaccard 计算两个集合的 Jaccard 相似度，两个空集合视为完全相同
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	intersection := 0
+	for key := range a {
+		if b[key] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	return float64(intersection) / float64(union)
+}
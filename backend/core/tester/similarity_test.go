@@ -0,0 +1,92 @@
+package tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestCompareWithBaseline(t *testing.T) {
+	baseline := &models.ResponseData{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json", "Date": "Mon, 01 Jan 2026 00:00:00 GMT"},
+		Body:       `{"code":0,"data":{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"}],"ts":1700000000}}`,
+	}
+
+	similar := &models.ResponseData{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json", "Date": "Tue, 02 Jan 2026 00:00:00 GMT"},
+		Body:       `{"code":0,"data":{"items":[{"id":7,"name":"c"}],"ts":1700000123}}`,
+	}
+	score := compareWithBaseline(baseline, similar, models.BaselineConfig{Threshold: 0.9})
+	if !score.Passed || score.BodyMethod != "json" || score.BodyScore != 1 {
+		t.Fatalf("expected structurally equal JSON to pass, got %#v", score)
+	}
+
+	loginPage := &models.ResponseData{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "text/html"},
+		Body:       `<html><body>请先登录</body></html>`,
+	}
+	score = compareWithBaseline(baseline, loginPage, models.BaselineConfig{Threshold: 0.9})
+	if score.Passed {
+		t.Fatalf("expected login page to fail baseline comparison, got %#v", score)
+	}
+
+	redirect := &models.ResponseData{StatusCode: 302, Headers: baseline.Headers, Body: baseline.Body}
+	if compareWithBaseline(baseline, redirect, models.BaselineConfig{}).Passed {
+		t.Fatalf("expected status mismatch to fail")
+	}
+}
+
+func TestTextShinglesIgnoreDigits(t *testing.T) {
+	a := textShingles("Order 12345 created at 2026-01-01 10:00")
+	b := textShingles("Order 99999 created at 2026-02-03 11:22")
+	if jaccard(a, b) != 1 {
+		t.Fatalf("expected digit runs to be normalized")
+	}
+}
+
+func TestBatchTestFieldNecessityUsesReferenceBaseline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-Token") == "" {
+			w.Write([]byte(`{"error":"login required"}`))
+			return
+		}
+		w.Write([]byte(`{"user":{"id":1,"name":"alice"}}`))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-Token": "t", "X-Extra": "x"},
+		HeaderOrder: []string{"X-Token", "X-Extra"},
+	}
+	reference := &models.ResponseData{
+		StatusCode: http.StatusOK,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"user":{"id":7,"name":"bob"}}`,
+	}
+	config := &models.ValidationConfig{Baseline: models.BaselineConfig{Enabled: true, Reference: reference}}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.CumulativeResults.Headers["X-Token"].Required || result.CumulativeResults.Headers["X-Extra"].Required {
+		t.Fatalf("unexpected results: %#v", result.CumulativeResults.Headers)
+	}
+
+	// 原始请求的响应与参照不一致时停止测试
+	reference.Body = `<html>maintenance</html>`
+	reference.Headers = map[string]string{"Content-Type": "text/html", "Retry-After": "60"}
+	result, err = NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err == nil || result.OriginalPassed {
+		t.Fatalf("expected mismatch with reference to fail the run")
+	}
+}
@@ -0,0 +1,201 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+// defaultExcerptLength 请求体与响应体的默认摘录字符数
+const defaultExcerptLength = 200
+
+// maskedValue 遮盖后的取值
+const maskedValue = "***"
+
+// sensitiveHeaders 默认遮盖取值的请求头与响应头（小写）
+var sensitiveHeaders = map[string]bool{
+	"cookie":              true,
+	"set-cookie":          true,
+	"authorization":       true,
+	"proxy-authorization": true,
+}
+
+// transcriptRecorder 一次运行的测试请求记录
+type transcriptRecorder struct {
+	runID   string
+	mask    bool
+	excerpt int
+	emit    func(*models.TranscriptEntry) // 每记录一条时回调（可为 nil）
+
+	mu      sync.Mutex
+	entries []models.TranscriptEntry
+}
+
+// newTranscriptRecorder 按验证配置创建运行记录
+func newTranscriptRecorder(runID string, config models.TranscriptConfig, emit func(*models.TranscriptEntry)) *transcriptRecorder {
+	excerpt := config.ExcerptLength
+	if excerpt <= 0 {
+		excerpt = defaultExcerptLength
+	}
+	return &transcriptRecorder{
+		runID:   runID,
+		mask:    !config.ShowSecrets,
+		excerpt: excerpt,
+		emit:    emit,
+		entries: []models.TranscriptEntry{},
+	}
+}
+
+// transcriptAttempt 一次测试请求的发送情况与判定结果
+type transcriptAttempt struct {
+	step     string
+	started  time.Time
+	request  *models.ParsedRequest
+	response *models.ResponseData // 请求失败时为 nil
+	retries  int
+	result   *models.SingleRequestResult
+}
+
+// record 追加一条记录（记录器为 nil 时忽略）
+func (r *transcriptRecorder) record(attempt transcriptAttempt) {
+	if r == nil {
+		return
+	}
+
+	entry := models.TranscriptEntry{
+		RunID:   r.runID,
+		Time:    attempt.started,
+		Step:    attempt.step,
+		Request: r.transcriptRequest(attempt.request, attempt.response),
+		Retries: attempt.retries,
+		Elapsed: time.Since(attempt.started),
+	}
+	if response := attempt.response; response != nil {
+		entry.Response = &models.TranscriptResponse{
+			StatusCode:    response.StatusCode,
+			Protocol:      response.Protocol,
+			Headers:       make(map[string]string, len(response.Headers)),
+			Duration:      response.Duration,
+			ContentLength: response.ContentLength,
+			BodyExcerpt:   excerptString(response.Body, r.excerpt),
+		}
+		for name, value := range response.Headers {
+			entry.Response.Headers[name] = r.maskHeader(name, value)
+		}
+	}
+	if result := attempt.result; result != nil {
+		entry.Passed = result.Success
+		entry.Verdict = result.Verdict
+		entry.Similarity = result.Similarity
+		entry.Error = result.Error
+	}
+
+	r.mu.Lock()
+	entry.Sequence = len(r.entries) + 1
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+
+	if r.emit != nil {
+		r.emit(&entry)
+	}
+}
+
+// transcriptRequest 记录实际发送的请求头；请求未发出时按解析结果列出
+func (r *transcriptRecorder) transcriptRequest(request *models.ParsedRequest, response *models.ResponseData) models.TranscriptRequest {
+	recorded := models.TranscriptRequest{
+		Method:      request.Method,
+		URL:         request.URL,
+		Headers:     []models.HeaderField{},
+		BodyExcerpt: excerptString(request.Body, r.excerpt),
+	}
+
+	var headers []models.HeaderField
+	if response != nil && len(response.SentHeaders) > 0 {
+		headers = response.SentHeaders
+	} else {
+		for _, name := range request.OrderedHeaderNames() {
+			if len(request.Cookies) > 0 && strings.EqualFold(name, "cookie") {
+				continue
+			}
+			headers = append(headers, models.HeaderField{Name: name, Value: request.Headers[name]})
+		}
+		if len(request.Cookies) > 0 {
+			pairs := make([]string, 0, len(request.Cookies))
+			for _, name := range request.OrderedCookieNames() {
+				pairs = append(pairs, name+"="+request.Cookies[name])
+			}
+			headers = append(headers, models.HeaderField{Name: "Cookie", Value: strings.Join(pairs, "; ")})
+		}
+	}
+
+	for _, header := range headers {
+		header.Value = r.maskHeader(header.Name, header.Value)
+		recorded.Headers = append(recorded.Headers, header)
+	}
+	return recorded
+}
+
+// maskHeader 遮盖敏感头的值：Cookie 只保留名称，Authorization 只保留认证方案
+func (r *transcriptRecorder) maskHeader(name, value string) string {
+	lower := strings.ToLower(name)
+	if !r.mask || !sensitiveHeaders[lower] || value == "" {
+		return value
+	}
+
+	switch lower {
+	case "cookie":
+		pairs := strings.Split(value, ";")
+		for i, pair := range pairs {
+			cookieName, _, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			pairs[i] = cookieName + "=" + maskedValue
+		}
+		return strings.Join(pairs, "; ")
+	case "set-cookie":
+		cookieName, rest, _ := strings.Cut(value, "=")
+		if _, attributes, found := strings.Cut(rest, ";"); found {
+			return cookieName + "=" + maskedValue + ";" + attributes
+		}
+		return cookieName + "=" + maskedValue
+	default:
+		if scheme, _, found := strings.Cut(value, " "); found {
+			return scheme + " " + maskedValue
+		}
+		return maskedValue
+	}
+}
+
+// list 返回记录副本
+func (r *transcriptRecorder) list() []models.TranscriptEntry {
+	if r == nil {
+		return []models.TranscriptEntry{}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.TranscriptEntry{}, r.entries...)
+}
+
+// excerptString 按字符数截取摘录
+func excerptString(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return string(runes[:maxRunes]) + "..."
+}
+
+// WriteTranscript 以 JSON Lines 格式写出运行记录（每行一条，键按字段顺序）
+func WriteTranscript(w io.Writer, entries []models.TranscriptEntry) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for i := range entries {
+		if err := encoder.Encode(&entries[i]); err != nil {
+			return fmt.Errorf("写出第%d条记录失败: %v", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package tester
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestBatchTestFieldNecessityRecordsTranscript(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("denied"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"Authorization": "Bearer secret-token", "X-Extra": "x"},
+		HeaderOrder: []string{"Authorization", "X-Extra"},
+		Cookies:     map[string]string{"sid": "secret-cookie"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+	}
+
+	var streamed []*models.TranscriptEntry
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{
+		Transcript: func(entry *models.TranscriptEntry) { streamed = append(streamed, entry) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 原始请求 + 3个字段
+	if len(result.Transcript) != 4 || len(streamed) != 4 {
+		t.Fatalf("expected 4 entries, got %d (streamed %d)", len(result.Transcript), len(streamed))
+	}
+	first := result.Transcript[0]
+	if first.Sequence != 1 || first.RunID != result.RunID || first.Step != "测试原始请求..." || !first.Passed || first.Verdict == nil {
+		t.Fatalf("unexpected first entry: %#v", first)
+	}
+	if first.Response == nil || first.Response.StatusCode != http.StatusOK || first.Response.BodyExcerpt != "ok" {
+		t.Fatalf("unexpected first response: %#v", first.Response)
+	}
+
+	sent := make(map[string]models.HeaderField)
+	for _, header := range first.Request.Headers {
+		sent[header.Name] = header
+	}
+	if sent["Authorization"].Value != "Bearer ***" || sent["Cookie"].Value != "sid=***" {
+		t.Fatalf("expected secrets to be masked, got %#v", first.Request.Headers)
+	}
+	if !sent["Host"].Implicit {
+		t.Fatalf("expected implicit headers to be recorded, got %#v", first.Request.Headers)
+	}
+
+	var failed *models.TranscriptEntry
+	for i := range result.Transcript {
+		if entry := &result.Transcript[i]; entry.Response != nil && entry.Response.StatusCode == http.StatusUnauthorized {
+			failed = entry
+		}
+	}
+	if failed == nil || failed.Passed || !strings.Contains(failed.Step, "Authorization") {
+		t.Fatalf("expected a failed entry for Authorization, got %#v", failed)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTranscript(&buf, result.Transcript); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var entry models.TranscriptEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v", lines+1, err)
+		}
+		lines++
+	}
+	if lines != 4 || strings.Contains(buf.String(), "secret") {
+		t.Fatalf("expected 4 masked lines, got %d:\n%s", lines, buf.String())
+	}
+}
+
+func TestTranscriptShowSecrets(t *testing.T) {
+	recorder := newTranscriptRecorder("run", models.TranscriptConfig{ShowSecrets: true, ExcerptLength: 3}, nil)
+	recorder.record(transcriptAttempt{
+		request: &models.ParsedRequest{
+			Method:  "POST",
+			URL:     "http://example.com/",
+			Headers: map[string]string{"Authorization": "Basic abc"},
+			Cookies: map[string]string{"sid": "1"},
+			Body:    "你好世界",
+		},
+		result: &models.SingleRequestResult{Error: "请求执行失败"},
+	})
+
+	entry := recorder.list()[0]
+	if entry.Request.Headers[0].Value != "Basic abc" || entry.Request.Headers[1].Value != "sid=1" {
+		t.Fatalf("expected raw secrets, got %#v", entry.Request.Headers)
+	}
+	if entry.Request.BodyExcerpt != "你好世..." || entry.Error != "请求执行失败" || entry.Response != nil {
+		t.Fatalf("unexpected entry: %#v", entry)
+	}
+}
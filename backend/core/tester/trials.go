@@ -0,0 +1,71 @@
+package tester
+
+import (
+	"fmt"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// normalizeVoting 规范化投票方式，默认为多数投票
+func normalizeVoting(voting string) string {
+	if strings.EqualFold(strings.TrimSpace(voting), models.VotingUnanimous) {
+		return models.VotingUnanimous
+	}
+	return models.VotingMajority
+}
+
+// voteTrials 按投票方式汇总同一请求的多次试验结果
+//
+// 返回与投票结论一致的一次试验结果作为代表，并附上试验统计。
+// 多数投票时通过次数必须超过半数，平票按未通过处理。
+func voteTrials(results []*models.SingleRequestResult, voting string) *models.SingleRequestResult {
+	summary := &models.TrialSummary{Trials: len(results), Voting: normalizeVoting(voting)}
+	retries := 0
+	for _, result := range results {
+		retries += result.Retries
+		if result.Success {
+			summary.Passed++
+		} else {
+			summary.Failed++
+		}
+	}
+	summary.Flipped = summary.Passed > 0 && summary.Failed > 0
+
+	passed := summary.Passed*2 > summary.Trials
+	if summary.Voting == models.VotingUnanimous {
+		passed = summary.Passed == summary.Trials
+	}
+
+	var chosen models.SingleRequestResult
+	for _, result := range results {
+		if result.Success == passed {
+			chosen = *result
+			break
+		}
+	}
+	chosen.Success = passed
+	chosen.Trials = summary
+	chosen.Retries = retries
+	if summary.Flipped {
+		chosen.Note = strings.TrimSpace(fmt.Sprintf("%d/%d 次试验通过，结果不稳定 %s", summary.Passed, summary.Trials, chosen.Note))
+	}
+	return &chosen
+}
+
+// preflightOriginal 重复发送原始请求，检查验证条件与目标接口本身是否稳定
+//
+// 返回按投票方式汇总后的结果；未启用预检时返回 nil。
+func (t *RequestTester) preflightOriginal(run *fieldTestRun) *models.SingleRequestResult {
+	count := run.config.Trials.Preflight
+	if count <= 0 {
+		return nil
+	}
+
+	results := make([]*models.SingleRequestResult, 0, count)
+	for i := 0; i < count; i++ {
+		run.updateProgress(fmt.Sprintf("预检原始请求 (%d/%d)", i+1, count))
+		results = append(results, run.executeOnce(run.original))
+	}
+	return voteTrials(results, run.config.Trials.Voting)
+}
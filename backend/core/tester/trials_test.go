@@ -0,0 +1,135 @@
+package tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestVoteTrials(t *testing.T) {
+	pass := &models.SingleRequestResult{Success: true}
+	fail := &models.SingleRequestResult{Success: false, Error: "502"}
+
+	majority := voteTrials([]*models.SingleRequestResult{pass, fail, pass}, "")
+	if !majority.Success || !majority.Trials.Flipped || majority.Trials.Passed != 2 || majority.Trials.Voting != models.VotingMajority {
+		t.Fatalf("unexpected majority vote: %#v %#v", majority, majority.Trials)
+	}
+
+	unanimous := voteTrials([]*models.SingleRequestResult{pass, fail, pass}, models.VotingUnanimous)
+	if unanimous.Success || unanimous.Error != "502" {
+		t.Fatalf("expected unanimous vote to fail with failing trial as representative, got %#v", unanimous)
+	}
+
+	tie := voteTrials([]*models.SingleRequestResult{pass, fail}, models.VotingMajority)
+	if tie.Success {
+		t.Fatalf("expected tie to be treated as failure")
+	}
+
+	stable := voteTrials([]*models.SingleRequestResult{fail, fail, fail}, models.VotingMajority)
+	if stable.Success || stable.Trials.Flipped {
+		t.Fatalf("expected stable failure, got %#v", stable.Trials)
+	}
+}
+
+func TestNewFieldTestResultMarksFlippedFieldsUnstable(t *testing.T) {
+	flipped := voteTrials([]*models.SingleRequestResult{{Success: true}, {Success: false}, {Success: false}}, models.VotingMajority)
+
+	result := newFieldTestResult(!flipped.Success, "v", flipped)
+	if result.Required || !result.Unstable || result.Status != models.FieldStatusUnstable {
+		t.Fatalf("expected unstable field, got %#v", result)
+	}
+	if legacy := newLegacyTestResult("X-Token", "header", result); legacy.IsRequired || !legacy.Unstable {
+		t.Fatalf("expected legacy result to be unstable, got %#v", legacy)
+	}
+
+	if result := newFieldTestResult(true, "v", &models.SingleRequestResult{}); result.Status != models.FieldStatusRequired {
+		t.Fatalf("expected required status, got %q", result.Status)
+	}
+}
+
+func TestBatchTestFieldNecessityRestoresUnstableFields(t *testing.T) {
+	var flakyMissing, untestedCombination int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Flaky") == "" {
+			if r.Header.Get("X-Other") == "" {
+				// X-Flaky 结论不稳定而被保留，后续测试不应再缺少它
+				atomic.AddInt32(&untestedCombination, 1)
+			}
+			if atomic.AddInt32(&flakyMissing, 1) == 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-Flaky": "1", "X-Other": "1"},
+		HeaderOrder: []string{"X-Flaky", "X-Other"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+		Trials:       models.TrialConfig{Count: 3, Voting: models.VotingMajority},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if flaky := result.CumulativeResults.Headers["X-Flaky"]; flaky == nil || !flaky.Unstable {
+		t.Fatalf("expected X-Flaky to be unstable, got %#v", flaky)
+	}
+	if n := atomic.LoadInt32(&untestedCombination); n != 0 {
+		t.Fatalf("expected X-Other to be tested with X-Flaky restored, got %d requests without both", n)
+	}
+	if _, kept := result.SimplifiedRequest.Headers["X-Flaky"]; !kept {
+		t.Fatalf("expected unstable field to be kept")
+	}
+	if _, kept := result.SimplifiedRequest.Headers["X-Other"]; kept {
+		t.Fatalf("expected X-Other to be removed")
+	}
+}
+
+func TestBatchTestFieldNecessityFailsOnFlippedPreflight(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 原始请求通过，3 次预检中第 2 次失败
+		if atomic.AddInt32(&requests, 1) == 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-A": "1"},
+		HeaderOrder: []string{"X-A"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+		Trials:       models.TrialConfig{Preflight: 3, Voting: models.VotingMajority},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err == nil {
+		t.Fatalf("expected flipped preflight to fail the run")
+	}
+	if result.OriginalPassed || !strings.Contains(result.OriginalError, "2/3") || result.Preflight == nil || !result.Preflight.Flipped {
+		t.Fatalf("unexpected preflight result: %q %#v", result.OriginalError, result.Preflight)
+	}
+	if n := atomic.LoadInt32(&requests); n != 4 {
+		t.Fatalf("expected no field tests after preflight, got %d requests", n)
+	}
+}
@@ -0,0 +1,243 @@
+package tester
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"unicode"
+
+	"RequestProbe/backend/models"
+)
+
+// probeInput 一次待发送的取值探测
+type probeInput struct {
+	kind  string // 探测方式
+	value string // 探测值（未编码）
+}
+
+// valueCodec 字段值的编解码：请求体JSON字段保存的是JSON文本，探测时只变异字符串内容
+type valueCodec struct {
+	plain  string              // 可变异的原始值
+	encode func(string) string // 把变异后的值写回字段
+}
+
+// newValueCodec 返回字段的编解码；无法安全变异的字段（如JSON对象、数字）返回 false
+func newValueCodec(req *models.ParsedRequest, field candidateField) (valueCodec, bool) {
+	identity := func(value string) string { return value }
+	if field.Type != "body" || detectBodyKind(req) != bodyKindJSON {
+		return valueCodec{plain: field.Value, encode: identity}, true
+	}
+
+	var text string
+	if err := json.Unmarshal([]byte(field.Value), &text); err != nil {
+		return valueCodec{}, false
+	}
+	return valueCodec{plain: text, encode: func(value string) string {
+		data, _ := json.Marshal(value)
+		return string(data)
+	}}, true
+}
+
+// randomLike 生成同长度、同字符类型的随机值（数字换数字、字母换同大小写字母，其余字符保留）
+func randomLike(value string) string {
+	runes := []rune(value)
+	for i, r := range runes {
+		switch {
+		case r >= '0' && r <= '9':
+			runes[i] = rune('0' + rand.IntN(10))
+		case r >= 'a' && r <= 'z':
+			runes[i] = rune('a' + rand.IntN(26))
+		case r >= 'A' && r <= 'Z':
+			runes[i] = rune('A' + rand.IntN(26))
+		case unicode.IsLetter(r):
+			runes[i] = rune('a' + rand.IntN(26))
+		}
+	}
+	return string(runes)
+}
+
+// mutateRandom 生成与原值不同的随机值；原值没有可替换的字符时返回 false
+func mutateRandom(value string) (string, bool) {
+	for i := 0; i < 5; i++ {
+		if mutated := randomLike(value); mutated != value {
+			return mutated, true
+		}
+	}
+	return "", false
+}
+
+// staleFieldValue 从另一次抓包中查找同一字段的值
+func staleFieldValue(stale *models.ParsedRequest, field candidateField) (string, bool) {
+	if stale == nil {
+		return "", false
+	}
+	switch field.Type {
+	case "header":
+		for name, value := range stale.Headers {
+			if strings.EqualFold(name, field.Name) {
+				return value, true
+			}
+		}
+	case "cookie":
+		value, exists := stale.Cookies[field.Name]
+		return value, exists
+	case "query":
+		value, exists := originalQueryParams(stale)[field.Name]
+		return value, exists
+	case "body":
+		for _, bodyField := range listBodyFields(stale) {
+			if bodyField.Path == field.Name {
+				return bodyField.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// classifySensitivity 根据探测结果判断取值敏感度
+//
+// 空值、随机值、截断值都通过时只检查存在；同格式的随机值不通过时校验具体值；
+// 随机值通过但空值或截断值不通过时只检查格式。旧值通过说明值不需要每次刷新。
+// 结果不可信的探测分别按通过与不通过判断，两种结论不同时记为无法判断。
+func classifySensitivity(probes []models.ValueProbe) *models.ValueSensitivity {
+	sensitivity := &models.ValueSensitivity{Probes: probes}
+	passed := make(map[string]bool)
+	tried := make(map[string]bool)
+	inconclusive := make(map[string]bool)
+	for _, probe := range probes {
+		tried[probe.Kind] = true
+		passed[probe.Kind] = probe.Passed
+		inconclusive[probe.Kind] = probe.Inconclusive
+	}
+
+	assume := func(outcome bool) map[string]bool {
+		assumed := make(map[string]bool, len(passed))
+		for kind, value := range passed {
+			if inconclusive[kind] {
+				value = outcome
+			}
+			assumed[kind] = value
+		}
+		return assumed
+	}
+	sensitivity.Class = sensitivityClass(tried, assume(true))
+	if sensitivity.Class != sensitivityClass(tried, assume(false)) {
+		sensitivity.Class = models.SensitivityUnknown
+		return sensitivity
+	}
+
+	if tried[models.ProbeStale] && !inconclusive[models.ProbeStale] {
+		staleAccepted := passed[models.ProbeStale]
+		sensitivity.StaleAccepted = &staleAccepted
+	}
+	sensitivity.MustRefresh = sensitivity.Class == models.SensitivityValueVerified &&
+		(sensitivity.StaleAccepted == nil || !*sensitivity.StaleAccepted)
+	return sensitivity
+}
+
+// sensitivityClass 根据各探测方式是否通过得出取值敏感度结论
+func sensitivityClass(tried, passed map[string]bool) string {
+	switch {
+	case tried[models.ProbeRandom] && !passed[models.ProbeRandom]:
+		return models.SensitivityValueVerified
+	case !tried[models.ProbeRandom] && (!passed[models.ProbeEmpty] || tried[models.ProbeTruncated] && !passed[models.ProbeTruncated]):
+		// 无法生成同格式随机值时，按更严格的结论处理
+		return models.SensitivityValueVerified
+	case !passed[models.ProbeEmpty] || tried[models.ProbeTruncated] && !passed[models.ProbeTruncated]:
+		return models.SensitivityFormatChecked
+	default:
+		return models.SensitivityPresenceOnly
+	}
+}
+
+// probeValueSensitivity 对必需字段逐一发送变异值，记录取值敏感度
+//
+// 请求以最小集合为基础（只保留必需与不稳定字段），每次只替换一个字段的值。
+// 运行被取消时，未完成全部探测的字段不记录结论。
+func (t *RequestTester) probeValueSensitivity(run *fieldTestRun, results *models.TestResults) {
+	candidates := t.collectCandidateFields(run.original, run.config)
+	full := newFullTestState(run.original, run.config)
+
+	kept := make(map[int]bool)
+	for i, field := range candidates {
+		if result, exists := fieldResults(results, field.Type)[field.Name]; exists && (result.Required || result.Unstable) {
+			kept[i] = true
+		}
+	}
+
+	for i, field := range candidates {
+		result := fieldResults(results, field.Type)[field.Name]
+		if !kept[i] || result == nil || !result.Required {
+			continue
+		}
+		codec, ok := newValueCodec(run.original, field)
+		if !ok {
+			continue
+		}
+		if codec.plain == "" {
+			result.Sensitivity = &models.ValueSensitivity{Class: models.SensitivityPresenceOnly, Probes: []models.ValueProbe{}}
+			continue
+		}
+
+		var probes []models.ValueProbe
+		values := []probeInput{{models.ProbeEmpty, ""}}
+		if random, ok := mutateRandom(codec.plain); ok {
+			values = append(values, probeInput{models.ProbeRandom, random})
+		}
+		if runes := []rune(codec.plain); len(runes) >= 2 {
+			values = append(values, probeInput{models.ProbeTruncated, string(runes[:len(runes)/2])})
+		}
+		if stale, ok := staleFieldValue(run.config.ValueProbe.StaleCapture, field); ok && stale != field.Value {
+			// 旧值按原样写回（请求体字段已是编码后的值）
+			values = append(values, probeInput{models.ProbeStale, stale})
+		}
+
+		for _, probe := range values {
+			if run.stopped() {
+				return
+			}
+			value := probe.value
+			if probe.kind != models.ProbeStale {
+				value = codec.encode(value)
+			}
+
+			run.updateProgress(fmt.Sprintf("取值探测%s: %s (%s)", fieldTypeLabel(field.Type), field.Name, probe.kind))
+			testResult := run.execute(t.buildRequestFromState(stateWithValue(stateWithFields(full, candidates, kept), field, value), run.original))
+			run.advance()
+			if run.stopped() {
+				return
+			}
+
+			valueProbe := models.ValueProbe{
+				Kind:   probe.kind,
+				Value:  value,
+				Passed: testResult.Success,
+				// 限流或结果翻转时不能当作服务端拒绝了该值
+				Inconclusive: testResult.RetryExhausted || testResult.Trials != nil && testResult.Trials.Flipped,
+			}
+			if testResult.ResponseInfo != nil {
+				valueProbe.StatusCode = testResult.ResponseInfo.StatusCode
+			}
+			probes = append(probes, valueProbe)
+		}
+
+		result.Sensitivity = classifySensitivity(probes)
+		run.updateProgress(fmt.Sprintf("完成取值探测%s: %s → %s", fieldTypeLabel(field.Type), field.Name, result.Sensitivity.Class))
+	}
+}
+
+// stateWithValue 替换状态中某个字段的值
+func stateWithValue(state *models.CumulativeTestState, field candidateField, value string) *models.CumulativeTestState {
+	switch field.Type {
+	case "header":
+		state.Headers[field.Name] = value
+	case "cookie":
+		state.Cookies[field.Name] = value
+	case "query":
+		state.QueryParams[field.Name] = value
+	case "body":
+		state.BodyFields[field.Name] = value
+	}
+	return state
+}
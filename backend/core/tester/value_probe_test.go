@@ -0,0 +1,144 @@
+package tester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"unicode"
+
+	"RequestProbe/backend/models"
+)
+
+func TestRandomLikeKeepsLengthAndCharacterClasses(t *testing.T) {
+	value := "ab12-CD.x"
+	mutated, ok := mutateRandom(value)
+	if !ok || mutated == value {
+		t.Fatalf("expected a different value, got %q", mutated)
+	}
+	original, changed := []rune(value), []rune(mutated)
+	if len(original) != len(changed) {
+		t.Fatalf("expected same length, got %q", mutated)
+	}
+	for i := range original {
+		switch {
+		case unicode.IsDigit(original[i]) != unicode.IsDigit(changed[i]),
+			unicode.IsUpper(original[i]) != unicode.IsUpper(changed[i]),
+			unicode.IsLetter(original[i]) != unicode.IsLetter(changed[i]):
+			t.Fatalf("character class changed at %d: %q -> %q", i, value, mutated)
+		case !unicode.IsLetter(original[i]) && !unicode.IsDigit(original[i]) && original[i] != changed[i]:
+			t.Fatalf("separator changed at %d: %q -> %q", i, value, mutated)
+		}
+	}
+
+	if _, ok := mutateRandom("--"); ok {
+		t.Fatalf("expected no random value for separators only")
+	}
+}
+
+func TestClassifySensitivity(t *testing.T) {
+	probe := func(kind string, passed bool) models.ValueProbe {
+		return models.ValueProbe{Kind: kind, Passed: passed}
+	}
+	flaky := func(kind string) models.ValueProbe {
+		return models.ValueProbe{Kind: kind, Passed: false, Inconclusive: true}
+	}
+
+	cases := []struct {
+		probes      []models.ValueProbe
+		class       string
+		mustRefresh bool
+	}{
+		{[]models.ValueProbe{probe(models.ProbeEmpty, true), probe(models.ProbeRandom, true), probe(models.ProbeTruncated, true)}, models.SensitivityPresenceOnly, false},
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, true), probe(models.ProbeTruncated, false)}, models.SensitivityFormatChecked, false},
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, false), probe(models.ProbeTruncated, false)}, models.SensitivityValueVerified, true},
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, false), probe(models.ProbeStale, true)}, models.SensitivityValueVerified, false},
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, false), probe(models.ProbeStale, false)}, models.SensitivityValueVerified, true},
+		// 随机值被限流，无法区分校验格式还是校验具体值
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), flaky(models.ProbeRandom), probe(models.ProbeTruncated, false)}, models.SensitivityUnknown, false},
+		// 截断值结果不可信，但随机值已失败，结论不受影响
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, false), flaky(models.ProbeTruncated)}, models.SensitivityValueVerified, true},
+		// 旧值结果不可信时不记录是否接受旧值
+		{[]models.ValueProbe{probe(models.ProbeEmpty, false), probe(models.ProbeRandom, false), flaky(models.ProbeStale)}, models.SensitivityValueVerified, true},
+	}
+	for i, tc := range cases {
+		sensitivity := classifySensitivity(tc.probes)
+		if sensitivity.Class != tc.class || sensitivity.MustRefresh != tc.mustRefresh {
+			t.Fatalf("case %d: expected %s/%v, got %s/%v", i, tc.class, tc.mustRefresh, sensitivity.Class, sensitivity.MustRefresh)
+		}
+		if tc.probes[len(tc.probes)-1].Kind == models.ProbeStale && tc.probes[len(tc.probes)-1].Inconclusive && sensitivity.StaleAccepted != nil {
+			t.Fatalf("case %d: expected inconclusive stale probe to be ignored", i)
+		}
+	}
+}
+
+func TestBatchTestFieldNecessityProbesValueSensitivity(t *testing.T) {
+	sidFormat := regexp.MustCompile(`^[a-z0-9]{8}$`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, present := r.Header["X-Present"]
+		token := r.Header.Get("X-Token")
+		sid, err := r.Cookie("sid")
+		if !present || (token != "abc123" && token != "old999") || err != nil || !sidFormat.MatchString(sid.Value) {
+			w.Write([]byte("denied"))
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	req := &models.ParsedRequest{
+		Method:      "GET",
+		URL:         server.URL + "/",
+		Headers:     map[string]string{"X-Present": "1", "X-Token": "abc123", "X-Extra": "x"},
+		Cookies:     map[string]string{"sid": "a1b2c3d4"},
+		HeaderOrder: []string{"X-Present", "X-Token", "X-Extra"},
+	}
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"},
+		ValueProbe: models.ValueProbeConfig{
+			Enabled:      true,
+			StaleCapture: &models.ParsedRequest{Headers: map[string]string{"x-token": "old999"}, Cookies: map[string]string{"sid": "zzzz9999"}},
+		},
+	}
+
+	result, err := NewRequestTester().BatchTestFieldNecessity(context.Background(), req, config, RunOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expect := map[string]struct {
+		result *models.FieldTestResult
+		class  string
+	}{
+		"X-Present": {result.CumulativeResults.Headers["X-Present"], models.SensitivityPresenceOnly},
+		"X-Token":   {result.CumulativeResults.Headers["X-Token"], models.SensitivityValueVerified},
+		"sid":       {result.CumulativeResults.Cookies["sid"], models.SensitivityFormatChecked},
+	}
+	for name, tc := range expect {
+		if tc.result == nil || tc.result.Sensitivity == nil {
+			t.Fatalf("%s: expected sensitivity to be recorded", name)
+		}
+		if tc.result.Sensitivity.Class != tc.class {
+			t.Fatalf("%s: expected %s, got %#v", name, tc.class, tc.result.Sensitivity)
+		}
+	}
+
+	token := expect["X-Token"].result.Sensitivity
+	if token.StaleAccepted == nil || !*token.StaleAccepted || token.MustRefresh {
+		t.Fatalf("expected stale token to be accepted, got %#v", token)
+	}
+	if result.CumulativeResults.Headers["X-Extra"].Sensitivity != nil {
+		t.Fatalf("expected optional field not to be probed")
+	}
+
+	var probeCost *models.PhaseCost
+	for i := range result.PhaseCosts {
+		if result.PhaseCosts[i].Phase == models.PhaseValueProbe {
+			probeCost = &result.PhaseCosts[i]
+		}
+	}
+	if probeCost == nil || probeCost.Requests == 0 {
+		t.Fatalf("expected value probe phase cost, got %#v", result.PhaseCosts)
+	}
+}
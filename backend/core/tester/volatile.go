@@ -0,0 +1,244 @@
+package tester
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+var (
+	hexPattern   = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	tokenPattern = regexp.MustCompile(`^[A-Za-z0-9_\-+/=]+$`)
+)
+
+// 合理的时间戳范围（2000-01-01 至 2100-01-01，秒）
+const (
+	minPlausibleUnix = 946684800
+	maxPlausibleUnix = 4102444800
+)
+
+// captureEntry 一次抓包中的一个字段
+type captureEntry struct {
+	ref   models.FieldRef
+	key   string // 对比用的键（请求头名称不区分大小写）
+	value string
+}
+
+// AnalyzeVolatileFields 对比同一接口的多次抓包，找出取值变化的字段并判断取值类型
+//
+// 抓包按方法+路径分组，只有一次抓包的接口放入 Unmatched。请求头按名称不区分大小写对比，
+// Content-Length 随请求体变化、不单独对比；已解析到 Cookies 的 Cookie 头也不重复对比。
+func AnalyzeVolatileFields(captures []*models.ParsedRequest) *models.VolatileAnalysis {
+	analysis := &models.VolatileAnalysis{Groups: []models.VolatileGroup{}, Unmatched: []int{}}
+
+	var groupKeys []string
+	groups := make(map[string]*models.VolatileGroup)
+	for i, capture := range captures {
+		if capture == nil {
+			continue
+		}
+		method := strings.ToUpper(capture.Method)
+		path := "/"
+		if parsedURL, err := url.Parse(capture.URL); err == nil && parsedURL.Path != "" {
+			path = parsedURL.Path
+		}
+		key := method + " " + path
+		if groups[key] == nil {
+			groups[key] = &models.VolatileGroup{Method: method, Path: path}
+			groupKeys = append(groupKeys, key)
+		}
+		groups[key].Captures = append(groups[key].Captures, i)
+	}
+
+	for _, key := range groupKeys {
+		group := groups[key]
+		if len(group.Captures) < 2 {
+			analysis.Unmatched = append(analysis.Unmatched, group.Captures...)
+			continue
+		}
+		group.Fields = compareCaptures(captures, group.Captures)
+		analysis.Groups = append(analysis.Groups, *group)
+	}
+	return analysis
+}
+
+// compareCaptures 对比一组抓包的字段（按字段首次出现的顺序）
+func compareCaptures(captures []*models.ParsedRequest, indexes []int) []models.VolatileField {
+	var order []captureEntry
+	values := make(map[string][]*string)
+	for position, index := range indexes {
+		for _, entry := range captureEntries(captures[index]) {
+			if values[entry.key] == nil {
+				values[entry.key] = make([]*string, len(indexes))
+				order = append(order, entry)
+			}
+			value := entry.value
+			values[entry.key][position] = &value
+		}
+	}
+
+	fields := make([]models.VolatileField, 0, len(order))
+	for _, entry := range order {
+		field := models.VolatileField{Type: entry.ref.Type, Name: entry.ref.Name, Status: models.FieldConstant}
+		for _, value := range values[entry.key] {
+			switch {
+			case value == nil:
+				field.Status = models.FieldPartial
+				field.Values = append(field.Values, "")
+				continue
+			case field.Status == models.FieldConstant && len(field.Values) > 0 && *value != field.Values[0]:
+				field.Status = models.FieldChanging
+			}
+			field.Values = append(field.Values, *value)
+		}
+		field.Kind = classifyVolatileField(field)
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// captureEntries 列出一次抓包中的全部字段
+func captureEntries(req *models.ParsedRequest) []captureEntry {
+	var entries []captureEntry
+
+	for _, name := range req.OrderedHeaderNames() {
+		lower := strings.ToLower(name)
+		if lower == "content-length" || lower == "cookie" && len(req.Cookies) > 0 {
+			continue
+		}
+		entries = append(entries, captureEntry{models.FieldRef{Type: "header", Name: name}, "header:" + lower, req.Headers[name]})
+	}
+
+	for _, name := range req.OrderedCookieNames() {
+		entries = append(entries, captureEntry{models.FieldRef{Type: "cookie", Name: name}, "cookie:" + name, req.Cookies[name]})
+	}
+
+	params := originalQueryParams(req)
+	seen := make(map[string]bool)
+	for _, name := range append(queryParamOrder(req.URL), sortedKeys(params)...) {
+		if value, exists := params[name]; exists && !seen[name] {
+			seen[name] = true
+			entries = append(entries, captureEntry{models.FieldRef{Type: "query", Name: name}, "query:" + name, value})
+		}
+	}
+
+	jsonBody := detectBodyKind(req) == bodyKindJSON
+	for _, field := range listBodyFields(req) {
+		value := field.Value
+		if jsonBody {
+			if _, err := parseOrderedJSONObject([]byte(value)); err == nil {
+				// 嵌套对象的变化由其子字段体现
+				continue
+			}
+			var text string
+			if err := json.Unmarshal([]byte(value), &text); err == nil {
+				value = text
+			}
+		}
+		entries = append(entries, captureEntry{models.FieldRef{Type: "body", Name: field.Path}, "body:" + field.Path, value})
+	}
+	return entries
+}
+
+// classifyVolatileField 判断字段取值类型：所有非空值类型一致时才给出结论，随机串只对变化的字段判断
+func classifyVolatileField(field models.VolatileField) string {
+	kind := ""
+	for _, value := range field.Values {
+		if value == "" {
+			continue
+		}
+		current := classifyVolatileValue(value)
+		if current == "" || kind != "" && current != kind {
+			return ""
+		}
+		kind = current
+	}
+	if kind == models.ValueKindNonce && field.Status != models.FieldChanging {
+		return ""
+	}
+	return kind
+}
+
+// classifyVolatileValue 判断单个取值的类型
+func classifyVolatileValue(value string) string {
+	value = strings.TrimSpace(value)
+	switch {
+	case isJWT(value):
+		return models.ValueKindJWT
+	case isTimestamp(value):
+		return models.ValueKindTimestamp
+	case isHexDigest(value):
+		return models.ValueKindHash
+	case isNonce(value):
+		return models.ValueKindNonce
+	}
+	return ""
+}
+
+// isJWT 判断是否为JWT（允许 Bearer 前缀），要求头部可解码且包含 alg
+func isJWT(value string) bool {
+	if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+		value = strings.TrimSpace(value[7:])
+	}
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return false
+	}
+
+	header, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[0], "="))
+	if err != nil {
+		return false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(header, &fields); err != nil {
+		return false
+	}
+	_, hasAlg := fields["alg"]
+	return hasAlg
+}
+
+// isTimestamp 判断是否为合理范围内的秒/毫秒时间戳或 RFC3339 时间
+func isTimestamp(value string) bool {
+	if _, err := time.Parse(time.RFC3339, value); err == nil {
+		return true
+	}
+	if len(value) != 10 && len(value) != 13 {
+		return false
+	}
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return false
+	}
+	if len(value) == 13 {
+		number /= 1000
+	}
+	return number >= minPlausibleUnix && number <= maxPlausibleUnix
+}
+
+// isHexDigest 判断是否为常见摘要长度的十六进制串
+func isHexDigest(value string) bool {
+	switch len(value) {
+	case 32, 40, 64, 128:
+		return hexPattern.MatchString(value)
+	}
+	return false
+}
+
+// isNonce 判断是否像随机串：UUID，或至少8位且同时含字母和数字的令牌字符
+func isNonce(value string) bool {
+	if uuidPattern.MatchString(value) {
+		return true
+	}
+	if len(value) < 8 || !tokenPattern.MatchString(value) {
+		return false
+	}
+	return strings.ContainsAny(value, "0123456789") &&
+		strings.IndexFunc(value, func(r rune) bool { return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' }) >= 0
+}
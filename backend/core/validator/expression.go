@@ -0,0 +1,851 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// ExpressionErrorKind 表达式错误类型
+type ExpressionErrorKind string
+
+const (
+	ExprErrorSyntax    ExpressionErrorKind = "syntax"    // 语法错误
+	ExprErrorForbidden ExpressionErrorKind = "forbidden" // 使用了白名单之外的节点、函数或字段
+	ExprErrorType      ExpressionErrorKind = "type"      // 操作数类型不匹配
+	ExprErrorLookup    ExpressionErrorKind = "lookup"    // 下标越界或键不存在
+	ExprErrorArgument  ExpressionErrorKind = "argument"  // 函数参数数量或取值错误
+)
+
+// ExpressionError 表达式校验或求值错误
+type ExpressionError struct {
+	Kind    ExpressionErrorKind `json:"kind"`    // 错误类型
+	Pos     int                 `json:"pos"`     // 出错位置（表达式中的字节偏移，-1表示未知）
+	Message string              `json:"message"` // 错误描述
+}
+
+func (e *ExpressionError) Error() string {
+	if e.Pos >= 0 {
+		return fmt.Sprintf("%s (位置 %d)", e.Message, e.Pos)
+	}
+	return e.Message
+}
+
+// newExprError 创建表达式错误
+func newExprError(kind ExpressionErrorKind, pos token.Pos, format string, args ...interface{}) *ExpressionError {
+	offset := -1
+	if pos.IsValid() {
+		offset = int(pos) - 1
+	}
+	return &ExpressionError{Kind: kind, Pos: offset, Message: fmt.Sprintf(format, args...)}
+}
+
+// compiledExpression 预处理并解析后的表达式
+type compiledExpression struct {
+	root ast.Expr
+	// inOps 记录由 in / not in 改写而来的 == 节点，值表示是否为 not in
+	inOps map[token.Pos]bool
+	// offsets 改写后表达式的字节偏移到原表达式字节偏移的映射（末尾多一项对应表达式结尾）
+	offsets []int
+}
+
+// rewriter 改写表达式并记录每个输出字节对应的原始偏移
+type rewriter struct {
+	out     []byte
+	offsets []int
+}
+
+// write 写入文本，所有字节都对应原始偏移 origin
+func (w *rewriter) write(text string, origin int) {
+	for i := 0; i < len(text); i++ {
+		w.out = append(w.out, text[i])
+		w.offsets = append(w.offsets, origin)
+	}
+}
+
+// compileExpression 将表达式预处理后解析为AST
+//
+// go/parser 不认识 Python 风格的 in/and/or/not 以及单引号字符串，这里先做改写：
+// 单引号字符串转为双引号，and/or 替换为 &&/||，in 替换为 == 并记录位置，
+// not 按 Python 优先级（低于比较运算、高于 and/or）改写为 !( ... )。
+// 改写时记录偏移映射，错误位置始终指向原表达式。
+func compileExpression(expression string) (*compiledExpression, error) {
+	normalized, quoteOffsets := normalizeQuotes(expression)
+	src := []byte(normalized)
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, 0)
+
+	type scannedToken struct {
+		tok    token.Token
+		lit    string
+		offset int
+		end    int
+	}
+	var tokens []scannedToken
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			// 扫描器在行尾自动插入的分号，源码中并不存在
+			continue
+		}
+		offset := file.Offset(pos)
+		end := offset + len(lit)
+		if lit == "" {
+			end = offset + len(tok.String())
+		}
+		tokens = append(tokens, scannedToken{tok: tok, lit: lit, offset: offset, end: end})
+	}
+
+	w := &rewriter{}
+	inOffsets := make(map[int]bool)
+	var notDepths []int // 尚未闭合的 not 所在的括号深度
+	depth, copied := 0, 0
+	closeNots := func(minDepth, origin int) {
+		for len(notDepths) > 0 && notDepths[len(notDepths)-1] >= minDepth {
+			w.write(")", origin)
+			notDepths = notDepths[:len(notDepths)-1]
+		}
+	}
+
+	for i, tk := range tokens {
+		keyword := ""
+		if tk.tok == token.IDENT && (i == 0 || tokens[i-1].tok != token.PERIOD) {
+			keyword = tk.lit
+		}
+
+		// not 的作用范围到同层的 and/or、逗号或闭括号为止
+		switch {
+		case keyword == "and" || keyword == "or" || tk.tok == token.COMMA:
+			closeNots(depth, tk.offset)
+		case tk.tok == token.RPAREN || tk.tok == token.RBRACK || tk.tok == token.RBRACE:
+			closeNots(depth, tk.offset)
+		}
+
+		// 复制上一个词法单元与当前词法单元之间的空白
+		for ; copied < tk.offset; copied++ {
+			w.write(string(src[copied]), copied)
+		}
+		copied = tk.end
+
+		switch {
+		case keyword == "and":
+			w.write("&&", tk.offset)
+		case keyword == "or":
+			w.write("||", tk.offset)
+		case keyword == "not" && i+1 < len(tokens) && tokens[i+1].tok == token.IDENT && tokens[i+1].lit == "in":
+			// not in：由后面的 in 处理
+		case keyword == "not":
+			w.write("!(", tk.offset)
+			notDepths = append(notDepths, depth)
+		case keyword == "in":
+			inOffsets[len(w.out)] = i > 0 && tokens[i-1].tok == token.IDENT && tokens[i-1].lit == "not"
+			w.write("==", tk.offset)
+		default:
+			w.write(string(src[tk.offset:tk.end]), tk.offset)
+		}
+
+		switch tk.tok {
+		case token.LPAREN, token.LBRACK, token.LBRACE:
+			depth++
+		case token.RPAREN, token.RBRACK, token.RBRACE:
+			depth--
+		}
+	}
+	for ; copied < len(src); copied++ {
+		w.write(string(src[copied]), copied)
+	}
+	closeNots(-1<<31, len(src))
+	w.offsets = append(w.offsets, len(src))
+
+	// 组合两次改写的偏移映射
+	offsets := make([]int, len(w.offsets))
+	for i, offset := range w.offsets {
+		offsets[i] = quoteOffsets[offset]
+	}
+	compiled := &compiledExpression{offsets: offsets}
+
+	root, err := parser.ParseExpr(string(w.out))
+	if err != nil {
+		pos := token.NoPos
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			pos = token.Pos(list[0].Pos.Offset + 1)
+		}
+		return nil, compiled.mapError(newExprError(ExprErrorSyntax, pos, "表达式语法错误: %v", err))
+	}
+
+	inOps := make(map[token.Pos]bool, len(inOffsets))
+	for offset, negated := range inOffsets {
+		inOps[token.Pos(offset+1)] = negated
+	}
+	compiled.root = root
+	compiled.inOps = inOps
+	return compiled, nil
+}
+
+// mapError 把错误中的位置从改写后的表达式映射回原表达式
+func (c *compiledExpression) mapError(err error) error {
+	exprErr, ok := err.(*ExpressionError)
+	if !ok || exprErr.Pos < 0 {
+		return err
+	}
+	if exprErr.Pos < len(c.offsets) {
+		exprErr.Pos = c.offsets[exprErr.Pos]
+	} else {
+		exprErr.Pos = c.offsets[len(c.offsets)-1]
+	}
+	return exprErr
+}
+
+// normalizeQuotes 将单引号字符串改写为双引号字符串，返回改写结果与偏移映射
+//
+// 单引号字符串中的 \' 还原为单引号，未转义的双引号补上反斜杠，其余转义原样保留。
+// 映射的第 i 项是输出第 i 个字节对应的原始偏移，末尾多一项对应表达式结尾。
+func normalizeQuotes(expression string) (string, []int) {
+	w := &rewriter{}
+	for i := 0; i < len(expression); i++ {
+		ch := expression[i]
+		switch ch {
+		case '"', '`':
+			// 原样保留双引号/反引号字符串
+			w.write(string(ch), i)
+			for i++; i < len(expression); i++ {
+				w.write(string(expression[i]), i)
+				if ch == '"' && expression[i] == '\\' && i+1 < len(expression) {
+					i++
+					w.write(string(expression[i]), i)
+					continue
+				}
+				if expression[i] == ch {
+					break
+				}
+			}
+		case '\'':
+			w.write(`"`, i)
+			for i++; i < len(expression); i++ {
+				c := expression[i]
+				if c == '\\' && i+1 < len(expression) {
+					if expression[i+1] == '\'' {
+						w.write("'", i)
+					} else {
+						w.write(expression[i:i+2], i)
+					}
+					i++
+					continue
+				}
+				if c == '\'' {
+					break
+				}
+				if c == '"' {
+					w.write(`\"`, i)
+					continue
+				}
+				w.write(string(c), i)
+			}
+			w.write(`"`, min(i, len(expression)))
+		default:
+			w.write(string(ch), i)
+		}
+	}
+	w.offsets = append(w.offsets, len(expression))
+	return string(w.out), w.offsets
+}
+
+// headerValues 响应头集合，键查找不区分大小写
+type headerValues map[string]string
+
+func (h headerValues) get(name string) (string, bool) {
+	if value, ok := h[name]; ok {
+		return value, true
+	}
+	if value, ok := h[http.CanonicalHeaderKey(name)]; ok {
+		return value, true
+	}
+	for key, value := range h {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// expressionEnv 表达式求值环境
+type expressionEnv struct {
+	response *models.ResponseData
+	compiled *compiledExpression
+}
+
+// evaluate 求值表达式并返回布尔结果
+func (env *expressionEnv) evaluate() (bool, error) {
+	value, err := env.eval(env.compiled.root)
+	if err != nil {
+		return false, err
+	}
+	return truthy(value), nil
+}
+
+// eval 递归求值AST节点
+//
+// 求值结果只会是 nil、bool、float64、string、[]interface{}、map[string]interface{} 或 headerValues。
+func (env *expressionEnv) eval(node ast.Expr) (interface{}, error) {
+	switch n := node.(type) {
+	case *ast.ParenExpr:
+		return env.eval(n.X)
+
+	case *ast.BasicLit:
+		return evalLiteral(n)
+
+	case *ast.Ident:
+		switch n.Name {
+		case "true", "True":
+			return true, nil
+		case "false", "False":
+			return false, nil
+		case "nil", "None":
+			return nil, nil
+		}
+		return nil, newExprError(ExprErrorForbidden, n.Pos(), "不允许的标识符: %s", n.Name)
+
+	case *ast.SelectorExpr:
+		return env.responseField(n)
+
+	case *ast.CallExpr:
+		return env.call(n)
+
+	case *ast.IndexExpr:
+		container, err := env.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		index, err := env.eval(n.Index)
+		if err != nil {
+			return nil, err
+		}
+		return indexValue(container, index, n.Lbrack)
+
+	case *ast.UnaryExpr:
+		operand, err := env.eval(n.X)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case token.NOT:
+			return !truthy(operand), nil
+		case token.SUB:
+			number, ok := operand.(float64)
+			if !ok {
+				return nil, newExprError(ExprErrorType, n.OpPos, "负号只能作用于数字，实际为 %s", typeName(operand))
+			}
+			return -number, nil
+		}
+		return nil, newExprError(ExprErrorForbidden, n.OpPos, "不允许的操作符: %s", n.Op)
+
+	case *ast.BinaryExpr:
+		return env.binary(n)
+	}
+
+	return nil, newExprError(ExprErrorForbidden, node.Pos(), "不支持的表达式类型: %T", node)
+}
+
+// binary 求值二元表达式
+func (env *expressionEnv) binary(n *ast.BinaryExpr) (interface{}, error) {
+	switch n.Op {
+	case token.LAND:
+		left, err := env.eval(n.X)
+		if err != nil || !truthy(left) {
+			return false, err
+		}
+		right, err := env.eval(n.Y)
+		if err != nil {
+			return false, err
+		}
+		return truthy(right), nil
+
+	case token.LOR:
+		left, err := env.eval(n.X)
+		if err != nil {
+			return false, err
+		}
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := env.eval(n.Y)
+		if err != nil {
+			return false, err
+		}
+		return truthy(right), nil
+	}
+
+	if !isComparison(n.Op) {
+		return nil, newExprError(ExprErrorForbidden, n.OpPos, "不允许的操作符: %s", n.Op)
+	}
+
+	// Python 风格的链式比较：200 <= response.status_code < 300
+	if inner, ok := n.X.(*ast.BinaryExpr); ok && isComparison(inner.Op) && !env.isInOp(n) && !env.isInOp(inner) {
+		first, err := env.binary(inner)
+		if err != nil || !truthy(first) {
+			return false, err
+		}
+		middle, err := env.eval(inner.Y)
+		if err != nil {
+			return nil, err
+		}
+		right, err := env.eval(n.Y)
+		if err != nil {
+			return nil, err
+		}
+		return compareValues(n.Op, middle, right, n.OpPos)
+	}
+
+	left, err := env.eval(n.X)
+	if err != nil {
+		return nil, err
+	}
+	right, err := env.eval(n.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	if negated, ok := env.compiled.inOps[n.OpPos]; ok {
+		contained, err := containsValue(right, left, n.OpPos)
+		if err != nil {
+			return nil, err
+		}
+		return contained != negated, nil
+	}
+
+	return compareValues(n.Op, left, right, n.OpPos)
+}
+
+// isInOp 判断二元表达式是否由 in 改写而来
+func (env *expressionEnv) isInOp(n *ast.BinaryExpr) bool {
+	_, ok := env.compiled.inOps[n.OpPos]
+	return ok
+}
+
+// responseField 读取 response.xxx 字段
+func (env *expressionEnv) responseField(n *ast.SelectorExpr) (interface{}, error) {
+	x, ok := n.X.(*ast.Ident)
+	if !ok || x.Name != "response" {
+		return nil, newExprError(ExprErrorForbidden, n.Pos(), "只允许访问response对象的字段")
+	}
+
+	resp := env.response
+	switch n.Sel.Name {
+	case "status_code":
+		return float64(resp.StatusCode), nil
+	case "text", "content":
+		return resp.Body, nil
+	case "headers":
+		return headerValues(resp.Headers), nil
+	case "cookies":
+		cookies := make(map[string]interface{}, len(resp.Cookies))
+		for _, cookie := range resp.Cookies {
+			cookies[cookie.Name] = cookie.Value
+		}
+		return cookies, nil
+	case "url":
+		return resp.URL, nil
+	case "elapsed":
+		return resp.Duration.Seconds(), nil
+	case "encoding":
+		return resp.DetectedEncoding, nil
+	case "reason":
+		return http.StatusText(resp.StatusCode), nil
+	case "history":
+		return redirectHistory(resp.Redirects), nil
+	}
+
+	return nil, newExprError(ExprErrorForbidden, n.Sel.Pos(), "不允许的response字段: %s", n.Sel.Name)
+}
+
+// redirectHistory 把重定向链转换为表达式可用的列表（与 requests 的 response.history 对应）
+func redirectHistory(hops []models.RedirectHop) []interface{} {
+	history := make([]interface{}, 0, len(hops))
+	for _, hop := range hops {
+		setCookies := make([]interface{}, 0, len(hop.SetCookies))
+		for _, cookie := range hop.SetCookies {
+			setCookies = append(setCookies, cookie)
+		}
+		history = append(history, map[string]interface{}{
+			"status_code": float64(hop.StatusCode),
+			"url":         hop.URL,
+			"location":    hop.Location,
+			"set_cookies": setCookies,
+		})
+	}
+	return history
+}
+
+// call 求值函数调用
+func (env *expressionEnv) call(n *ast.CallExpr) (interface{}, error) {
+	if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "response" || sel.Sel.Name != "json" {
+			return nil, newExprError(ExprErrorForbidden, sel.Pos(), "不允许的方法调用")
+		}
+		if len(n.Args) != 0 {
+			return nil, newExprError(ExprErrorArgument, n.Lparen, "response.json() 不接受参数")
+		}
+		return parseJSONValue(env.response.Body, n.Pos())
+	}
+
+	ident, ok := n.Fun.(*ast.Ident)
+	if !ok {
+		return nil, newExprError(ExprErrorForbidden, n.Fun.Pos(), "不允许的方法调用")
+	}
+
+	args := make([]interface{}, 0, len(n.Args))
+	for _, arg := range n.Args {
+		value, err := env.eval(arg)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+	if len(args) != 1 {
+		return nil, newExprError(ExprErrorArgument, n.Lparen, "函数 %s 需要1个参数，实际为 %d 个", ident.Name, len(args))
+	}
+
+	return callBuiltin(ident.Name, args[0], n.Pos())
+}
+
+// callBuiltin 执行白名单内置函数
+func callBuiltin(name string, arg interface{}, pos token.Pos) (interface{}, error) {
+	switch name {
+	case "len":
+		switch v := arg.(type) {
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		case headerValues:
+			return float64(len(v)), nil
+		}
+		return nil, newExprError(ExprErrorType, pos, "len() 不支持 %s 类型", typeName(arg))
+
+	case "str":
+		return stringify(arg), nil
+
+	case "int", "float":
+		var number float64
+		switch v := arg.(type) {
+		case float64:
+			number = v
+		case bool:
+			if v {
+				number = 1
+			}
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, newExprError(ExprErrorArgument, pos, "无法将 %q 转换为数字", v)
+			}
+			number = parsed
+		default:
+			return nil, newExprError(ExprErrorType, pos, "%s() 不支持 %s 类型", name, typeName(arg))
+		}
+		if name == "int" {
+			number = math.Trunc(number)
+		}
+		return number, nil
+
+	case "bool":
+		return truthy(arg), nil
+
+	case "lower", "upper", "strip":
+		text, ok := arg.(string)
+		if !ok {
+			return nil, newExprError(ExprErrorType, pos, "%s() 需要字符串参数，实际为 %s", name, typeName(arg))
+		}
+		switch name {
+		case "lower":
+			return strings.ToLower(text), nil
+		case "upper":
+			return strings.ToUpper(text), nil
+		}
+		return strings.TrimSpace(text), nil
+
+	case "json":
+		text, ok := arg.(string)
+		if !ok {
+			return nil, newExprError(ExprErrorType, pos, "json() 需要字符串参数，实际为 %s", typeName(arg))
+		}
+		return parseJSONValue(text, pos)
+	}
+
+	return nil, newExprError(ExprErrorForbidden, pos, "不允许的函数: %s", name)
+}
+
+// parseJSONValue 解析JSON文本为求值结果类型
+func parseJSONValue(text string, pos token.Pos) (interface{}, error) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		return nil, newExprError(ExprErrorType, pos, "响应内容不是有效的JSON: %v", err)
+	}
+	return data, nil
+}
+
+// evalLiteral 求值字面量
+func evalLiteral(lit *ast.BasicLit) (interface{}, error) {
+	switch lit.Kind {
+	case token.INT, token.FLOAT:
+		number, err := strconv.ParseFloat(lit.Value, 64)
+		if err != nil {
+			if intVal, intErr := strconv.ParseInt(lit.Value, 0, 64); intErr == nil {
+				return float64(intVal), nil
+			}
+			return nil, newExprError(ExprErrorSyntax, lit.Pos(), "无效的数字: %s", lit.Value)
+		}
+		return number, nil
+	case token.STRING, token.CHAR:
+		text, err := strconv.Unquote(lit.Value)
+		if err != nil {
+			return nil, newExprError(ExprErrorSyntax, lit.Pos(), "无效的字符串: %s", lit.Value)
+		}
+		return text, nil
+	}
+	return nil, newExprError(ExprErrorForbidden, lit.Pos(), "不支持的字面量: %s", lit.Value)
+}
+
+// indexValue 下标访问
+func indexValue(container, index interface{}, pos token.Pos) (interface{}, error) {
+	switch c := container.(type) {
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, newExprError(ExprErrorType, pos, "对象下标必须是字符串，实际为 %s", typeName(index))
+		}
+		value, exists := c[key]
+		if !exists {
+			return nil, newExprError(ExprErrorLookup, pos, "键不存在: %q", key)
+		}
+		return value, nil
+
+	case headerValues:
+		key, ok := index.(string)
+		if !ok {
+			return nil, newExprError(ExprErrorType, pos, "响应头下标必须是字符串，实际为 %s", typeName(index))
+		}
+		value, exists := c.get(key)
+		if !exists {
+			return nil, newExprError(ExprErrorLookup, pos, "响应头不存在: %q", key)
+		}
+		return value, nil
+
+	case []interface{}:
+		i, err := sequenceIndex(index, len(c), pos)
+		if err != nil {
+			return nil, err
+		}
+		return c[i], nil
+
+	case string:
+		runes := []rune(c)
+		i, err := sequenceIndex(index, len(runes), pos)
+		if err != nil {
+			return nil, err
+		}
+		return string(runes[i]), nil
+	}
+
+	return nil, newExprError(ExprErrorType, pos, "%s 类型不支持下标访问", typeName(container))
+}
+
+// sequenceIndex 计算列表/字符串下标（支持负数）
+func sequenceIndex(index interface{}, length int, pos token.Pos) (int, error) {
+	number, ok := index.(float64)
+	if !ok || number != math.Trunc(number) {
+		return 0, newExprError(ExprErrorType, pos, "列表下标必须是整数，实际为 %s", typeName(index))
+	}
+	i := int(number)
+	if i < 0 {
+		i += length
+	}
+	if i < 0 || i >= length {
+		return 0, newExprError(ExprErrorLookup, pos, "下标越界: %d (长度 %d)", int(number), length)
+	}
+	return i, nil
+}
+
+// containsValue 实现 in 操作符
+func containsValue(container, needle interface{}, pos token.Pos) (bool, error) {
+	switch c := container.(type) {
+	case string:
+		text, ok := needle.(string)
+		if !ok {
+			return false, newExprError(ExprErrorType, pos, "在字符串中查找时左侧必须是字符串，实际为 %s", typeName(needle))
+		}
+		return strings.Contains(c, text), nil
+	case []interface{}:
+		for _, item := range c {
+			if valuesEqual(item, needle) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := needle.(string)
+		if !ok {
+			return false, nil
+		}
+		_, exists := c[key]
+		return exists, nil
+	case headerValues:
+		key, ok := needle.(string)
+		if !ok {
+			return false, nil
+		}
+		_, exists := c.get(key)
+		return exists, nil
+	}
+	return false, newExprError(ExprErrorType, pos, "%s 类型不支持 in 操作", typeName(container))
+}
+
+// compareValues 比较两个值
+func compareValues(op token.Token, left, right interface{}, pos token.Pos) (bool, error) {
+	switch op {
+	case token.EQL:
+		return valuesEqual(left, right), nil
+	case token.NEQ:
+		return !valuesEqual(left, right), nil
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			return false, newExprError(ExprErrorType, pos, "无法比较 %s 和 %s", typeName(left), typeName(right))
+		}
+		switch {
+		case l < r:
+			cmp = -1
+		case l > r:
+			cmp = 1
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			return false, newExprError(ExprErrorType, pos, "无法比较 %s 和 %s", typeName(left), typeName(right))
+		}
+		cmp = strings.Compare(l, r)
+	default:
+		return false, newExprError(ExprErrorType, pos, "%s 类型不支持大小比较", typeName(left))
+	}
+
+	switch op {
+	case token.LSS:
+		return cmp < 0, nil
+	case token.LEQ:
+		return cmp <= 0, nil
+	case token.GTR:
+		return cmp > 0, nil
+	case token.GEQ:
+		return cmp >= 0, nil
+	}
+	return false, newExprError(ExprErrorForbidden, pos, "不允许的操作符: %s", op)
+}
+
+// valuesEqual 判断两个值是否相等
+func valuesEqual(left, right interface{}) bool {
+	if l, ok := left.(headerValues); ok {
+		left = map[string]string(l)
+	}
+	if r, ok := right.(headerValues); ok {
+		right = map[string]string(r)
+	}
+	return reflect.DeepEqual(left, right)
+}
+
+// isComparison 判断是否为比较操作符
+func isComparison(op token.Token) bool {
+	switch op {
+	case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+		return true
+	}
+	return false
+}
+
+// truthy 按 Python 规则判断真值
+func truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	case headerValues:
+		return len(v) > 0
+	}
+	return true
+}
+
+// stringify 将值转换为字符串（对应 str()）
+func stringify(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if v {
+			return "True"
+		}
+		return "False"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	case headerValues:
+		data, _ := json.Marshal(map[string]string(v))
+		return string(data)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// typeName 返回值的类型名称（用于错误提示）
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "object"
+	case headerValues:
+		return "headers"
+	}
+	return fmt.Sprintf("%T", value)
+}
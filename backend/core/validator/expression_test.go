@@ -0,0 +1,121 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func newTestResponse() *models.ResponseData {
+	return &models.ResponseData{
+		StatusCode: 200,
+		Headers: map[string]string{
+			"Content-Type": "application/json; charset=utf-8",
+		},
+		Body: `{"code":0,"msg":"ok","data":{"items":[1,2,3],"user":"alice"}}`,
+		URL:  "https://example.com/api",
+	}
+}
+
+func TestSafeValidator_EvaluateExpression(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+
+	cases := []struct {
+		expr string
+		want bool
+	}{
+		{`response.status_code == 200`, true},
+		{`response.status_code != 200`, false},
+		{`response.status_code == 200 && "ok" in response.text`, true},
+		{`response.status_code == 200 && "login" in response.text`, false},
+		{`response.status_code == 404 || "alice" in response.text`, true},
+		{`!("login" in response.text)`, true},
+		{`'login' not in response.text and response.status_code == 200`, true},
+		{`200 <= response.status_code < 300`, true},
+		{`300 <= response.status_code < 400`, false},
+		{`response.json()["code"] == 0`, true},
+		{`response.json()["data"]["user"] == "alice"`, true},
+		{`len(response.json()["data"]["items"]) == 3`, true},
+		{`response.json()["data"]["items"][-1] == 3`, true},
+		{`"user" in response.json()["data"]`, true},
+		{`lower(response.headers["content-type"]) == "application/json; charset=utf-8"`, true},
+		{`"Content-Type" in response.headers`, true},
+		{`int("42") > 41`, true},
+		{`upper(strip("  ok ")) == "OK"`, true},
+		{`json(response.text)["msg"] == "ok"`, true},
+		{`not response.status_code == 404`, true},
+		{`not "ok" in response.text`, false},
+		{`not "login" in response.text`, true},
+		{`response.status_code == 200 and not "log" in response.text`, true},
+		{`not response.status_code == 200 or "alice" in response.text`, true},
+		{`not (response.status_code == 200) and len(response.text) > 0`, false},
+		{`not not 200 <= response.status_code < 300`, true},
+		{`'it\'s' not in response.text`, true},
+		{`'say "hi"' != response.text`, true},
+	}
+
+	for _, tc := range cases {
+		got, err := v.EvaluateExpression(tc.expr, response)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.expr, err)
+		}
+		if got != tc.want {
+			t.Fatalf("%s: expected %v, got %v", tc.expr, tc.want, got)
+		}
+	}
+}
+
+func TestSafeValidator_EvaluateExpressionTypedErrors(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+
+	cases := []struct {
+		expr string
+		kind ExpressionErrorKind
+	}{
+		{`response.status_code ==`, ExprErrorSyntax},
+		{`os.Exit(1)`, ExprErrorForbidden},
+		{`response.secret == 1`, ExprErrorForbidden},
+		{`response.json()["missing"] == 1`, ExprErrorLookup},
+		{`response.text > 1`, ExprErrorType},
+		{`len(1, 2) == 1`, ExprErrorArgument},
+	}
+
+	for _, tc := range cases {
+		_, err := v.EvaluateExpression(tc.expr, response)
+		var exprErr *ExpressionError
+		if !errors.As(err, &exprErr) {
+			t.Fatalf("%s: expected *ExpressionError, got %v", tc.expr, err)
+		}
+		if exprErr.Kind != tc.kind {
+			t.Fatalf("%s: expected kind %q, got %q (%v)", tc.expr, tc.kind, exprErr.Kind, exprErr)
+		}
+	}
+}
+
+func TestSafeValidator_EvaluateExpressionErrorPosition(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+
+	cases := []struct {
+		expr string
+		pos  int
+	}{
+		{`'a"b' in response.text and response.secret == 1`, 36},
+		{`'it\'s' in response.text and not response.secret`, 42},
+		{`not response.text > 1`, 18},
+	}
+
+	for _, tc := range cases {
+		_, err := v.EvaluateExpression(tc.expr, response)
+		var exprErr *ExpressionError
+		if !errors.As(err, &exprErr) {
+			t.Fatalf("%s: expected *ExpressionError, got %v", tc.expr, err)
+		}
+		if exprErr.Pos != tc.pos {
+			t.Fatalf("%s: expected position %d, got %d (%v)", tc.expr, tc.pos, exprErr.Pos, exprErr)
+		}
+	}
+}
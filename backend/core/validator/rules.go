@@ -0,0 +1,345 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"RequestProbe/backend/models"
+)
+
+// errNoRulesEnabled 未启用任何验证规则
+var errNoRulesEnabled = errors.New("验证配置错误：未启用任何验证规则\n请在前端界面中配置以下验证方式之一：\n1. 文本匹配验证：检查响应中是否包含特定文本\n2. 长度范围验证：检查响应长度是否在指定范围内\n3. 自定义表达式验证：使用自定义表达式进行验证")
+
+// effectiveRules 返回本次评估使用的规则及顶层组合方式
+//
+// 配置了 Rules 时直接使用；否则把旧版单一规则配置转换为等价规则：
+// 自定义表达式单独生效，其余情况为 2xx 状态码 AND 已启用的文本匹配/长度范围。
+func effectiveRules(config *models.ValidationConfig) ([]models.ValidationRule, string, error) {
+	if len(config.Rules) > 0 {
+		return config.Rules, normalizeLogic(config.RuleLogic), nil
+	}
+
+	if config.UseCustomExpr && config.Expression != "" {
+		return []models.ValidationRule{{
+			Type:       models.RuleTypeExpression,
+			Name:       "自定义表达式",
+			Expression: config.Expression,
+		}}, "and", nil
+	}
+
+	if !config.TextMatching.Enabled && !config.LengthRange.Enabled {
+		return nil, "and", errNoRulesEnabled
+	}
+
+	rules := []models.ValidationRule{{
+		Type:         models.RuleTypeStatusCode,
+		Name:         "2xx状态码",
+		StatusRanges: []models.StatusCodeRange{{Min: 200, Max: 299}},
+	}}
+	if config.TextMatching.Enabled {
+		textMatching := config.TextMatching
+		rules = append(rules, models.ValidationRule{Type: models.RuleTypeTextMatch, Name: "文本匹配", TextMatching: &textMatching})
+	}
+	if config.LengthRange.Enabled {
+		lengthRange := config.LengthRange
+		rules = append(rules, models.ValidationRule{Type: models.RuleTypeLengthRange, Name: "长度范围", LengthRange: &lengthRange})
+	}
+	return rules, "and", nil
+}
+
+// normalizeLogic 规范化组合方式，默认为 and
+func normalizeLogic(logic string) string {
+	if strings.EqualFold(strings.TrimSpace(logic), "or") {
+		return "or"
+	}
+	return "and"
+}
+
+// evaluateRules 按顺序评估一组规则并按组合方式汇总
+//
+// 所有规则都会被评估（不短路），以便结果中包含完整的判定明细。
+func (v *SafeValidator) evaluateRules(rules []models.ValidationRule, logic string, response *models.ResponseData) (bool, []models.RuleVerdict, error) {
+	verdicts := make([]models.RuleVerdict, 0, len(rules))
+	passed := logic == "and"
+
+	for _, rule := range rules {
+		verdict, err := v.evaluateRule(rule, response)
+		if err != nil {
+			return false, verdicts, err
+		}
+		verdicts = append(verdicts, verdict)
+
+		if logic == "or" {
+			passed = passed || verdict.Passed
+		} else {
+			passed = passed && verdict.Passed
+		}
+	}
+
+	if len(rules) == 0 {
+		passed = false
+	}
+	return passed, verdicts, nil
+}
+
+// evaluateRule 评估单条规则
+//
+// 返回的 error 只表示规则配置本身有误（如无效正则、表达式语法错误），
+// 运行期不满足条件（如JSON取值不存在）记录为未通过的判定。
+func (v *SafeValidator) evaluateRule(rule models.ValidationRule, response *models.ResponseData) (models.RuleVerdict, error) {
+	verdict := models.RuleVerdict{Type: rule.Type, Name: rule.Name}
+	if verdict.Name == "" {
+		verdict.Name = string(rule.Type)
+	}
+
+	switch rule.Type {
+	case models.RuleTypeGroup:
+		logic := normalizeLogic(rule.Logic)
+		passed, children, err := v.evaluateRules(rule.Rules, logic, response)
+		if err != nil {
+			return verdict, err
+		}
+		verdict.Passed = passed
+		verdict.Rules = children
+		verdict.Message = fmt.Sprintf("%d 条子规则按 %s 组合", len(rule.Rules), strings.ToUpper(logic))
+
+	case models.RuleTypeStatusCode:
+		verdict.Passed = statusCodeAllowed(rule, response.StatusCode)
+		verdict.Message = fmt.Sprintf("状态码 %d", response.StatusCode)
+
+	case models.RuleTypeTextMatch:
+		if rule.TextMatching == nil {
+			return verdict, fmt.Errorf("规则 %s 缺少文本匹配参数", verdict.Name)
+		}
+		passed, captures, err := v.checkTextMatching(*rule.TextMatching, response.Body)
+		if err != nil {
+			return verdict, fmt.Errorf("规则 %s: %v", verdict.Name, err)
+		}
+		verdict.Passed = passed
+		verdict.Captures = captures
+		if verdict.Passed {
+			verdict.Message = "文本匹配通过"
+		} else {
+			verdict.Message = "文本匹配未通过"
+		}
+
+	case models.RuleTypeLengthRange:
+		if rule.LengthRange == nil {
+			return verdict, fmt.Errorf("规则 %s 缺少长度范围参数", verdict.Name)
+		}
+		verdict.Passed = v.checkLengthRange(*rule.LengthRange, response.Body)
+		verdict.Message = fmt.Sprintf("响应长度 %d", len(response.Body))
+
+	case models.RuleTypeHeader:
+		if rule.HeaderName == "" {
+			return verdict, fmt.Errorf("规则 %s 缺少响应头名称", verdict.Name)
+		}
+		value, exists := headerValues(response.Headers).get(rule.HeaderName)
+		switch {
+		case !exists:
+			verdict.Message = fmt.Sprintf("响应头 %s 不存在", rule.HeaderName)
+		case strings.EqualFold(rule.HeaderMatch, "equals"):
+			verdict.Passed = value == rule.HeaderValue
+			verdict.Message = fmt.Sprintf("响应头 %s = %q", rule.HeaderName, value)
+		default:
+			verdict.Passed = true
+			verdict.Message = fmt.Sprintf("响应头 %s 存在", rule.HeaderName)
+		}
+
+	case models.RuleTypeRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return verdict, fmt.Errorf("规则 %s 正则表达式无效: %v", verdict.Name, err)
+		}
+		verdict.Passed = re.MatchString(response.Body)
+		if verdict.Passed {
+			verdict.Message = "正则匹配成功"
+		} else {
+			verdict.Message = "正则未匹配"
+		}
+
+	case models.RuleTypeJSONPath:
+		steps, err := parseJSONPath(rule.JSONPath)
+		if err != nil {
+			return verdict, fmt.Errorf("规则 %s JSONPath无效: %v", verdict.Name, err)
+		}
+		var data interface{}
+		if err := json.Unmarshal([]byte(response.Body), &data); err != nil {
+			verdict.Message = "响应内容不是有效的JSON"
+			break
+		}
+		actual, err := lookupJSONPath(data, steps)
+		if err != nil {
+			verdict.Message = err.Error()
+			break
+		}
+		verdict.Passed = jsonValueEquals(actual, rule.ExpectedValue)
+		verdict.Message = fmt.Sprintf("%s = %s", rule.JSONPath, stringify(actual))
+
+	case models.RuleTypeExpression:
+		passed, err := v.EvaluateExpression(rule.Expression, response)
+		if err != nil {
+			var exprErr *ExpressionError
+			if errors.As(err, &exprErr) && (exprErr.Kind == ExprErrorSyntax || exprErr.Kind == ExprErrorForbidden) {
+				return verdict, err
+			}
+			verdict.Message = err.Error()
+			break
+		}
+		verdict.Passed = passed
+		verdict.Message = fmt.Sprintf("表达式结果 %t", passed)
+
+	case models.RuleTypeRedirect:
+		if rule.MaxRedirects == nil && len(rule.URLExcludes) == 0 {
+			return verdict, fmt.Errorf("规则 %s 缺少重定向约束", verdict.Name)
+		}
+		verdict.Passed, verdict.Message = checkRedirectChain(rule, response)
+
+	default:
+		return verdict, fmt.Errorf("不支持的验证规则类型: %s", rule.Type)
+	}
+
+	return verdict, nil
+}
+
+// checkRedirectChain 检查跳转次数与跳转链中的URL（含最终URL）
+func checkRedirectChain(rule models.ValidationRule, response *models.ResponseData) (bool, string) {
+	hops := len(response.Redirects)
+	if rule.MaxRedirects != nil && hops > *rule.MaxRedirects {
+		return false, fmt.Sprintf("跳转 %d 次，超过上限 %d 次", hops, *rule.MaxRedirects)
+	}
+
+	urls := make([]string, 0, hops+1)
+	for _, hop := range response.Redirects {
+		urls = append(urls, hop.Location)
+	}
+	urls = append(urls, response.URL)
+	for _, exclude := range rule.URLExcludes {
+		if exclude == "" {
+			continue
+		}
+		for _, u := range urls {
+			if strings.Contains(u, exclude) {
+				return false, fmt.Sprintf("跳转链包含 %q: %s", exclude, u)
+			}
+		}
+	}
+	return true, fmt.Sprintf("跳转 %d 次，最终URL %s", hops, response.URL)
+}
+
+// statusCodeAllowed 检查状态码是否在规则允许的集合或范围内
+func statusCodeAllowed(rule models.ValidationRule, statusCode int) bool {
+	for _, code := range rule.StatusCodes {
+		if code == statusCode {
+			return true
+		}
+	}
+	for _, r := range rule.StatusRanges {
+		if statusCode >= r.Min && statusCode <= r.Max {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonPathStep JSONPath 的一级取值（键或下标）
+type jsonPathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// parseJSONPath 解析简化的 JSONPath：$.a.b[0]['c'] 或 a.b[0]
+func parseJSONPath(path string) ([]jsonPathStep, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	if path == "" {
+		return nil, nil
+	}
+
+	var steps []jsonPathStep
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("路径中存在空的键: %s", path)
+			}
+			steps = append(steps, jsonPathStep{key: path[start:i]})
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("缺少 ]: %s", path)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			i += end + 1
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				steps = append(steps, jsonPathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil {
+				return nil, fmt.Errorf("无效的下标: %s", inner)
+			}
+			steps = append(steps, jsonPathStep{index: index, isIndex: true})
+		default:
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			steps = append(steps, jsonPathStep{key: path[start:i]})
+		}
+	}
+	return steps, nil
+}
+
+// lookupJSONPath 按路径从JSON数据中取值
+func lookupJSONPath(data interface{}, steps []jsonPathStep) (interface{}, error) {
+	current := data
+	for _, step := range steps {
+		if step.isIndex {
+			list, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("无法对 %s 使用下标 [%d]", typeName(current), step.index)
+			}
+			index := step.index
+			if index < 0 {
+				index += len(list)
+			}
+			if index < 0 || index >= len(list) {
+				return nil, fmt.Errorf("下标越界: %d", step.index)
+			}
+			current = list[index]
+			continue
+		}
+
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("无法在 %s 中查找键 %q", typeName(current), step.key)
+		}
+		value, exists := object[step.key]
+		if !exists {
+			return nil, fmt.Errorf("键不存在: %q", step.key)
+		}
+		current = value
+	}
+	return current, nil
+}
+
+// jsonValueEquals 比较JSON取值与期望值
+func jsonValueEquals(actual interface{}, expected string) bool {
+	var expectedValue interface{}
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err == nil && reflect.DeepEqual(actual, expectedValue) {
+		return true
+	}
+	return stringify(actual) == expected
+}
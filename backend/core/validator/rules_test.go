@@ -0,0 +1,143 @@
+package validator
+
+import (
+	"testing"
+
+	"RequestProbe/backend/models"
+)
+
+func TestSafeValidator_EvaluateConfigLegacyChecksTextAndLength(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+
+	config := &models.ValidationConfig{
+		TextMatching: models.TextMatchingConfig{Enabled: true, Texts: []string{"alice"}, MatchMode: "all"},
+		LengthRange:  models.LengthRangeConfig{Enabled: true, MinLength: 1000, MaxLength: -1},
+	}
+
+	verdict, err := v.EvaluateConfig(config, response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if verdict.Passed {
+		t.Fatalf("expected length rule to fail the verdict, got %#v", verdict)
+	}
+	if len(verdict.Rules) != 3 || !verdict.Rules[0].Passed || !verdict.Rules[1].Passed || verdict.Rules[2].Passed {
+		t.Fatalf("unexpected rule breakdown: %#v", verdict.Rules)
+	}
+}
+
+func TestSafeValidator_EvaluateConfigRuleGroups(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+
+	config := &models.ValidationConfig{
+		Rules: []models.ValidationRule{
+			{Type: models.RuleTypeStatusCode, StatusCodes: []int{200, 304}},
+			{Type: models.RuleTypeHeader, HeaderName: "content-type"},
+			{
+				Type:  models.RuleTypeGroup,
+				Logic: "or",
+				Rules: []models.ValidationRule{
+					{Type: models.RuleTypeRegex, Pattern: `"code":\s*1`},
+					{Type: models.RuleTypeJSONPath, JSONPath: "$.data.items[2]", ExpectedValue: "3"},
+				},
+			},
+		},
+	}
+
+	verdict, err := v.EvaluateConfig(config, response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !verdict.Passed {
+		t.Fatalf("expected verdict to pass, got %#v", verdict)
+	}
+	group := verdict.Rules[2]
+	if len(group.Rules) != 2 || group.Rules[0].Passed || !group.Rules[1].Passed {
+		t.Fatalf("unexpected group breakdown: %#v", group)
+	}
+
+	config.Rules = append(config.Rules, models.ValidationRule{Type: models.RuleTypeRegex, Pattern: "("})
+	if _, err := v.EvaluateConfig(config, response); err == nil {
+		t.Fatalf("expected invalid regex to be reported as config error")
+	}
+}
+
+func TestSafeValidator_checkTextMatchingModes(t *testing.T) {
+	v := NewSafeValidator()
+	body := `<html><title>Dashboard</title><span id="uid">10086</span></html>`
+
+	passed, captures, err := v.checkTextMatching(models.TextMatchingConfig{
+		MatchMode: "all",
+		Texts:     []string{"dashboard"},
+		Patterns: []models.TextPattern{
+			{Text: "请登录", Mode: models.TextMatchNotContains},
+			{Text: `id="uid">(?P<uid>\d+)<`, Mode: models.TextMatchRegex},
+			{Text: `<form[^>]*login`, Mode: models.TextMatchNotRegex},
+		},
+	}, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !passed {
+		t.Fatalf("expected all patterns to match")
+	}
+	if captures["uid"] != "10086" {
+		t.Fatalf("expected uid capture, got %#v", captures)
+	}
+
+	passed, _, err = v.checkTextMatching(models.TextMatchingConfig{
+		MatchMode: "all",
+		Patterns:  []models.TextPattern{{Text: "Dashboard", Mode: models.TextMatchNotContains}},
+	}, body)
+	if err != nil || passed {
+		t.Fatalf("expected not-contains to fail, passed=%v err=%v", passed, err)
+	}
+
+	if _, _, err := v.checkTextMatching(models.TextMatchingConfig{
+		Patterns: []models.TextPattern{{Text: "(", Mode: models.TextMatchRegex}},
+	}, body); err == nil {
+		t.Fatalf("expected invalid regex error")
+	}
+}
+
+func TestSafeValidator_EvaluateRedirectRule(t *testing.T) {
+	v := NewSafeValidator()
+	response := newTestResponse()
+	response.URL = "https://example.com/login?next=/api"
+	response.Redirects = []models.RedirectHop{
+		{StatusCode: 302, URL: "https://example.com/api", Location: "https://example.com/login?next=/api"},
+	}
+
+	zero := 0
+	cases := []struct {
+		rule models.ValidationRule
+		want bool
+	}{
+		{models.ValidationRule{Type: models.RuleTypeRedirect, URLExcludes: []string{"/login"}}, false},
+		{models.ValidationRule{Type: models.RuleTypeRedirect, URLExcludes: []string{"/logout"}}, true},
+		{models.ValidationRule{Type: models.RuleTypeRedirect, MaxRedirects: &zero}, false},
+	}
+	for _, tc := range cases {
+		verdict, err := v.EvaluateConfig(&models.ValidationConfig{Rules: []models.ValidationRule{tc.rule}}, response)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if verdict.Passed != tc.want {
+			t.Fatalf("rule %#v: expected %v, got %#v", tc.rule, tc.want, verdict)
+		}
+	}
+
+	if _, err := v.EvaluateConfig(&models.ValidationConfig{Rules: []models.ValidationRule{{Type: models.RuleTypeRedirect}}}, response); err == nil {
+		t.Fatalf("expected redirect rule without constraints to be a config error")
+	}
+
+	passed, err := v.EvaluateExpression(`len(response.history) == 1 and response.history[0]["status_code"] == 302 and "/login" not in response.url`, response)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if passed {
+		t.Fatalf("expected expression to fail on login redirect")
+	}
+}
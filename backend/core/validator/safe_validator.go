@@ -1,11 +1,10 @@
 package validator
 
 import (
-	"encoding/json"
 	"fmt"
 	"go/ast"
-	"go/parser"
-	"reflect"
+	"go/token"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -52,18 +51,27 @@ func NewSafeValidator() *SafeValidator {
 
 // ValidateExpression 验证表达式安全性
 func (v *SafeValidator) ValidateExpression(expression string) error {
+	_, err := v.compile(expression)
+	return err
+}
+
+// compile 解析表达式并检查AST节点安全性
+func (v *SafeValidator) compile(expression string) (*compiledExpression, error) {
 	if strings.TrimSpace(expression) == "" {
-		return fmt.Errorf("验证表达式不能为空")
+		return nil, &ExpressionError{Kind: ExprErrorSyntax, Pos: -1, Message: "验证表达式不能为空"}
 	}
 
 	// 解析表达式为AST
-	expr, err := parser.ParseExpr(expression)
+	compiled, err := compileExpression(expression)
 	if err != nil {
-		return fmt.Errorf("表达式语法错误: %v", err)
+		return nil, err
 	}
 
 	// 检查AST节点安全性
-	return v.validateASTNode(expr)
+	if err := v.validateASTNode(compiled.root); err != nil {
+		return nil, compiled.mapError(err)
+	}
+	return compiled, nil
 }
 
 // validateASTNode 验证AST节点
@@ -80,7 +88,7 @@ func (v *SafeValidator) validateASTNode(node ast.Node) error {
 
 		op := n.Op.String()
 		if !v.allowedOperators[op] {
-			return fmt.Errorf("不允许的操作符: %s", op)
+			return newExprError(ExprErrorForbidden, n.OpPos, "不允许的操作符: %s", op)
 		}
 
 	case *ast.UnaryExpr:
@@ -89,27 +97,34 @@ func (v *SafeValidator) validateASTNode(node ast.Node) error {
 			return err
 		}
 
+		// 负号只允许用于数字字面量（如列表下标 -1）
+		if lit, ok := n.X.(*ast.BasicLit); ok && n.Op == token.SUB && (lit.Kind == token.INT || lit.Kind == token.FLOAT) {
+			break
+		}
+
 		op := n.Op.String()
 		if !v.allowedOperators[op] {
-			return fmt.Errorf("不允许的操作符: %s", op)
+			return newExprError(ExprErrorForbidden, n.OpPos, "不允许的操作符: %s", op)
 		}
 
 	case *ast.CallExpr:
 		// 验证函数调用
 		if ident, ok := n.Fun.(*ast.Ident); ok {
 			if !v.allowedFunctions[ident.Name] {
-				return fmt.Errorf("不允许的函数: %s", ident.Name)
+				return newExprError(ExprErrorForbidden, ident.Pos(), "不允许的函数: %s", ident.Name)
 			}
 		} else if sel, ok := n.Fun.(*ast.SelectorExpr); ok {
 			// 允许response.method()形式的调用
 			if x, ok := sel.X.(*ast.Ident); ok && x.Name == "response" {
 				// 验证response对象的方法调用
 				if !v.isAllowedResponseMethod(sel.Sel.Name) {
-					return fmt.Errorf("不允许的response方法: %s", sel.Sel.Name)
+					return newExprError(ExprErrorForbidden, sel.Sel.Pos(), "不允许的response方法: %s", sel.Sel.Name)
 				}
 			} else {
-				return fmt.Errorf("不允许的方法调用")
+				return newExprError(ExprErrorForbidden, sel.Pos(), "不允许的方法调用")
 			}
+		} else {
+			return newExprError(ExprErrorForbidden, n.Fun.Pos(), "不允许的方法调用")
 		}
 
 		// 验证参数
@@ -123,16 +138,23 @@ func (v *SafeValidator) validateASTNode(node ast.Node) error {
 		// 验证选择器表达式 (如 response.status_code)
 		if x, ok := n.X.(*ast.Ident); ok && x.Name == "response" {
 			if !v.isAllowedResponseField(n.Sel.Name) {
-				return fmt.Errorf("不允许的response字段: %s", n.Sel.Name)
+				return newExprError(ExprErrorForbidden, n.Sel.Pos(), "不允许的response字段: %s", n.Sel.Name)
 			}
 		} else {
-			return fmt.Errorf("只允许访问response对象的字段")
+			return newExprError(ExprErrorForbidden, n.Pos(), "只允许访问response对象的字段")
+		}
+
+	case *ast.IndexExpr:
+		// 验证下标访问 (如 response.json()["data"]、response.headers["Content-Type"])
+		if err := v.validateASTNode(n.X); err != nil {
+			return err
 		}
+		return v.validateASTNode(n.Index)
 
 	case *ast.Ident:
-		// 验证标识符
-		if n.Name != "response" && !v.isBuiltinConstant(n.Name) {
-			return fmt.Errorf("不允许的标识符: %s", n.Name)
+		// 验证标识符（response 只能作为选择器的接收者出现）
+		if !v.isBuiltinConstant(n.Name) {
+			return newExprError(ExprErrorForbidden, n.Pos(), "不允许的标识符: %s", n.Name)
 		}
 
 	case *ast.BasicLit:
@@ -144,7 +166,7 @@ func (v *SafeValidator) validateASTNode(node ast.Node) error {
 		return v.validateASTNode(n.X)
 
 	default:
-		return fmt.Errorf("不支持的表达式类型: %T", n)
+		return newExprError(ExprErrorForbidden, n.Pos(), "不支持的表达式类型: %T", n)
 	}
 
 	return nil
@@ -162,6 +184,7 @@ func (v *SafeValidator) isAllowedResponseField(field string) bool {
 		"elapsed":     true,
 		"encoding":    true,
 		"reason":      true,
+		"history":     true,
 	}
 	return allowedFields[field]
 }
@@ -180,96 +203,131 @@ func (v *SafeValidator) isBuiltinConstant(name string) bool {
 		"true":  true,
 		"false": true,
 		"nil":   true,
+		"True":  true,
+		"False": true,
+		"None":  true,
 	}
 	return constants[name]
 }
 
-// EvaluateExpression 评估验证表达式（保持兼容性）
+// EvaluateExpression 评估验证表达式
+//
+// 表达式在 go/ast 白名单基础上逐节点求值，支持 &&/||/!（及 and/or/not）、比较运算、
+// in / not in、白名单函数以及对 response.json() 和 response.headers 的下标访问。
+// 校验和求值失败时返回 *ExpressionError。
 func (v *SafeValidator) EvaluateExpression(expression string, response *models.ResponseData) (bool, error) {
 	// 首先验证表达式安全性
-	if err := v.ValidateExpression(expression); err != nil {
+	compiled, err := v.compile(expression)
+	if err != nil {
 		return false, err
 	}
 
-	// 创建响应对象的映射
-	responseMap := v.createResponseMap(response)
-
-	// 简单的表达式评估器
-	result, err := v.evaluateSimpleExpression(expression, responseMap)
+	env := &expressionEnv{response: response, compiled: compiled}
+	passed, err := env.evaluate()
 	if err != nil {
-		return false, fmt.Errorf("表达式评估失败: %v", err)
+		return false, compiled.mapError(err)
 	}
-
-	return result, nil
+	return passed, nil
 }
 
-// EvaluateConfig 使用新的配置系统评估响应
-func (v *SafeValidator) EvaluateConfig(config *models.ValidationConfig, response *models.ResponseData) (bool, error) {
-	// 如果使用自定义表达式
-	if config.UseCustomExpr && config.Expression != "" {
-		result, err := v.EvaluateExpression(config.Expression, response)
-		return result, err
-	}
-
-	// 首先检查基本状态码：必须是2xx成功状态码
-	if response.StatusCode < 200 || response.StatusCode >= 300 {
-		return false, nil
-	}
-
-	// 检查文本匹配（如果启用）
-	if config.TextMatching.Enabled {
-		result := v.checkTextMatching(config.TextMatching, response.Body)
-		return result, nil
+// EvaluateConfig 使用新的配置系统评估响应，返回各规则的判定明细
+func (v *SafeValidator) EvaluateConfig(config *models.ValidationConfig, response *models.ResponseData) (*models.ValidationVerdict, error) {
+	rules, logic, err := effectiveRules(config)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查长度范围（如果启用）
-	if config.LengthRange.Enabled {
-		result := v.checkLengthRange(config.LengthRange, response.Body)
-		return result, nil
+	passed, verdicts, err := v.evaluateRules(rules, logic, response)
+	if err != nil {
+		return nil, err
 	}
 
-	// 如果没有启用任何特定验证，返回详细的错误提示
-	return false, fmt.Errorf("验证配置错误：未启用任何验证规则\n请在前端界面中配置以下验证方式之一：\n1. 文本匹配验证：检查响应中是否包含特定文本\n2. 长度范围验证：检查响应长度是否在指定范围内\n3. 自定义表达式验证：使用自定义表达式进行验证")
+	return &models.ValidationVerdict{
+		Passed: passed,
+		Logic:  logic,
+		Rules:  verdicts,
+	}, nil
 }
 
 // checkTextMatching 检查文本匹配
-func (v *SafeValidator) checkTextMatching(config models.TextMatchingConfig, responseBody string) bool {
-	// 如果没有配置匹配文本，默认认为成功（只要有响应内容）
-	if len(config.Texts) == 0 {
-		return len(responseBody) > 0
+//
+// responseBody 应为 autoDetectAndDecodeResponse 解码后的文本。Texts 中的每一项按 contains 处理，
+// Patterns 中的每一项按各自的模式处理；正则模式匹配成功时返回捕获组（命名组用组名，其余用序号）。
+// 返回的 error 表示配置中的正则表达式无效。
+func (v *SafeValidator) checkTextMatching(config models.TextMatchingConfig, responseBody string) (bool, map[string]string, error) {
+	patterns := make([]models.TextPattern, 0, len(config.Texts)+len(config.Patterns))
+	for _, text := range config.Texts {
+		if text != "" {
+			patterns = append(patterns, models.TextPattern{Text: text, Mode: models.TextMatchContains})
+		}
+	}
+	for _, pattern := range config.Patterns {
+		if pattern.Text != "" {
+			patterns = append(patterns, pattern)
+		}
 	}
 
-	text := responseBody
-	if !config.CaseSensitive {
-		text = strings.ToLower(text)
+	// 如果没有配置匹配文本，默认认为成功（只要有响应内容）
+	if len(patterns) == 0 {
+		return len(responseBody) > 0, nil, nil
 	}
 
+	lowerBody := strings.ToLower(responseBody)
+	captures := make(map[string]string)
 	matchCount := 0
-	for _, searchText := range config.Texts {
-		if searchText == "" {
-			continue
+	for _, pattern := range patterns {
+		var matched bool
+
+		switch pattern.Mode {
+		case models.TextMatchRegex, models.TextMatchNotRegex:
+			expr := pattern.Text
+			if !config.CaseSensitive {
+				expr = "(?i)" + expr
+			}
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return false, nil, fmt.Errorf("无效的正则表达式 %q: %v", pattern.Text, err)
+			}
+			groups := re.FindStringSubmatch(responseBody)
+			matched = groups != nil
+			if matched && pattern.Mode == models.TextMatchRegex {
+				names := re.SubexpNames()
+				for i := 1; i < len(groups); i++ {
+					name := names[i]
+					if name == "" {
+						name = strconv.Itoa(i)
+					}
+					captures[name] = groups[i]
+				}
+			}
+		default:
+			if config.CaseSensitive {
+				matched = strings.Contains(responseBody, pattern.Text)
+			} else {
+				matched = strings.Contains(lowerBody, strings.ToLower(pattern.Text))
+			}
 		}
 
-		checkText := searchText
-		if !config.CaseSensitive {
-			checkText = strings.ToLower(checkText)
+		if pattern.Mode == models.TextMatchNotContains || pattern.Mode == models.TextMatchNotRegex {
+			matched = !matched
 		}
 
-		if strings.Contains(text, checkText) {
+		if matched {
 			matchCount++
-			if config.MatchMode == "any" {
-				return true // 任意匹配模式，找到一个就返回true
-			}
 		}
 	}
 
+	if len(captures) == 0 {
+		captures = nil
+	}
+
 	// 全部匹配模式，需要所有文本都匹配
 	if config.MatchMode == "all" {
-		return matchCount == len(config.Texts)
+		return matchCount == len(patterns), captures, nil
 	}
 
 	// 默认为任意匹配模式
-	return matchCount > 0
+	return matchCount > 0, captures, nil
 }
 
 // checkLengthRange 检查长度范围
@@ -287,121 +345,6 @@ func (v *SafeValidator) checkLengthRange(config models.LengthRangeConfig, respon
 	return true
 }
 
-// createResponseMap 创建响应数据映射
-func (v *SafeValidator) createResponseMap(response *models.ResponseData) map[string]interface{} {
-	responseMap := map[string]interface{}{
-		"status_code": response.StatusCode,
-		"text":        response.Body,
-		"content":     response.Body,
-		"headers":     response.Headers,
-		"cookies":     response.Cookies,
-		"url":         response.URL,
-		"elapsed":     response.Duration,
-	}
-
-	// 添加json()方法的模拟
-	if response.Body != "" {
-		var jsonData interface{}
-		if err := json.Unmarshal([]byte(response.Body), &jsonData); err == nil {
-			responseMap["json"] = jsonData
-		}
-	}
-
-	return responseMap
-}
-
-// evaluateSimpleExpression 简单表达式评估器
-func (v *SafeValidator) evaluateSimpleExpression(expression string, responseMap map[string]interface{}) (bool, error) {
-	// 这里实现一个简化的表达式评估器
-	// 在实际项目中，可以使用更完善的表达式引擎
-
-	// 替换response.字段为实际值
-	expr := expression
-
-	// 处理status_code
-	if statusCode, ok := responseMap["status_code"].(int); ok {
-		expr = strings.ReplaceAll(expr, "response.status_code", strconv.Itoa(statusCode))
-	}
-
-	// 处理简单的比较表达式
-	if strings.Contains(expr, "==") {
-		parts := strings.Split(expr, "==")
-		if len(parts) == 2 {
-			left := strings.TrimSpace(parts[0])
-			right := strings.TrimSpace(parts[1])
-
-			leftVal, err := v.parseValue(left, responseMap)
-			if err != nil {
-				return false, err
-			}
-
-			rightVal, err := v.parseValue(right, responseMap)
-			if err != nil {
-				return false, err
-			}
-
-			return reflect.DeepEqual(leftVal, rightVal), nil
-		}
-	}
-
-	// 处理范围比较 (如 200 <= status_code < 300)
-	if strings.Contains(expr, "<=") && strings.Contains(expr, "<") {
-		// 简化处理状态码范围
-		if statusCode, ok := responseMap["status_code"].(int); ok {
-			if statusCode >= 200 && statusCode < 300 {
-				return true, nil
-			}
-		}
-		return false, nil
-	}
-
-	// 处理包含检查
-	if strings.Contains(expr, " in ") {
-		parts := strings.Split(expr, " in ")
-		if len(parts) == 2 {
-			needle := strings.Trim(strings.TrimSpace(parts[0]), "\"'")
-			haystack := strings.TrimSpace(parts[1])
-
-			if haystack == "response.text" {
-				if text, ok := responseMap["text"].(string); ok {
-					return strings.Contains(text, needle), nil
-				}
-			}
-		}
-	}
-
-	return false, fmt.Errorf("不支持的表达式格式")
-}
-
-// parseValue 解析值
-func (v *SafeValidator) parseValue(value string, responseMap map[string]interface{}) (interface{}, error) {
-	value = strings.TrimSpace(value)
-
-	// 数字
-	if intVal, err := strconv.Atoi(value); err == nil {
-		return intVal, nil
-	}
-
-	// 字符串
-	if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-		return strings.Trim(value, "\""), nil
-	}
-
-	if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
-		return strings.Trim(value, "'"), nil
-	}
-
-	// response字段
-	if strings.HasPrefix(value, "response.") {
-		field := strings.TrimPrefix(value, "response.")
-		if val, ok := responseMap[field]; ok {
-			return val, nil
-		}
-	}
-
-	return value, nil
-}
-
 // DetectEncoding 检测响应编码
 func (v *SafeValidator) DetectEncoding(responseBody []byte, calibrationText string) (string, error) {
 	return v.encodingDetector.DetectEncoding(responseBody, calibrationText)
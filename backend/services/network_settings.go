@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"RequestProbe/backend/core/tester"
+	"RequestProbe/backend/models"
+)
+
+type requestSettingsFile struct {
+	Network *models.NetworkConfig `json:"network,omitempty"`
+}
+
+// GetNetworkSettings 获取默认网络配置
+func (s *RequestService) GetNetworkSettings(ctx context.Context) (*models.NetworkConfig, error) {
+	_ = ctx
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.loadNetworkSettings()
+}
+
+// UpdateNetworkSettings 校验并保存默认网络配置
+func (s *RequestService) UpdateNetworkSettings(ctx context.Context, network models.NetworkConfig) (*models.NetworkConfig, error) {
+	_ = ctx
+	if network.HTTPVersion == "" {
+		network.HTTPVersion = models.HTTPVersionAuto
+	}
+	if err := tester.ValidateNetworkConfig(network); err != nil {
+		return nil, err
+	}
+
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	settings, err := s.readRequestSettingsFile()
+	if err != nil {
+		return nil, err
+	}
+	settings.Network = &network
+	if err := s.writeRequestSettingsFile(settings); err != nil {
+		return nil, err
+	}
+
+	return s.loadNetworkSettings()
+}
+
+// ResetNetworkSettings 恢复默认网络配置
+func (s *RequestService) ResetNetworkSettings(ctx context.Context) (*models.NetworkConfig, error) {
+	_ = ctx
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	settings, err := s.readRequestSettingsFile()
+	if err != nil {
+		return nil, err
+	}
+	settings.Network = nil
+	if err := s.writeRequestSettingsFile(settings); err != nil {
+		return nil, err
+	}
+
+	return s.loadNetworkSettings()
+}
+
+func (s *RequestService) loadNetworkSettings() (*models.NetworkConfig, error) {
+	settings, err := s.readRequestSettingsFile()
+	if err != nil {
+		return nil, err
+	}
+	if settings.Network == nil {
+		return defaultNetworkConfig(), nil
+	}
+	network := *settings.Network
+	return &network, nil
+}
+
+func (s *RequestService) readRequestSettingsFile() (*requestSettingsFile, error) {
+	settingsPath, err := s.settingsPathFn()
+	if err != nil {
+		return nil, fmt.Errorf("获取请求设置文件失败: %w", err)
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &requestSettingsFile{}, nil
+		}
+		return nil, fmt.Errorf("读取请求设置失败: %w", err)
+	}
+
+	var settings requestSettingsFile
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("解析请求设置失败: %w", err)
+	}
+	return &settings, nil
+}
+
+func (s *RequestService) writeRequestSettingsFile(settings *requestSettingsFile) error {
+	settingsPath, err := s.settingsPathFn()
+	if err != nil {
+		return fmt.Errorf("获取请求设置文件失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0o755); err != nil {
+		return fmt.Errorf("创建设置目录失败: %w", err)
+	}
+
+	payload, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化请求设置失败: %w", err)
+	}
+
+	if err := os.WriteFile(settingsPath, payload, 0o644); err != nil {
+		return fmt.Errorf("写入请求设置失败: %w", err)
+	}
+	return nil
+}
+
+func defaultNetworkConfig() *models.NetworkConfig {
+	return &models.NetworkConfig{HTTPVersion: models.HTTPVersionAuto}
+}
+
+func defaultRequestSettingsPath() (string, error) {
+	configDir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "request_settings.json"), nil
+}
@@ -2,14 +2,21 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"RequestProbe/backend/core/manager"
 	"RequestProbe/backend/core/parser"
 	"RequestProbe/backend/core/tester"
 	"RequestProbe/backend/models"
+
+	"github.com/google/uuid"
 )
 
 // RequestService 请求服务
@@ -17,6 +24,24 @@ type RequestService struct {
 	parser            *parser.UnifiedRequestParser
 	tester            *tester.RequestTester
 	expressionManager *manager.ExpressionManager
+
+	runMu sync.Mutex
+	runs  map[string]*fieldTestRunHandle // 正在进行的字段必要性测试（键为运行ID）
+
+	settingsMu     sync.RWMutex
+	settingsPathFn func() (string, error) // 请求设置文件路径（默认网络配置等）
+
+	replayMu        sync.Mutex
+	replays         map[string]*replayHandle // 定时重放（键为运行ID，结束后保留最近的结果）
+	finishedReplays []string                 // 已结束的定时重放ID（按结束顺序，超出上限时淘汰最早的）
+	clock           clock
+}
+
+// fieldTestRunHandle 正在进行的字段必要性测试的控制句柄
+type fieldTestRunHandle struct {
+	cancel  context.CancelFunc
+	control *tester.RunControl
+	info    models.FieldTestRunInfo // 受 runMu 保护
 }
 
 // NewRequestService 创建请求服务
@@ -25,6 +50,10 @@ func NewRequestService() *RequestService {
 		parser:            parser.NewUnifiedRequestParser(),
 		tester:            tester.NewRequestTester(),
 		expressionManager: manager.NewExpressionManager(),
+		runs:              make(map[string]*fieldTestRunHandle),
+		settingsPathFn:    defaultRequestSettingsPath,
+		replays:           make(map[string]*replayHandle),
+		clock:             realClock{},
 	}
 }
 
@@ -63,31 +92,174 @@ func (s *RequestService) DetectInputType(ctx context.Context, input string) stri
 	return s.parser.DetectInputType(input)
 }
 
+// ListHarEntries 按条件列出HAR文件中的条目
+func (s *RequestService) ListHarEntries(ctx context.Context, input string, filter models.HarEntryFilter) ([]models.HarEntrySummary, error) {
+	return s.parser.ListHarEntries(input, filter)
+}
+
+// ImportHarEntry 导入HAR文件中的一个条目，返回请求与记录的响应（可作为基线参照）
+func (s *RequestService) ImportHarEntry(ctx context.Context, input string, index int) (*models.HarImport, error) {
+	imported, err := s.parser.ParseHarEntry(input, index)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.parser.ValidateRequest(imported.Request); err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+// DetectVolatileFields 解析同一接口的多次抓包，找出在抓包之间变化的字段
+func (s *RequestService) DetectVolatileFields(ctx context.Context, inputs []string) (*models.VolatileAnalysis, error) {
+	if len(inputs) < 2 {
+		return nil, fmt.Errorf("至少需要两次抓包才能对比")
+	}
+
+	captures := make([]*models.ParsedRequest, 0, len(inputs))
+	for i, input := range inputs {
+		request, err := s.ParseRequest(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("第%d个请求解析失败: %v", i+1, err)
+		}
+		captures = append(captures, request)
+	}
+	return tester.AnalyzeVolatileFields(captures), nil
+}
+
 // GeneratePythonCode 生成Python代码
 func (s *RequestService) GeneratePythonCode(ctx context.Context, request *models.ParsedRequest) string {
 	return s.parser.GeneratePythonCode(request)
 }
 
-// TestSingleRequest 测试单个请求
+// TestSingleRequest 测试单个请求（超时取自验证配置，不影响正在进行的字段测试）
 func (s *RequestService) TestSingleRequest(ctx context.Context, request *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
-	// 设置超时
-	if config.Timeout > 0 {
-		s.tester.SetTimeout(config.Timeout)
+	return s.tester.TestRequest(ctx, request, config)
+}
+
+// TestFieldNecessity 测试字段必要性
+//
+// 每次调用都是独立的运行，可与其他运行并发执行，并可通过运行ID取消、暂停或恢复。
+// transcriptCallback 在每个测试请求完成后收到一条运行记录（可为 nil）。
+func (s *RequestService) TestFieldNecessity(ctx context.Context, request *models.ParsedRequest, config *models.ValidationConfig, progressCallback func(*models.TestProgress), transcriptCallback func(*models.TranscriptEntry)) (*models.BatchTestResult, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	runID := uuid.NewString()
+	handle := &fieldTestRunHandle{
+		cancel:  cancel,
+		control: tester.NewRunControl(),
+		info: models.FieldTestRunInfo{
+			RunID:     runID,
+			Method:    request.Method,
+			URL:       request.URL,
+			StartedAt: time.Now(),
+		},
 	}
 
-	return s.tester.TestRequest(request, config)
+	s.runMu.Lock()
+	s.runs[runID] = handle
+	s.runMu.Unlock()
+
+	defer func() {
+		s.runMu.Lock()
+		delete(s.runs, runID)
+		s.runMu.Unlock()
+	}()
+
+	// 记录最近的进度，供运行列表展示
+	progress := func(p *models.TestProgress) {
+		s.runMu.Lock()
+		handle.info.Progress = p.Progress
+		handle.info.Message = p.Message
+		s.runMu.Unlock()
+
+		if progressCallback != nil {
+			progressCallback(p)
+		}
+	}
+
+	return s.tester.BatchTestFieldNecessity(runCtx, request, config, tester.RunOptions{
+		RunID:      runID,
+		Control:    handle.control,
+		Progress:   progress,
+		Transcript: transcriptCallback,
+	})
 }
 
-// TestFieldNecessity 测试字段必要性
-func (s *RequestService) TestFieldNecessity(ctx context.Context, request *models.ParsedRequest, config *models.ValidationConfig, progressCallback func(*models.TestProgress)) (*models.BatchTestResult, error) {
-	// 设置超时
-	if config.Timeout > 0 {
-		s.tester.SetTimeout(config.Timeout)
-	} else {
-		s.tester.SetTimeout(30 * time.Second) // 默认30秒超时
+// ListFieldNecessityRuns 列出正在进行的字段必要性测试（按开始时间排序）
+func (s *RequestService) ListFieldNecessityRuns(ctx context.Context) []models.FieldTestRunInfo {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	runs := make([]models.FieldTestRunInfo, 0, len(s.runs))
+	for _, handle := range s.runs {
+		info := handle.info
+		info.Paused = handle.control.Paused()
+		runs = append(runs, info)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].StartedAt.Before(runs[j].StartedAt)
+	})
+	return runs
+}
+
+// CancelFieldNecessity 取消字段必要性测试（runID 为空时取消全部），测试返回已完成部分的结果
+func (s *RequestService) CancelFieldNecessity(ctx context.Context, runID string) error {
+	handles, err := s.lookupRuns(runID)
+	if err != nil {
+		return err
+	}
+	for _, handle := range handles {
+		handle.cancel()
+	}
+	return nil
+}
+
+// PauseFieldNecessity 暂停字段必要性测试（runID 为空时暂停全部，当前请求完成后生效）
+func (s *RequestService) PauseFieldNecessity(ctx context.Context, runID string) error {
+	handles, err := s.lookupRuns(runID)
+	if err != nil {
+		return err
 	}
+	for _, handle := range handles {
+		handle.control.Pause()
+	}
+	return nil
+}
 
-	return s.tester.BatchTestFieldNecessity(request, config, progressCallback)
+// ResumeFieldNecessity 恢复已暂停的字段必要性测试（runID 为空时恢复全部）
+func (s *RequestService) ResumeFieldNecessity(ctx context.Context, runID string) error {
+	handles, err := s.lookupRuns(runID)
+	if err != nil {
+		return err
+	}
+	for _, handle := range handles {
+		handle.control.Resume()
+	}
+	return nil
+}
+
+// lookupRuns 查找运行句柄，runID 为空时返回全部运行
+func (s *RequestService) lookupRuns(runID string) ([]*fieldTestRunHandle, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+
+	if runID == "" {
+		if len(s.runs) == 0 {
+			return nil, errors.New("当前没有正在进行的字段必要性测试")
+		}
+		handles := make([]*fieldTestRunHandle, 0, len(s.runs))
+		for _, handle := range s.runs {
+			handles = append(handles, handle)
+		}
+		return handles, nil
+	}
+
+	handle, exists := s.runs[runID]
+	if !exists {
+		return nil, fmt.Errorf("未找到正在进行的字段必要性测试: %s", runID)
+	}
+	return []*fieldTestRunHandle{handle}, nil
 }
 
 // ValidateExpression 验证表达式
@@ -125,6 +297,19 @@ func (s *RequestService) DeleteExpressionTemplate(ctx context.Context, id string
 	return s.expressionManager.DeleteTemplate(id)
 }
 
+// ExportTranscript 以 JSON Lines 格式导出运行记录
+func (s *RequestService) ExportTranscript(ctx context.Context, entries []models.TranscriptEntry, filePath string) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("创建运行记录文件失败: %v", err)
+	}
+	if err := tester.WriteTranscript(file, entries); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
 // ExportExpressionTemplates 导出表达式模板
 func (s *RequestService) ExportExpressionTemplates(ctx context.Context, filePath string) error {
 	return s.expressionManager.ExportTemplates(filePath)
@@ -136,11 +321,34 @@ func (s *RequestService) ImportExpressionTemplates(ctx context.Context, filePath
 }
 
 // GetDefaultValidationConfig 获取默认验证配置
+//
+// 网络配置使用已保存的默认值，读取失败时使用内置默认值。
 func (s *RequestService) GetDefaultValidationConfig(ctx context.Context) *models.ValidationConfig {
+	network, err := s.GetNetworkSettings(ctx)
+	if err != nil {
+		network = defaultNetworkConfig()
+	}
+
 	return &models.ValidationConfig{
 		Expression: "", // 不再使用表达式
 		Timeout:    30 * time.Second,
-		MaxRetries: 3, // 默认重试3次
+		MaxRetries: 3, // 默认重试3次（未启用重试策略时使用）
+
+		// 重试策略
+		RetryPolicy: models.RetryPolicy{
+			Enabled:           true,
+			MaxRetries:        3,                         // 默认重试3次
+			RetryStatuses:     []int{429, 502, 503, 504}, // 限流与网关错误
+			RespectRetryAfter: true,
+			BaseDelay:         200 * time.Millisecond,
+			MaxDelay:          10 * time.Second,
+			Jitter:            0.2,
+			CircuitBreaker: models.CircuitBreakerConfig{
+				Enabled:          true,
+				FailureThreshold: 5,
+				Cooldown:         30 * time.Second,
+			},
+		},
 
 		FollowRedirect: true,
 		UserAgent:      "RequestProbe/1.0",
@@ -159,6 +367,39 @@ func (s *RequestService) GetDefaultValidationConfig(ctx context.Context) *models
 		},
 		UseCustomExpr: false, // 默认不使用自定义表达式
 
+		// 请求体字段测试
+		BodyFieldTest: models.BodyFieldTestConfig{
+			Enabled:   true,  // 默认测试JSON/表单请求体的顶层字段
+			Recursive: false, // 默认不递归嵌套对象
+		},
+
+		// 基线模式
+		Baseline: models.BaselineConfig{
+			Enabled:   false, // 默认关闭
+			Threshold: 0.9,   // 默认相似度阈值
+		},
+
+		// 字段最小化策略
+		Minimization: models.MinimizationConfig{
+			Strategy: models.StrategyCumulative, // 默认逐个累积移除
+			Verify:   false,                     // 默认不复测最小集合
+		},
+
+		// 多次试验
+		Trials: models.TrialConfig{
+			Count:     1,                     // 默认每个测试请求只发送一次
+			Voting:    models.VotingMajority, // 默认多数投票
+			Preflight: 0,                     // 默认不预检
+		},
+
+		// 取值探测
+		ValueProbe: models.ValueProbeConfig{
+			Enabled: false, // 默认不探测（每个必需字段额外发送3~4个请求）
+		},
+
+		// 网络配置
+		Network: *network,
+
 		// 编码配置
 		EncodingConfig: models.EncodingConfig{
 			Enabled:            false,                                      // 默认关闭编码检测
@@ -172,33 +413,9 @@ func (s *RequestService) GetDefaultValidationConfig(ctx context.Context) *models
 	}
 }
 
-// TestRequestWithRetry 带重试的请求测试
+// TestRequestWithRetry 带重试的请求测试（按验证配置的重试策略）
 func (s *RequestService) TestRequestWithRetry(ctx context.Context, request *models.ParsedRequest, config *models.ValidationConfig) (*models.ResponseData, error) {
-	maxRetries := config.MaxRetries
-	if maxRetries <= 0 {
-		maxRetries = 1
-	}
-
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		response, err := s.TestSingleRequest(ctx, request, config)
-		if err == nil {
-			return response, nil
-		}
-		lastErr = err
-
-		// 如果不是最后一次重试，等待一段时间
-		if i < maxRetries-1 {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(time.Second * time.Duration(i+1)):
-				// 指数退避
-			}
-		}
-	}
-
-	return nil, fmt.Errorf("重试 %d 次后仍然失败: %v", maxRetries, lastErr)
+	return s.tester.TestRequestWithRetry(ctx, request, config)
 }
 
 // GetRequestSummary 获取请求摘要信息
@@ -239,12 +456,15 @@ func (s *RequestService) GetTestStatistics(ctx context.Context, result *models.B
 		"originalPassed": result.OriginalPassed,
 	}
 
-	// 计算必需字段统计
+	// 计算必需字段统计（多次试验结果不稳定的字段单独统计）
+	unstableFields := 0
 	requiredHeaders := 0
 	optionalHeaders := 0
 	for _, headerResult := range result.HeaderResults {
 		if headerResult.IsRequired {
 			requiredHeaders++
+		} else if headerResult.Unstable {
+			unstableFields++
 		} else {
 			optionalHeaders++
 		}
@@ -255,19 +475,87 @@ func (s *RequestService) GetTestStatistics(ctx context.Context, result *models.B
 	for _, cookieResult := range result.CookieResults {
 		if cookieResult.IsRequired {
 			requiredCookies++
+		} else if cookieResult.Unstable {
+			unstableFields++
 		} else {
 			optionalCookies++
 		}
 	}
 
+	requiredQueryParams := 0
+	optionalQueryParams := 0
+	for _, queryResult := range result.QueryResults {
+		if queryResult.IsRequired {
+			requiredQueryParams++
+		} else if queryResult.Unstable {
+			unstableFields++
+		} else {
+			optionalQueryParams++
+		}
+	}
+
 	stats["requiredHeaders"] = requiredHeaders
 	stats["optionalHeaders"] = optionalHeaders
 	stats["requiredCookies"] = requiredCookies
 	stats["optionalCookies"] = optionalCookies
+	requiredBodyFields := 0
+	optionalBodyFields := 0
+	for _, bodyResult := range result.BodyResults {
+		if bodyResult.IsRequired {
+			requiredBodyFields++
+		} else if bodyResult.Unstable {
+			unstableFields++
+		} else {
+			optionalBodyFields++
+		}
+	}
+	keptBodyFields := len(result.BodyResults) - optionalBodyFields
+
+	stats["requiredQueryParams"] = requiredQueryParams
+	stats["optionalQueryParams"] = optionalQueryParams
+	stats["requiredBodyFields"] = requiredBodyFields
+	stats["optionalBodyFields"] = optionalBodyFields
+	stats["unstableFields"] = unstableFields
+
+	// 取值敏感度统计：需要每次刷新的字段在生成爬虫时不能写死
+	sensitivityCounts := map[string]int{
+		models.SensitivityPresenceOnly:  0,
+		models.SensitivityFormatChecked: 0,
+		models.SensitivityValueVerified: 0,
+		models.SensitivityUnknown:       0,
+	}
+	mustRefreshFields := []string{}
+	if result.CumulativeResults != nil {
+		groups := []struct {
+			fieldType string
+			results   map[string]*models.FieldTestResult
+		}{
+			{"header", result.CumulativeResults.Headers},
+			{"cookie", result.CumulativeResults.Cookies},
+			{"query", result.CumulativeResults.QueryResults},
+			{"body", result.CumulativeResults.BodyResults},
+		}
+		for _, group := range groups {
+			for name, fieldResult := range group.results {
+				if fieldResult.Sensitivity == nil {
+					continue
+				}
+				sensitivityCounts[fieldResult.Sensitivity.Class]++
+				if fieldResult.Sensitivity.MustRefresh {
+					mustRefreshFields = append(mustRefreshFields, group.fieldType+":"+name)
+				}
+			}
+		}
+	}
+	sort.Strings(mustRefreshFields)
+	stats["valueSensitivity"] = sensitivityCounts
+	stats["mustRefreshFields"] = mustRefreshFields
 
-	// 计算简化率
-	originalFieldCount := len(result.OriginalRequest.Headers) + len(result.OriginalRequest.Cookies)
-	simplifiedFieldCount := len(result.SimplifiedRequest.Headers) + len(result.SimplifiedRequest.Cookies)
+	// 计算简化率（查询参数都按URL统计；请求体字段按测试结果统计，未测试的字段不计入）
+	originalFieldCount := len(result.OriginalRequest.Headers) + len(result.OriginalRequest.Cookies) +
+		urlQueryParamCount(result.OriginalRequest.URL) + len(result.BodyResults)
+	simplifiedFieldCount := len(result.SimplifiedRequest.Headers) + len(result.SimplifiedRequest.Cookies) +
+		urlQueryParamCount(result.SimplifiedRequest.URL) + keptBodyFields
 
 	if originalFieldCount > 0 {
 		simplificationRate := float64(originalFieldCount-simplifiedFieldCount) / float64(originalFieldCount) * 100
@@ -279,6 +567,15 @@ func (s *RequestService) GetTestStatistics(ctx context.Context, result *models.B
 	return stats
 }
 
+// urlQueryParamCount 统计URL中不同名称的查询参数个数
+func urlQueryParamCount(rawURL string) int {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return 0
+	}
+	return len(parsedURL.Query())
+}
+
 // GetSupportedEncodings 获取支持的编码列表
 func (s *RequestService) GetSupportedEncodings(ctx context.Context) []string {
 	return s.tester.Validator.GetSupportedEncodings()
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"RequestProbe/backend/models"
+
+	"github.com/google/uuid"
+)
+
+// clock 时间来源（测试中替换为假时钟）
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock 系统时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// replayHandle 定时重放的控制句柄
+type replayHandle struct {
+	cancel context.CancelFunc
+	result models.ReplayResult // 受 replayMu 保护
+}
+
+// 定时重放的默认参数
+const (
+	defaultReplayInitialDelay = 30 * time.Second
+	defaultReplayMaxDuration  = 24 * time.Hour
+	maxFinishedReplays        = 20 // 保留结果的已结束重放数量
+)
+
+// normalizeReplayConfig 补全退避参数的默认值并校验
+func normalizeReplayConfig(config models.ReplayConfig) (models.ReplayConfig, error) {
+	if config.InitialDelay < 0 || config.MaxDelay < 0 || config.MaxDuration < 0 || config.MaxAttempts < 0 {
+		return config, errors.New("重放配置错误：等待时间和上限不能为负数")
+	}
+	if config.InitialDelay == 0 {
+		config.InitialDelay = defaultReplayInitialDelay
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = 1
+	}
+	if config.MaxDuration == 0 && config.MaxAttempts == 0 {
+		// 不允许无限重放
+		config.MaxDuration = defaultReplayMaxDuration
+	}
+	return config, nil
+}
+
+// nextReplayDelay 按退避倍数计算下一次等待时间
+func nextReplayDelay(delay time.Duration, config models.ReplayConfig) time.Duration {
+	next := time.Duration(float64(delay) * config.Multiplier)
+	if config.MaxDelay > 0 && next > config.MaxDelay {
+		next = config.MaxDelay
+	}
+	return next
+}
+
+// StartReplay 在后台按退避序列重复发送请求，测量凭证（Cookie、签名等）的有效期
+//
+// 首次请求立即发送，之后按 InitialDelay、InitialDelay*Multiplier… 等待；
+// 第一次未通过验证时结束并给出失效窗口。请求失败（网络错误等）只记录，不视为失效。
+// 返回运行ID，结果通过进度回调与 GetReplayResult 获取。
+func (s *RequestService) StartReplay(ctx context.Context, request *models.ParsedRequest, config *models.ValidationConfig, replay models.ReplayConfig, progressCallback func(*models.ReplayProgress)) (string, error) {
+	replay, err := normalizeReplayConfig(replay)
+	if err != nil {
+		return "", err
+	}
+	if request == nil || config == nil {
+		return "", errors.New("重放配置错误：缺少请求或验证配置")
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	runID := uuid.NewString()
+	handle := &replayHandle{
+		cancel: cancel,
+		result: models.ReplayResult{
+			RunID:     runID,
+			Status:    models.ReplayStatusRunning,
+			StartedAt: s.clock.Now(),
+			Attempts:  []models.ReplayAttempt{},
+		},
+	}
+
+	s.replayMu.Lock()
+	s.replays[runID] = handle
+	s.replayMu.Unlock()
+
+	go func() {
+		defer cancel()
+		s.runReplay(runCtx, handle, request, config, replay, progressCallback)
+	}()
+	return runID, nil
+}
+
+// runReplay 执行重放循环
+func (s *RequestService) runReplay(ctx context.Context, handle *replayHandle, request *models.ParsedRequest, config *models.ValidationConfig, replay models.ReplayConfig, progressCallback func(*models.ReplayProgress)) {
+	s.replayMu.Lock()
+	runID, startedAt := handle.result.RunID, handle.result.StartedAt
+	s.replayMu.Unlock()
+
+	report := func(status string, attempt *models.ReplayAttempt, nextIn time.Duration, message string) {
+		finished := status != models.ReplayStatusRunning
+		s.replayMu.Lock()
+		handle.result.Status = status
+		if attempt != nil {
+			handle.result.Attempts = append(handle.result.Attempts, *attempt)
+			if attempt.Error == "" && attempt.Passed {
+				handle.result.LastPassedAfter = attempt.Elapsed
+			} else if attempt.Error == "" && handle.result.FirstFailedAfter == 0 {
+				handle.result.FirstFailedAfter = attempt.Elapsed
+			}
+		}
+		if finished && status == models.ReplayStatusFailed {
+			handle.result.Error = message
+		}
+		if finished {
+			s.retireReplayLocked(runID)
+		}
+		s.replayMu.Unlock()
+
+		if progressCallback != nil {
+			progressCallback(&models.ReplayProgress{
+				RunID:    runID,
+				Status:   status,
+				Attempt:  attempt,
+				NextIn:   nextIn,
+				Message:  message,
+				Finished: finished,
+			})
+		}
+	}
+
+	delay := replay.InitialDelay
+	for number := 1; ; number++ {
+		now := s.clock.Now()
+		attempt := &models.ReplayAttempt{Attempt: number, At: now, Elapsed: now.Sub(startedAt)}
+
+		response, err := s.tester.TestRequestWithRetry(ctx, request, config)
+		if ctx.Err() != nil {
+			report(models.ReplayStatusCanceled, nil, 0, "重放已取消")
+			return
+		}
+		if err != nil {
+			attempt.Error = err.Error()
+		} else {
+			attempt.StatusCode = response.StatusCode
+			verdict, err := s.tester.ValidateResponseWithConfig(response, config)
+			if err != nil {
+				report(models.ReplayStatusFailed, attempt, 0, fmt.Sprintf("验证配置错误: %v", err))
+				return
+			}
+			attempt.Passed = verdict.Passed
+		}
+
+		switch {
+		case attempt.Error == "" && !attempt.Passed && number == 1:
+			report(models.ReplayStatusFailed, attempt, 0, "首次请求未通过验证，无法测量有效期")
+			return
+		case attempt.Error == "" && !attempt.Passed:
+			report(models.ReplayStatusExpired, attempt, 0, fmt.Sprintf("第%d次重放未通过：凭证在 %s 之后失效", number, s.replayWindow(handle)))
+			return
+		case replay.MaxAttempts > 0 && number >= replay.MaxAttempts,
+			replay.MaxDuration > 0 && attempt.Elapsed+delay > replay.MaxDuration:
+			report(models.ReplayStatusValid, attempt, 0, fmt.Sprintf("已到达重放上限，%s 后仍然有效", attempt.Elapsed))
+			return
+		}
+
+		message := fmt.Sprintf("第%d次重放通过，%s 后再次发送", number, delay)
+		if attempt.Error != "" {
+			message = fmt.Sprintf("第%d次重放请求失败（不计入判定），%s 后再次发送", number, delay)
+		}
+		report(models.ReplayStatusRunning, attempt, delay, message)
+
+		select {
+		case <-s.clock.After(delay):
+		case <-ctx.Done():
+			report(models.ReplayStatusCanceled, nil, 0, "重放已取消")
+			return
+		}
+		delay = nextReplayDelay(delay, replay)
+	}
+}
+
+// retireReplayLocked 记录已结束的重放，只保留最近 maxFinishedReplays 个的结果（调用方持有 replayMu）
+func (s *RequestService) retireReplayLocked(runID string) {
+	s.finishedReplays = append(s.finishedReplays, runID)
+	for len(s.finishedReplays) > maxFinishedReplays {
+		delete(s.replays, s.finishedReplays[0])
+		s.finishedReplays = s.finishedReplays[1:]
+	}
+}
+
+// replayWindow 描述失效窗口
+func (s *RequestService) replayWindow(handle *replayHandle) string {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+	return fmt.Sprintf("%s ~ %s", handle.result.LastPassedAfter, handle.result.FirstFailedAfter)
+}
+
+// GetReplayResult 获取定时重放的当前结果（运行结束后仍可获取，最多保留最近 maxFinishedReplays 个）
+func (s *RequestService) GetReplayResult(ctx context.Context, runID string) (*models.ReplayResult, error) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	handle, exists := s.replays[runID]
+	if !exists {
+		return nil, fmt.Errorf("未找到定时重放: %s", runID)
+	}
+	result := handle.result
+	result.Attempts = append([]models.ReplayAttempt(nil), handle.result.Attempts...)
+	return &result, nil
+}
+
+// ListReplays 列出进行中与最近结束的定时重放（按开始时间排序）
+func (s *RequestService) ListReplays(ctx context.Context) []models.ReplayResult {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	results := make([]models.ReplayResult, 0, len(s.replays))
+	for _, handle := range s.replays {
+		result := handle.result
+		result.Attempts = append([]models.ReplayAttempt(nil), handle.result.Attempts...)
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartedAt.Before(results[j].StartedAt)
+	})
+	return results
+}
+
+// CancelReplay 取消定时重放
+func (s *RequestService) CancelReplay(ctx context.Context, runID string) error {
+	s.replayMu.Lock()
+	handle, exists := s.replays[runID]
+	s.replayMu.Unlock()
+
+	if !exists {
+		return fmt.Errorf("未找到定时重放: %s", runID)
+	}
+	handle.cancel()
+	return nil
+}
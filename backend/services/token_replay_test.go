@@ -0,0 +1,187 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"RequestProbe/backend/models"
+)
+
+// fakeClock 假时钟：After 立即推进时间（blocking 时永不触发）
+type fakeClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	blocking bool
+	waits    []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	if !c.blocking {
+		c.now = c.now.Add(d)
+		ch <- c.now
+	}
+	return ch
+}
+
+func waitReplay(t *testing.T, progress <-chan *models.ReplayProgress) *models.ReplayProgress {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case p := <-progress:
+			if p.Finished {
+				return p
+			}
+		case <-timeout:
+			t.Fatalf("replay did not finish")
+		}
+	}
+}
+
+func TestReplayFindsExpiryWindow(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	// 凭证在 5 分钟后失效
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if clock.Now().Sub(start) > 5*time.Minute {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	svc := NewRequestService()
+	svc.clock = clock
+	config := svc.GetDefaultValidationConfig(context.Background())
+	config.TextMatching = models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"}
+	request := &models.ParsedRequest{Method: "GET", URL: server.URL + "/"}
+
+	progress := make(chan *models.ReplayProgress, 32)
+	runID, err := svc.StartReplay(context.Background(), request, config, models.ReplayConfig{
+		InitialDelay: time.Minute,
+		Multiplier:   2,
+		MaxDelay:     4 * time.Minute,
+		MaxDuration:  time.Hour,
+	}, func(p *models.ReplayProgress) { progress <- p })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	final := waitReplay(t, progress)
+	if final.Status != models.ReplayStatusExpired {
+		t.Fatalf("expected expired, got %s (%s)", final.Status, final.Message)
+	}
+
+	result, err := svc.GetReplayResult(context.Background(), runID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 0, 1m, 3m 通过；7m 失效
+	if result.LastPassedAfter != 3*time.Minute || result.FirstFailedAfter != 7*time.Minute {
+		t.Fatalf("unexpected window (%s, %s]", result.LastPassedAfter, result.FirstFailedAfter)
+	}
+	if len(result.Attempts) != 4 || result.Attempts[3].StatusCode != http.StatusUnauthorized {
+		t.Fatalf("unexpected attempts: %#v", result.Attempts)
+	}
+}
+
+func TestReplayStopsAtLimitsAndCancels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	svc := NewRequestService()
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	svc.clock = clock
+	config := svc.GetDefaultValidationConfig(context.Background())
+	config.TextMatching = models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"}
+	request := &models.ParsedRequest{Method: "GET", URL: server.URL + "/"}
+
+	progress := make(chan *models.ReplayProgress, 32)
+	callback := func(p *models.ReplayProgress) { progress <- p }
+	if _, err := svc.StartReplay(context.Background(), request, config, models.ReplayConfig{
+		InitialDelay: time.Minute, Multiplier: 10, MaxDelay: 5 * time.Minute, MaxAttempts: 4,
+	}, callback); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if final := waitReplay(t, progress); final.Status != models.ReplayStatusValid {
+		t.Fatalf("expected valid, got %s", final.Status)
+	}
+	clock.mu.Lock()
+	waits := append([]time.Duration(nil), clock.waits...)
+	clock.mu.Unlock()
+	if len(waits) != 3 || waits[0] != time.Minute || waits[1] != 5*time.Minute || waits[2] != 5*time.Minute {
+		t.Fatalf("unexpected backoff: %v", waits)
+	}
+
+	clock.mu.Lock()
+	clock.blocking = true
+	clock.mu.Unlock()
+	runID, err := svc.StartReplay(context.Background(), request, config, models.ReplayConfig{InitialDelay: time.Minute}, callback)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.CancelReplay(context.Background(), "missing"); err == nil {
+		t.Fatalf("expected error for unknown replay")
+	}
+	if err := svc.CancelReplay(context.Background(), runID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if final := waitReplay(t, progress); final.Status != models.ReplayStatusCanceled || final.RunID != runID {
+		t.Fatalf("expected canceled run %s, got %s %s", runID, final.Status, final.RunID)
+	}
+	if replays := svc.ListReplays(context.Background()); len(replays) != 2 {
+		t.Fatalf("expected two replays, got %d", len(replays))
+	}
+}
+
+func TestReplayKeepsOnlyRecentFinishedResults(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	svc := NewRequestService()
+	svc.clock = &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	config := svc.GetDefaultValidationConfig(context.Background())
+	config.TextMatching = models.TextMatchingConfig{Enabled: true, Texts: []string{"ok"}, MatchMode: "all"}
+	request := &models.ParsedRequest{Method: "GET", URL: server.URL + "/"}
+
+	progress := make(chan *models.ReplayProgress, 32)
+	var runIDs []string
+	for i := 0; i < maxFinishedReplays+2; i++ {
+		runID, err := svc.StartReplay(context.Background(), request, config, models.ReplayConfig{MaxAttempts: 1}, func(p *models.ReplayProgress) { progress <- p })
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		waitReplay(t, progress)
+		runIDs = append(runIDs, runID)
+	}
+
+	if replays := svc.ListReplays(context.Background()); len(replays) != maxFinishedReplays {
+		t.Fatalf("expected %d retained replays, got %d", maxFinishedReplays, len(replays))
+	}
+	if _, err := svc.GetReplayResult(context.Background(), runIDs[0]); err == nil {
+		t.Fatalf("expected oldest replay to be evicted")
+	}
+	if _, err := svc.GetReplayResult(context.Background(), runIDs[len(runIDs)-1]); err != nil {
+		t.Fatalf("expected latest replay to be kept, got %v", err)
+	}
+}
@@ -5,7 +5,9 @@ go 1.23.0
 toolchain go1.24.4
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/google/uuid v1.6.0
+	github.com/klauspost/compress v1.17.11
 	github.com/wailsapp/wails/v2 v2.11.0
 	golang.org/x/net v0.42.0
 	golang.org/x/text v0.27.0